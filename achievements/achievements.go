@@ -0,0 +1,92 @@
+package achievements
+
+import (
+	"time"
+)
+
+/*
+Metric is what an achievement rule measures, rules (rows in the achievements table) pick a metric and a threshold,
+so new badges for existing metrics don't need code or schema changes
+*/
+type Metric string
+
+const (
+	/* at least threshold practice tests finished */
+	MetricPracticeTests Metric = "PRACTICE_TESTS"
+	/* 100% on a practice test with at least threshold questions */
+	MetricPerfectPracticeTest Metric = "PERFECT_PRACTICE_TEST"
+	/* a match game finished in under threshold seconds */
+	MetricMatchUnderSeconds Metric = "MATCH_UNDER_SECONDS"
+	/* at least threshold reviews, from term progress and FSRS reviews */
+	MetricReviews Metric = "REVIEWS"
+	/* a streak (see the streak package) at least threshold days long */
+	MetricStreakDays Metric = "STREAK_DAYS"
+)
+
+/* Event is a kind of study activity that was just recorded, it decides which rules are evaluated */
+type Event string
+
+const (
+	EventPracticeTest  Event = "PRACTICE_TEST"
+	EventMatchActivity Event = "MATCH_ACTIVITY"
+	EventTermProgress  Event = "TERM_PROGRESS"
+	EventLearnAnswer   Event = "LEARN_ANSWER"
+)
+
+/* metrics that can change when each event is recorded */
+var eventMetrics = map[Event][]Metric{
+	EventPracticeTest: {
+		MetricPracticeTests,
+		MetricPerfectPracticeTest,
+		MetricReviews,
+		MetricStreakDays,
+	},
+	EventMatchActivity: {
+		MetricMatchUnderSeconds,
+		MetricReviews,
+		MetricStreakDays,
+	},
+	/* term progress doesn't save review events, so it doesn't change study days */
+	EventTermProgress: {
+		MetricReviews,
+	},
+	/* learn answers save review events & FSRS reviews */
+	EventLearnAnswer: {
+		MetricReviews,
+		MetricStreakDays,
+	},
+}
+
+type Rule struct {
+	ID          string `db:"id"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	Metric      Metric `db:"metric"`
+	Threshold   int32  `db:"threshold"`
+}
+
+type Earned struct {
+	AchievementID string
+	EarnedAt      time.Time
+}
+
+type Options struct {
+	/* used for study days (for streaks), UTC if it's nil */
+	Location     *time.Location
+	RolloverHour int32
+}
+
+/* affected returns whether metric can change when any of events are recorded, every metric can if there are no events */
+func affected(metric Metric, events []Event) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, event := range events {
+		for _, m := range eventMetrics[event] {
+			if m == metric {
+				return true
+			}
+		}
+	}
+	return false
+}
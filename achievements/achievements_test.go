@@ -0,0 +1,35 @@
+package achievements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffected(t *testing.T) {
+	require.True(t, affected(MetricPracticeTests, []Event{EventPracticeTest}))
+	require.False(t, affected(MetricPracticeTests, []Event{EventMatchActivity, EventTermProgress}))
+	require.True(t, affected(MetricReviews, []Event{EventTermProgress}))
+	require.True(t, affected(MetricMatchUnderSeconds, []Event{EventTermProgress, EventMatchActivity}))
+
+	/* backfills check everything */
+	require.True(t, affected(MetricStreakDays, nil))
+}
+
+func TestStreakDaysEarnedAt(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	opts := Options{Location: loc, RolloverHour: 4}
+	today := time.Now().In(loc).Add(-4 * time.Hour)
+	studyDay := func(daysAgo int) time.Time {
+		d := today.AddDate(0, 0, -daysAgo)
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	studyDays := []time.Time{studyDay(3), studyDay(2), studyDay(1)}
+
+	require.Nil(t, streakDaysEarnedAt(studyDays, opts, 4))
+	earnedAt := streakDaysEarnedAt(studyDays, opts, 2)
+	require.NotNil(t, earnedAt)
+	d := studyDay(2)
+	require.True(t, time.Date(d.Year(), d.Month(), d.Day(), 4, 0, 0, 0, loc).Equal(*earnedAt))
+}
@@ -0,0 +1,201 @@
+package achievements
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"quizfreely/api/streak"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+Evaluate checks the rules that a user hasn't earned yet against their study history, and saves the ones they've earned.
+Only rules for metrics that events can change are checked, or every rule if there are no events (like for backfilling).
+*/
+func Evaluate(ctx context.Context, db *pgxpool.Pool, userID string, opts Options, events ...Event) ([]Earned, error) {
+	var rules []Rule
+	err := pgxscan.Select(
+		ctx,
+		db,
+		&rules,
+		`SELECT a.id, a.name, a.description, a.metric, a.threshold
+		FROM achievements a
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_achievements ua
+			WHERE ua.user_id = $1 AND ua.achievement_id = a.id
+		)
+		ORDER BY a.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	/* study days are only loaded once, even if there are multiple streak rules */
+	var studyDays []time.Time
+	loadedStudyDays := false
+
+	var earned []Earned
+	for _, rule := range rules {
+		if !affected(rule.Metric, events) {
+			continue
+		}
+
+		var earnedAt *time.Time
+		switch rule.Metric {
+		case MetricPracticeTests:
+			earnedAt, err = practiceTestsEarnedAt(ctx, db, userID, rule.Threshold)
+		case MetricPerfectPracticeTest:
+			earnedAt, err = perfectPracticeTestEarnedAt(ctx, db, userID, rule.Threshold)
+		case MetricMatchUnderSeconds:
+			earnedAt, err = matchUnderSecondsEarnedAt(ctx, db, userID, rule.Threshold)
+		case MetricReviews:
+			earnedAt, err = reviewsEarnedAt(ctx, db, userID, rule.Threshold)
+		case MetricStreakDays:
+			if !loadedStudyDays {
+				studyDays, err = streak.LoadStudyDays(ctx, db, userID, opts.Location, opts.RolloverHour)
+				if err != nil {
+					return earned, err
+				}
+				loadedStudyDays = true
+			}
+			earnedAt = streakDaysEarnedAt(studyDays, opts, rule.Threshold)
+		default:
+			log.Warn().Str("achievementID", rule.ID).Str("metric", string(rule.Metric)).Msg("Unknown achievement metric")
+			continue
+		}
+		if err != nil {
+			return earned, err
+		}
+		if earnedAt == nil {
+			continue
+		}
+
+		tag, err := db.Exec(
+			ctx,
+			`INSERT INTO user_achievements (user_id, achievement_id, earned_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, achievement_id) DO NOTHING`,
+			userID,
+			rule.ID,
+			*earnedAt,
+		)
+		if err != nil {
+			return earned, err
+		}
+		if tag.RowsAffected() > 0 {
+			earned = append(earned, Earned{AchievementID: rule.ID, EarnedAt: *earnedAt})
+		}
+	}
+	return earned, nil
+}
+
+/* practiceTestsEarnedAt returns when the user's threshold-th practice test was finished */
+func practiceTestsEarnedAt(ctx context.Context, db *pgxpool.Pool, userID string, threshold int32) (*time.Time, error) {
+	var earnedAt time.Time
+	err := db.QueryRow(
+		ctx,
+		`SELECT "timestamp" FROM practice_tests
+		WHERE user_id = $1
+		ORDER BY "timestamp"
+		OFFSET $2 LIMIT 1`,
+		userID,
+		max(threshold, 1)-1,
+	).Scan(&earnedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &earnedAt, nil
+}
+
+func perfectPracticeTestEarnedAt(ctx context.Context, db *pgxpool.Pool, userID string, threshold int32) (*time.Time, error) {
+	var earnedAt *time.Time
+	err := db.QueryRow(
+		ctx,
+		`SELECT min("timestamp") FROM practice_tests
+		WHERE user_id = $1
+		AND questions_total >= $2
+		AND questions_correct = questions_total`,
+		userID,
+		max(threshold, 1),
+	).Scan(&earnedAt)
+	return earnedAt, err
+}
+
+/* match games without any matched terms don't count, they'd be finished instantly */
+func matchUnderSecondsEarnedAt(ctx context.Context, db *pgxpool.Pool, userID string, threshold int32) (*time.Time, error) {
+	var earnedAt *time.Time
+	err := db.QueryRow(
+		ctx,
+		`SELECT min(m.end_timestamp) FROM match_activities m
+		WHERE m.user_id = $1
+		AND m.duration_ms < $2::bigint * 1000
+		AND EXISTS (
+			SELECT 1 FROM review_events re
+			WHERE re.match_activity_id = m.id AND re.correct
+		)`,
+		userID,
+		threshold,
+	).Scan(&earnedAt)
+	return earnedAt, err
+}
+
+/*
+reviewsEarnedAt counts term progress answers and FSRS reviews.
+Learn answers are saved as term progress and an FSRS review, so their FSRS reviews are skipped.
+Term progress only has counts (not when each answer was), so it's earned at the user's last review
+*/
+func reviewsEarnedAt(ctx context.Context, db *pgxpool.Pool, userID string, threshold int32) (*time.Time, error) {
+	var reviews int64
+	var lastReviewedAt *time.Time
+	err := db.QueryRow(
+		ctx,
+		`SELECT
+	(SELECT COALESCE(sum(term_correct_count + term_incorrect_count + def_correct_count + def_incorrect_count), 0)
+		FROM term_progress WHERE user_id = $1)
+	+ (SELECT count(*) FROM fsrs_review_logs l WHERE user_id = $1 AND rating <> 'MANUAL'
+		AND NOT EXISTS (
+			SELECT 1 FROM review_events lre
+			WHERE lre.user_id = l.user_id AND lre.term_id = l.term_id AND lre."timestamp" = l.review
+			AND lre.review_activity_type = 'LEARN'
+		)),
+	GREATEST(
+		(SELECT max(GREATEST(term_last_reviewed_at, def_last_reviewed_at)) FROM term_progress WHERE user_id = $1),
+		(SELECT max(review) FROM fsrs_review_logs WHERE user_id = $1 AND rating <> 'MANUAL')
+	)`,
+		userID,
+	).Scan(&reviews, &lastReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	if reviews < int64(threshold) {
+		return nil, nil
+	}
+	if lastReviewedAt == nil {
+		now := time.Now()
+		return &now, nil
+	}
+	return lastReviewedAt, nil
+}
+
+/* streakDaysEarnedAt returns the start of the study day a streak first reached threshold days */
+func streakDaysEarnedAt(studyDays []time.Time, opts Options, threshold int32) *time.Time {
+	today := streak.Date(time.Now().In(opts.Location).Add(-time.Duration(opts.RolloverHour) * time.Hour))
+	day, ok := streak.FirstReached(studyDays, today, int(threshold))
+	if !ok {
+		return nil
+	}
+	earnedAt := time.Date(day.Year(), day.Month(), day.Day(), int(opts.RolloverHour), 0, 0, 0, opts.Location)
+	return &earnedAt
+}
@@ -1,29 +1,52 @@
 package config
 
 type Config struct {
-	Port                     int    `toml:"port"`
-	DBURL                    string `toml:"db_url"`
-	PrettyLog                bool   `toml:"pretty_log"`
-	BasePath                 string `toml:"base_path"`
-	EnableOAuthGoogle        bool   `toml:"enable_oauth_google"`
-	OAuthGoogleClientID      string `toml:"oauth_google_client_id"`
-	OAuthGoogleClientSecret  string `toml:"oauth_google_client_secret"`
-	OAuthGoogleCallbackURL   string `toml:"oauth_google_callback_url"`
-	OAuthFinalRedirectURL    string `toml:"oauth_final_redirect_url"`
-	StorageEndpointURL       string `toml:"storage_endpoint_url"`
-	StorageRegion            string `toml:"storage_region"`
-	StorageKeyID             string `toml:"storage_key_id"`
-	StorageSecretKey         string `toml:"storage_secret_key"`
-	UsercontentBucket        string `toml:"usercontent_bucket"`
-	UsercontentBaseURL       string `toml:"usercontent_base_url"`
-	SessionCleanupCronSpec   string `toml:"session_cleanup_cron_spec"`
-	TermImageCleanupCronSpec string `toml:"term_image_cleanup_cron_spec"`
-	EnableWebImport          bool   `toml:"enable_web_import"`
-	WebImportRateLimitReq    int    `toml:"web_import_rate_limit_req"`
-	WebImportRateLimitDur    int    `toml:"web_import_rate_limit_dur"`
-	UseCrawlbase             bool   `toml:"use_crawlbase"`
-	CrawlbaseAPIKey          string `toml:"crawlbase_api_key"`
-	UseZyte                  bool   `toml:"use_zyte"`
-	ZyteAPIKey               string `toml:"zyte_api_key"`
-	TryZyteBeforeCrawlbase   bool   `toml:"try_zyte_before_crawlbase"`
+	Port                          int            `toml:"port"`
+	DBURL                         string         `toml:"db_url"`
+	PrettyLog                     bool           `toml:"pretty_log"`
+	BasePath                      string         `toml:"base_path"`
+	EnableOAuthGoogle             bool           `toml:"enable_oauth_google"`
+	OAuthGoogleClientID           string         `toml:"oauth_google_client_id"`
+	OAuthGoogleClientSecret       string         `toml:"oauth_google_client_secret"`
+	OAuthGoogleCallbackURL        string         `toml:"oauth_google_callback_url"`
+	OAuthFinalRedirectURL         string         `toml:"oauth_final_redirect_url"`
+	StorageEndpointURL            string         `toml:"storage_endpoint_url"`
+	StorageRegion                 string         `toml:"storage_region"`
+	StorageKeyID                  string         `toml:"storage_key_id"`
+	StorageSecretKey              string         `toml:"storage_secret_key"`
+	UsercontentBucket             string         `toml:"usercontent_bucket"`
+	UsercontentBaseURL            string         `toml:"usercontent_base_url"`
+	SessionCleanupCronSpec        string         `toml:"session_cleanup_cron_spec"`
+	TermImageCleanupCronSpec      string         `toml:"term_image_cleanup_cron_spec"`
+	EnableWebImport               bool           `toml:"enable_web_import"`
+	WebImportRateLimitReq         int            `toml:"web_import_rate_limit_req"`
+	WebImportRateLimitDur         int            `toml:"web_import_rate_limit_dur"`
+	UseCrawlbase                  bool           `toml:"use_crawlbase"`
+	CrawlbaseAPIKey               string         `toml:"crawlbase_api_key"`
+	UseZyte                       bool           `toml:"use_zyte"`
+	ZyteAPIKey                    string         `toml:"zyte_api_key"`
+	TryZyteBeforeCrawlbase        bool           `toml:"try_zyte_before_crawlbase"`
+	WebImportFetchers             []string       `toml:"web_import_fetchers"`
+	WebImportFetcherTimeouts      map[string]int `toml:"web_import_fetcher_timeouts"`
+	WebImportUserAgent            string         `toml:"web_import_user_agent"`
+	WebImportProxyURL             string         `toml:"web_import_proxy_url"`
+	WebImportFileDir              string         `toml:"web_import_file_dir"`
+	WebImportCacheTTL             int            `toml:"web_import_cache_ttl"`
+	WebImportJobCleanupCronSpec   string         `toml:"web_import_job_cleanup_cron_spec"`
+	PDFFontPath                   string         `toml:"pdf_font_path"`
+	FSRSOptimizerCronSpec         string         `toml:"fsrs_optimizer_cron_spec"`
+	IdempotencyKeyRetention       int            `toml:"idempotency_key_retention"`
+	IdempotencyKeyCleanupCronSpec string         `toml:"idempotency_key_cleanup_cron_spec"`
+	GeneratedPTCleanupCronSpec    string         `toml:"generated_practice_test_cleanup_cron_spec"`
+	AchievementsBackfillCronSpec  string         `toml:"achievements_backfill_cron_spec"`
+	TermInsightsRefreshCronSpec   string         `toml:"term_insights_refresh_cron_spec"`
+	ReminderCronSpec              string         `toml:"reminder_cron_spec"`
+	ReminderNotifier              string         `toml:"reminder_notifier"`
+	ReminderUnsubscribeURL        string         `toml:"reminder_unsubscribe_url"`
+	ReminderWebhookSecret         string         `toml:"reminder_webhook_secret"`
+	SMTPHost                      string         `toml:"smtp_host"`
+	SMTPPort                      int            `toml:"smtp_port"`
+	SMTPUsername                  string         `toml:"smtp_username"`
+	SMTPPassword                  string         `toml:"smtp_password"`
+	SMTPFrom                      string         `toml:"smtp_from"`
 }
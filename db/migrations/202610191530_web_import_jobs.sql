@@ -0,0 +1,34 @@
+-- migrate:up
+CREATE TYPE public.web_import_job_status AS ENUM (
+    'PENDING',
+    'RUNNING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+CREATE TABLE public.web_import_jobs (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    url text NOT NULL,
+    normalized_url text NOT NULL,
+    source text NOT NULL,
+    status public.web_import_job_status DEFAULT 'PENDING' NOT NULL,
+    fetcher text,
+    result jsonb,
+    error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.web_import_jobs TO quizfreely_api;
+
+-- only one pending/running job per url, concurrent imports of the same url share a job
+CREATE UNIQUE INDEX web_import_jobs_active_url_idx ON public.web_import_jobs (normalized_url)
+WHERE status IN ('PENDING', 'RUNNING');
+
+-- for finding cached results
+CREATE INDEX web_import_jobs_succeeded_url_idx ON public.web_import_jobs (normalized_url, finished_at DESC)
+WHERE status = 'SUCCEEDED';
+
+-- migrate:down
+DROP TABLE IF EXISTS public.web_import_jobs;
+DROP TYPE IF EXISTS public.web_import_job_status;
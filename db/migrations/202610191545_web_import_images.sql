@@ -0,0 +1,27 @@
+-- migrate:up
+-- jobs that download images have different results (usercontent image urls & keys),
+-- so they're cached & deduplicated separately
+ALTER TABLE public.web_import_jobs ADD COLUMN download_images boolean DEFAULT false NOT NULL;
+
+DROP INDEX public.web_import_jobs_active_url_idx;
+CREATE UNIQUE INDEX web_import_jobs_active_url_idx ON public.web_import_jobs (normalized_url, download_images)
+WHERE status IN ('PENDING', 'RUNNING');
+
+-- images downloaded by web imports aren't used by terms until the user creates the studyset,
+-- so the image cleanup job skips recently added images
+ALTER TABLE public.images ADD COLUMN created_at timestamp with time zone DEFAULT now() NOT NULL;
+
+-- only signed in users can download images with web imports, so jobs & images record who added them
+ALTER TABLE public.web_import_jobs ADD COLUMN user_id uuid REFERENCES auth.users(id) ON DELETE SET NULL;
+ALTER TABLE public.images ADD COLUMN user_id uuid REFERENCES auth.users(id) ON DELETE SET NULL;
+
+-- migrate:down
+ALTER TABLE public.images DROP COLUMN IF EXISTS user_id;
+ALTER TABLE public.web_import_jobs DROP COLUMN IF EXISTS user_id;
+ALTER TABLE public.images DROP COLUMN IF EXISTS created_at;
+
+DELETE FROM public.web_import_jobs WHERE download_images = true;
+DROP INDEX public.web_import_jobs_active_url_idx;
+CREATE UNIQUE INDEX web_import_jobs_active_url_idx ON public.web_import_jobs (normalized_url)
+WHERE status IN ('PENDING', 'RUNNING');
+ALTER TABLE public.web_import_jobs DROP COLUMN IF EXISTS download_images;
@@ -0,0 +1,10 @@
+-- migrate:up
+-- for dueCards, finding a user's cards that are due
+CREATE INDEX fsrs_cards_user_id_due_idx ON public.fsrs_cards (user_id, due);
+
+-- for dueCards (new cards in studysets) and loading a studyset's terms
+CREATE INDEX terms_studyset_id_sort_order_idx ON public.terms (studyset_id, sort_order);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.terms_studyset_id_sort_order_idx;
+DROP INDEX IF EXISTS public.fsrs_cards_user_id_due_idx;
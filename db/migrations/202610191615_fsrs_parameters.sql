@@ -0,0 +1,32 @@
+-- migrate:up
+CREATE TYPE public.fsrs_optimization_status AS ENUM (
+    'PENDING',
+    'RUNNING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+-- per-user fsrs weights, fitted to their review logs
+CREATE TABLE public.fsrs_parameters (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES auth.users (id) ON DELETE CASCADE,
+    -- null means the default weights are used (not optimized yet, or the defaults were better)
+    weights double precision[],
+    -- log loss of the fitted weights & default weights on held out reviews
+    log_loss double precision,
+    default_log_loss double precision,
+    review_count integer DEFAULT 0 NOT NULL,
+    status public.fsrs_optimization_status DEFAULT 'PENDING' NOT NULL,
+    error text,
+    requested_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    optimized_at timestamp with time zone
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.fsrs_parameters TO quizfreely_api;
+
+-- for loading a user's review history
+CREATE INDEX fsrs_review_logs_user_id_term_id_review_idx ON public.fsrs_review_logs (user_id, term_id, review);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.fsrs_review_logs_user_id_term_id_review_idx;
+DROP TABLE IF EXISTS public.fsrs_parameters;
+DROP TYPE IF EXISTS public.fsrs_optimization_status;
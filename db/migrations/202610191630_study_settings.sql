@@ -0,0 +1,36 @@
+-- migrate:up
+-- null settings use the defaults (set in the api)
+CREATE TABLE public.study_settings (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES auth.users (id) ON DELETE CASCADE,
+    desired_retention double precision,
+    max_new_cards_per_day integer,
+    max_reviews_per_day integer,
+    learning_steps_minutes integer[],
+    relearning_steps_minutes integer[],
+    -- hour (in the user's timezone) when a new study day starts
+    day_rollover_hour integer,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.study_settings TO quizfreely_api;
+
+-- per-studyset overrides, null settings use the user's settings
+CREATE TABLE public.studyset_study_settings (
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    studyset_id uuid NOT NULL REFERENCES public.studysets (id) ON DELETE CASCADE,
+    desired_retention double precision,
+    max_new_cards_per_day integer,
+    max_reviews_per_day integer,
+    learning_steps_minutes integer[],
+    relearning_steps_minutes integer[],
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (user_id, studyset_id)
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.studyset_study_settings TO quizfreely_api;
+
+-- for counting today's reviews for daily limits
+CREATE INDEX fsrs_review_logs_user_id_review_idx ON public.fsrs_review_logs (user_id, review);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.fsrs_review_logs_user_id_review_idx;
+DROP TABLE IF EXISTS public.studyset_study_settings;
+DROP TABLE IF EXISTS public.study_settings;
@@ -0,0 +1,20 @@
+-- migrate:up
+-- suspended cards aren't in the due queue until they're unsuspended,
+-- buried cards aren't in the due queue until buried_until
+ALTER TABLE public.fsrs_cards ADD COLUMN suspended boolean DEFAULT false NOT NULL;
+ALTER TABLE public.fsrs_cards ADD COLUMN buried_until timestamp with time zone;
+-- cards the user keeps forgetting (lapses reached their leech threshold)
+ALTER TABLE public.fsrs_cards ADD COLUMN leech boolean DEFAULT false NOT NULL;
+
+ALTER TABLE public.study_settings ADD COLUMN leech_threshold integer;
+ALTER TABLE public.studyset_study_settings ADD COLUMN leech_threshold integer;
+
+CREATE INDEX fsrs_cards_user_id_leech_idx ON public.fsrs_cards (user_id) WHERE leech;
+
+-- migrate:down
+DROP INDEX IF EXISTS public.fsrs_cards_user_id_leech_idx;
+ALTER TABLE public.studyset_study_settings DROP COLUMN IF EXISTS leech_threshold;
+ALTER TABLE public.study_settings DROP COLUMN IF EXISTS leech_threshold;
+ALTER TABLE public.fsrs_cards DROP COLUMN IF EXISTS leech;
+ALTER TABLE public.fsrs_cards DROP COLUMN IF EXISTS buried_until;
+ALTER TABLE public.fsrs_cards DROP COLUMN IF EXISTS suspended;
@@ -0,0 +1,33 @@
+-- migrate:up
+-- practice tests generated by the api, submissions with generated_practice_test_id are checked against these
+CREATE TABLE public.generated_practice_tests (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    studyset_ids uuid[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    -- each generated test can only be submitted once
+    submitted_at timestamp with time zone
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.generated_practice_tests TO quizfreely_api;
+
+-- data is the same as practice_test_questions.data, without the user's answers
+CREATE TABLE public.generated_practice_test_questions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    generated_practice_test_id uuid NOT NULL REFERENCES public.generated_practice_tests (id) ON DELETE CASCADE,
+    term_id uuid NOT NULL REFERENCES public.terms (id) ON DELETE CASCADE,
+    term_snapshot text NOT NULL,
+    def_snapshot text NOT NULL,
+    type public.question_type NOT NULL,
+    answer_with public.answer_with_enum NOT NULL,
+    "position" integer NOT NULL,
+    data jsonb NOT NULL
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.generated_practice_test_questions TO quizfreely_api;
+
+CREATE INDEX generated_practice_tests_user_id_expires_at_idx ON public.generated_practice_tests (user_id, expires_at);
+CREATE INDEX generated_practice_test_questions_test_id_idx ON public.generated_practice_test_questions (generated_practice_test_id, "position");
+
+-- migrate:down
+DROP TABLE IF EXISTS public.generated_practice_test_questions;
+DROP TABLE IF EXISTS public.generated_practice_tests;
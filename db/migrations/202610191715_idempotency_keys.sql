@@ -0,0 +1,18 @@
+-- migrate:up
+-- client-supplied keys for retried mutations (like from offline clients),
+-- result is the mutation's original result, which is returned for replays
+CREATE TABLE public.idempotency_keys (
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    key text NOT NULL,
+    mutation text NOT NULL,
+    result jsonb,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.idempotency_keys TO quizfreely_api;
+
+-- for deleting expired keys
+CREATE INDEX idempotency_keys_created_at_idx ON public.idempotency_keys (created_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS public.idempotency_keys;
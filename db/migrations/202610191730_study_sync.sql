@@ -0,0 +1,13 @@
+-- migrate:up
+-- syncStudyActivity's cursor, so offline clients can pull cards & progress changed since their last sync
+ALTER TABLE public.fsrs_cards ADD COLUMN updated_at timestamp with time zone DEFAULT now() NOT NULL;
+ALTER TABLE public.term_progress ADD COLUMN updated_at timestamp with time zone DEFAULT now() NOT NULL;
+
+CREATE INDEX fsrs_cards_user_id_updated_at_idx ON public.fsrs_cards (user_id, updated_at);
+CREATE INDEX term_progress_user_id_updated_at_idx ON public.term_progress (user_id, updated_at);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.term_progress_user_id_updated_at_idx;
+DROP INDEX IF EXISTS public.fsrs_cards_user_id_updated_at_idx;
+ALTER TABLE public.term_progress DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE public.fsrs_cards DROP COLUMN IF EXISTS updated_at;
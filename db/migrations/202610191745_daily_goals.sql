@@ -0,0 +1,15 @@
+-- migrate:up
+CREATE TYPE public.daily_goal_type AS ENUM ('REVIEWS', 'MINUTES');
+
+-- null uses the default daily goal (set in the api)
+ALTER TABLE public.study_settings ADD COLUMN daily_goal_type public.daily_goal_type;
+ALTER TABLE public.study_settings ADD COLUMN daily_goal integer;
+
+-- for finding which days users studied, for streaks
+CREATE INDEX review_events_user_id_timestamp_idx ON public.review_events (user_id, "timestamp");
+
+-- migrate:down
+DROP INDEX IF EXISTS public.review_events_user_id_timestamp_idx;
+ALTER TABLE public.study_settings DROP COLUMN IF EXISTS daily_goal;
+ALTER TABLE public.study_settings DROP COLUMN IF EXISTS daily_goal_type;
+DROP TYPE IF EXISTS public.daily_goal_type;
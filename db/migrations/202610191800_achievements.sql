@@ -0,0 +1,31 @@
+-- migrate:up
+-- achievement rules, new badges only need new rows
+-- (metric is one of the metrics evaluated by the api's achievements package)
+CREATE TABLE public.achievements (
+    id text PRIMARY KEY,
+    name text NOT NULL,
+    description text NOT NULL,
+    metric text NOT NULL,
+    threshold integer NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+GRANT SELECT ON public.achievements TO quizfreely_api;
+
+CREATE TABLE public.user_achievements (
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    achievement_id text NOT NULL REFERENCES public.achievements (id) ON DELETE CASCADE,
+    earned_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (user_id, achievement_id)
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.user_achievements TO quizfreely_api;
+
+INSERT INTO public.achievements (id, name, description, metric, threshold) VALUES
+    ('first_practice_test', 'First Test', 'Finish your first practice test', 'PRACTICE_TESTS', 1),
+    ('perfect_practice_test', 'Perfect Score', 'Get 100% on a practice test with 20 or more questions', 'PERFECT_PRACTICE_TEST', 20),
+    ('fast_match', 'Quick Match', 'Finish a match game in under 30 seconds', 'MATCH_UNDER_SECONDS', 30),
+    ('reviews_1000', '1000 Reviews', 'Review terms 1000 times', 'REVIEWS', 1000),
+    ('streak_30', '30 Day Streak', 'Study 30 days in a row', 'STREAK_DAYS', 30);
+
+-- migrate:down
+DROP TABLE IF EXISTS public.user_achievements;
+DROP TABLE IF EXISTS public.achievements;
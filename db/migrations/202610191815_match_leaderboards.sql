@@ -0,0 +1,13 @@
+-- migrate:up
+-- users who opt out aren't shown on leaderboards (like match leaderboards)
+ALTER TABLE auth.users ADD COLUMN hide_from_leaderboards boolean DEFAULT false NOT NULL;
+
+-- for finding a studyset's match games, for leaderboards
+CREATE INDEX match_activity_studysets_studyset_id_idx ON public.match_activity_studysets (studyset_id);
+-- for counting each match game's matched terms and incorrect pairs
+CREATE INDEX review_events_match_activity_id_idx ON public.review_events (match_activity_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.review_events_match_activity_id_idx;
+DROP INDEX IF EXISTS public.match_activity_studysets_studyset_id_idx;
+ALTER TABLE auth.users DROP COLUMN IF EXISTS hide_from_leaderboards;
@@ -0,0 +1,63 @@
+-- migrate:up
+-- per-term answer stats across all users, for studyset authors (refreshed by the api's term insights cron job)
+-- common wrong answers are the ones the most users gave, so one user repeating an answer doesn't decide it
+CREATE MATERIALIZED VIEW public.term_insights AS
+WITH term_stats AS (
+    SELECT term_id,
+        count(*) AS attempts,
+        count(*) FILTER (WHERE NOT correct) AS incorrect,
+        count(DISTINCT user_id) AS users
+    FROM public.review_events
+    GROUP BY term_id
+),
+wrong_terms AS (
+    SELECT DISTINCT ON (term_id) term_id, answered_term_id, count(DISTINCT user_id) AS users
+    FROM public.review_events
+    WHERE NOT correct AND answered_term_id IS NOT NULL AND answered_term_id <> term_id
+    GROUP BY term_id, answered_term_id
+    ORDER BY term_id, count(DISTINCT user_id) DESC, count(*) DESC, answered_term_id
+),
+wrong_strings AS (
+    SELECT DISTINCT ON (term_id) term_id, lower(btrim(answered_string)) AS answered_string, count(DISTINCT user_id) AS users
+    FROM public.review_events
+    WHERE NOT correct AND btrim(answered_string) <> ''
+    GROUP BY term_id, lower(btrim(answered_string))
+    ORDER BY term_id, count(DISTINCT user_id) DESC, count(*) DESC, lower(btrim(answered_string))
+)
+SELECT ts.term_id,
+    ts.attempts,
+    ts.incorrect,
+    ts.users,
+    wt.answered_term_id AS common_wrong_term_id,
+    wt.users AS common_wrong_term_users,
+    ws.answered_string AS common_wrong_string,
+    ws.users AS common_wrong_string_users,
+    now() AS refreshed_at
+FROM term_stats ts
+LEFT JOIN wrong_terms wt ON wt.term_id = ts.term_id
+LEFT JOIN wrong_strings ws ON ws.term_id = ts.term_id;
+
+-- needed to refresh concurrently
+CREATE UNIQUE INDEX term_insights_term_id_idx ON public.term_insights (term_id);
+GRANT SELECT ON public.term_insights TO quizfreely_api;
+
+-- only a materialized view's owner can refresh it, so the api refreshes it with this
+CREATE FUNCTION public.refresh_term_insights() RETURNS void
+    LANGUAGE plpgsql SECURITY DEFINER
+    SET search_path = public
+    AS $$
+BEGIN
+    -- views that were never populated (like from schema.sql) can't be refreshed concurrently
+    IF (SELECT relispopulated FROM pg_class WHERE oid = 'public.term_insights'::regclass) THEN
+        REFRESH MATERIALIZED VIEW CONCURRENTLY public.term_insights;
+    ELSE
+        REFRESH MATERIALIZED VIEW public.term_insights;
+    END IF;
+END;
+$$;
+REVOKE ALL ON FUNCTION public.refresh_term_insights() FROM PUBLIC;
+GRANT EXECUTE ON FUNCTION public.refresh_term_insights() TO quizfreely_api;
+
+-- migrate:down
+DROP FUNCTION IF EXISTS public.refresh_term_insights();
+DROP MATERIALIZED VIEW IF EXISTS public.term_insights;
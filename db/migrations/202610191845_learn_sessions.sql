@@ -0,0 +1,63 @@
+-- migrate:up
+CREATE TYPE public.learn_stage AS ENUM (
+    'MULTIPLE_CHOICE',
+    'WRITTEN',
+    'LEARNED'
+);
+
+-- learn sessions are saved by the api (not the client), so they can be resumed on other devices
+CREATE TABLE public.learn_sessions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    studyset_ids uuid[] NOT NULL,
+    -- questions randomly answer with the term or def if it's null
+    answer_with public.answer_with_enum,
+    round_size integer NOT NULL,
+    round integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    completed_at timestamp with time zone
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.learn_sessions TO quizfreely_api;
+
+CREATE TABLE public.learn_session_terms (
+    learn_session_id uuid NOT NULL REFERENCES public.learn_sessions (id) ON DELETE CASCADE,
+    term_id uuid NOT NULL REFERENCES public.terms (id) ON DELETE CASCADE,
+    stage public.learn_stage DEFAULT 'MULTIPLE_CHOICE' NOT NULL,
+    "position" integer NOT NULL,
+    correct_count integer DEFAULT 0 NOT NULL,
+    incorrect_count integer DEFAULT 0 NOT NULL,
+    PRIMARY KEY (learn_session_id, term_id)
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.learn_session_terms TO quizfreely_api;
+
+-- each round's questions, data is the same as generated_practice_test_questions.data
+CREATE TABLE public.learn_session_questions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    learn_session_id uuid NOT NULL REFERENCES public.learn_sessions (id) ON DELETE CASCADE,
+    term_id uuid NOT NULL REFERENCES public.terms (id) ON DELETE CASCADE,
+    round integer NOT NULL,
+    "position" integer NOT NULL,
+    type public.question_type NOT NULL,
+    answer_with public.answer_with_enum NOT NULL,
+    term_snapshot text NOT NULL,
+    def_snapshot text NOT NULL,
+    data jsonb NOT NULL,
+    answered_at timestamp with time zone,
+    correct boolean
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.learn_session_questions TO quizfreely_api;
+
+CREATE INDEX learn_sessions_user_id_updated_at_idx ON public.learn_sessions (user_id, updated_at);
+CREATE INDEX learn_session_questions_session_id_round_idx ON public.learn_session_questions (learn_session_id, round, "position");
+
+ALTER TYPE public.review_activity_type_enum ADD VALUE 'LEARN';
+ALTER TABLE public.review_events ADD COLUMN learn_session_id uuid REFERENCES public.learn_sessions (id) ON DELETE SET NULL;
+
+-- migrate:down
+ALTER TABLE public.review_events DROP COLUMN IF EXISTS learn_session_id;
+-- enum values can't be dropped, so review_activity_type_enum keeps 'LEARN'
+DROP TABLE IF EXISTS public.learn_session_questions;
+DROP TABLE IF EXISTS public.learn_session_terms;
+DROP TABLE IF EXISTS public.learn_sessions;
+DROP TYPE IF EXISTS public.learn_stage;
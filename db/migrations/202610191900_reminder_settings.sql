@@ -0,0 +1,29 @@
+-- migrate:up
+CREATE TYPE public.reminder_channel AS ENUM (
+    'EMAIL',
+    'WEBHOOK'
+);
+
+-- study reminders are sent by the api's reminder job (see the reminders package)
+CREATE TABLE public.reminder_settings (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES auth.users (id) ON DELETE CASCADE,
+    enabled boolean DEFAULT true NOT NULL,
+    -- minutes after midnight, in timezone
+    reminder_minute integer NOT NULL CHECK (reminder_minute >= 0 AND reminder_minute < 1440),
+    timezone text DEFAULT 'UTC' NOT NULL,
+    channel public.reminder_channel DEFAULT 'EMAIL' NOT NULL,
+    webhook_url text,
+    -- for unsubscribe links in reminders, which work without signing in
+    unsubscribe_token text DEFAULT encode(public.gen_random_bytes(32), 'hex') NOT NULL UNIQUE,
+    -- the local date of the last reminder that was checked (even if nothing was due, so it's only checked once a day)
+    last_reminded_on date,
+    last_sent_at timestamp with time zone,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.reminder_settings TO quizfreely_api;
+
+CREATE INDEX reminder_settings_enabled_idx ON public.reminder_settings (user_id) WHERE enabled;
+
+-- migrate:down
+DROP TABLE IF EXISTS public.reminder_settings;
+DROP TYPE IF EXISTS public.reminder_channel;
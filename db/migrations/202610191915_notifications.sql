@@ -0,0 +1,38 @@
+-- migrate:up
+CREATE TYPE public.notification_type AS ENUM (
+    'STUDYSET_SAVED',
+    'STUDYSET_HIDDEN_BY_MODERATOR'
+);
+
+-- in-app notifications, created by the notifications package
+CREATE TABLE public.notifications (
+    id uuid DEFAULT gen_random_uuid() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    type public.notification_type NOT NULL,
+    -- the user who caused the notification, if it's shown
+    actor_user_id uuid REFERENCES auth.users (id) ON DELETE SET NULL,
+    studyset_id uuid REFERENCES public.studysets (id) ON DELETE CASCADE,
+    -- where the notification links to, if it isn't just its studyset
+    url text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    read_at timestamp with time zone
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.notifications TO quizfreely_api;
+
+CREATE INDEX notifications_user_id_created_at_idx ON public.notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX notifications_user_id_unread_idx ON public.notifications (user_id) WHERE read_at IS NULL;
+
+-- users get every type of notification unless they turn it off here
+CREATE TABLE public.notification_preferences (
+    user_id uuid NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    type public.notification_type NOT NULL,
+    enabled boolean NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.notification_preferences TO quizfreely_api;
+
+-- migrate:down
+DROP TABLE IF EXISTS public.notification_preferences;
+DROP TABLE IF EXISTS public.notifications;
+DROP TYPE IF EXISTS public.notification_type;
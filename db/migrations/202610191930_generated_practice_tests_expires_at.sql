@@ -0,0 +1,6 @@
+-- migrate:up
+-- for the generated practice test cleanup job, which deletes expired tests for every user
+CREATE INDEX generated_practice_tests_expires_at_idx ON public.generated_practice_tests (expires_at);
+
+-- migrate:down
+DROP INDEX IF EXISTS public.generated_practice_tests_expires_at_idx;
@@ -77,6 +77,28 @@ CREATE TYPE public.auth_type AS ENUM (
 );
 
 
+--
+-- Name: daily_goal_type; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.daily_goal_type AS ENUM (
+    'REVIEWS',
+    'MINUTES'
+);
+
+
+--
+-- Name: fsrs_optimization_status; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.fsrs_optimization_status AS ENUM (
+    'PENDING',
+    'RUNNING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+
 --
 -- Name: fsrs_rating; Type: TYPE; Schema: public; Owner: -
 --
@@ -102,6 +124,27 @@ CREATE TYPE public.fsrs_state AS ENUM (
 );
 
 
+--
+-- Name: learn_stage; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.learn_stage AS ENUM (
+    'MULTIPLE_CHOICE',
+    'WRITTEN',
+    'LEARNED'
+);
+
+
+--
+-- Name: notification_type; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.notification_type AS ENUM (
+    'STUDYSET_SAVED',
+    'STUDYSET_HIDDEN_BY_MODERATOR'
+);
+
+
 --
 -- Name: question_type; Type: TYPE; Schema: public; Owner: -
 --
@@ -113,13 +156,24 @@ CREATE TYPE public.question_type AS ENUM (
 );
 
 
+--
+-- Name: reminder_channel; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.reminder_channel AS ENUM (
+    'EMAIL',
+    'WEBHOOK'
+);
+
+
 --
 -- Name: review_activity_type_enum; Type: TYPE; Schema: public; Owner: -
 --
 
 CREATE TYPE public.review_activity_type_enum AS ENUM (
     'PRACTICE_TEST',
-    'MATCH'
+    'MATCH',
+    'LEARN'
 );
 
 
@@ -136,6 +190,37 @@ CREATE TYPE public.subject_category AS ENUM (
 );
 
 
+--
+-- Name: web_import_job_status; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.web_import_job_status AS ENUM (
+    'PENDING',
+    'RUNNING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+
+--
+-- Name: refresh_term_insights(); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.refresh_term_insights() RETURNS void
+    LANGUAGE plpgsql SECURITY DEFINER
+    SET search_path TO 'public'
+    AS $$
+BEGIN
+    -- views that were never populated (like from schema.sql) can't be refreshed concurrently
+    IF (SELECT relispopulated FROM pg_class WHERE oid = 'public.term_insights'::regclass) THEN
+        REFRESH MATERIALIZED VIEW CONCURRENTLY public.term_insights;
+    ELSE
+        REFRESH MATERIALIZED VIEW public.term_insights;
+    END IF;
+END;
+$$;
+
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
@@ -165,6 +250,21 @@ CREATE TABLE auth.users (
     oauth_google_email text,
     mod_perms boolean DEFAULT false NOT NULL,
     oauth_google_name text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    hide_from_leaderboards boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: achievements; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.achievements (
+    id text NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    metric text NOT NULL,
+    threshold integer NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
@@ -209,7 +309,29 @@ CREATE TABLE public.fsrs_cards (
     reps integer NOT NULL,
     scheduled_days integer NOT NULL,
     stability double precision NOT NULL,
-    state public.fsrs_state NOT NULL
+    state public.fsrs_state NOT NULL,
+    suspended boolean DEFAULT false NOT NULL,
+    buried_until timestamp with time zone,
+    leech boolean DEFAULT false NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: fsrs_parameters; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.fsrs_parameters (
+    user_id uuid NOT NULL,
+    weights double precision[],
+    log_loss double precision,
+    default_log_loss double precision,
+    review_count integer DEFAULT 0 NOT NULL,
+    status public.fsrs_optimization_status DEFAULT 'PENDING'::public.fsrs_optimization_status NOT NULL,
+    error text,
+    requested_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    optimized_at timestamp with time zone
 );
 
 
@@ -232,12 +354,109 @@ CREATE TABLE public.fsrs_review_logs (
 );
 
 
+--
+-- Name: generated_practice_test_questions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.generated_practice_test_questions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    generated_practice_test_id uuid NOT NULL,
+    term_id uuid NOT NULL,
+    term_snapshot text NOT NULL,
+    def_snapshot text NOT NULL,
+    type public.question_type NOT NULL,
+    answer_with public.answer_with_enum NOT NULL,
+    "position" integer NOT NULL,
+    data jsonb NOT NULL
+);
+
+
+--
+-- Name: generated_practice_tests; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.generated_practice_tests (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    studyset_ids uuid[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    submitted_at timestamp with time zone
+);
+
+
+--
+-- Name: idempotency_keys; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.idempotency_keys (
+    user_id uuid NOT NULL,
+    key text NOT NULL,
+    mutation text NOT NULL,
+    result jsonb,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: images; Type: TABLE; Schema: public; Owner: -
 --
 
 CREATE TABLE public.images (
-    object_key text NOT NULL
+    object_key text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    user_id uuid
+);
+
+
+--
+-- Name: learn_session_questions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.learn_session_questions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    learn_session_id uuid NOT NULL,
+    term_id uuid NOT NULL,
+    round integer NOT NULL,
+    "position" integer NOT NULL,
+    type public.question_type NOT NULL,
+    answer_with public.answer_with_enum NOT NULL,
+    term_snapshot text NOT NULL,
+    def_snapshot text NOT NULL,
+    data jsonb NOT NULL,
+    answered_at timestamp with time zone,
+    correct boolean
+);
+
+
+--
+-- Name: learn_session_terms; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.learn_session_terms (
+    learn_session_id uuid NOT NULL,
+    term_id uuid NOT NULL,
+    stage public.learn_stage DEFAULT 'MULTIPLE_CHOICE'::public.learn_stage NOT NULL,
+    "position" integer NOT NULL,
+    correct_count integer DEFAULT 0 NOT NULL,
+    incorrect_count integer DEFAULT 0 NOT NULL
+);
+
+
+--
+-- Name: learn_sessions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.learn_sessions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    studyset_ids uuid[] NOT NULL,
+    answer_with public.answer_with_enum,
+    round_size integer NOT NULL,
+    round integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    completed_at timestamp with time zone
 );
 
 
@@ -263,6 +482,34 @@ CREATE TABLE public.match_activity_studysets (
 );
 
 
+--
+-- Name: notification_preferences; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_preferences (
+    user_id uuid NOT NULL,
+    type public.notification_type NOT NULL,
+    enabled boolean NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: notifications; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notifications (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    type public.notification_type NOT NULL,
+    actor_user_id uuid,
+    studyset_id uuid,
+    url text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    read_at timestamp with time zone
+);
+
+
 --
 -- Name: practice_test_questions; Type: TABLE; Schema: public; Owner: -
 --
@@ -304,6 +551,25 @@ CREATE TABLE public.practice_tests (
 );
 
 
+--
+-- Name: reminder_settings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.reminder_settings (
+    user_id uuid NOT NULL,
+    enabled boolean DEFAULT true NOT NULL,
+    reminder_minute integer NOT NULL,
+    timezone text DEFAULT 'UTC'::text NOT NULL,
+    channel public.reminder_channel DEFAULT 'EMAIL'::public.reminder_channel NOT NULL,
+    webhook_url text,
+    unsubscribe_token text DEFAULT encode(public.gen_random_bytes(32), 'hex'::text) NOT NULL,
+    last_reminded_on date,
+    last_sent_at timestamp with time zone,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT reminder_settings_reminder_minute_check CHECK (((reminder_minute >= 0) AND (reminder_minute < 1440)))
+);
+
+
 --
 -- Name: review_events; Type: TABLE; Schema: public; Owner: -
 --
@@ -320,7 +586,8 @@ CREATE TABLE public.review_events (
     practice_test_question_type public.question_type,
     review_activity_type public.review_activity_type_enum NOT NULL,
     answered_string text,
-    match_activity_id uuid
+    match_activity_id uuid,
+    learn_session_id uuid
 );
 
 
@@ -345,6 +612,42 @@ CREATE TABLE public.schema_migrations (
 );
 
 
+--
+-- Name: study_settings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.study_settings (
+    user_id uuid NOT NULL,
+    desired_retention double precision,
+    max_new_cards_per_day integer,
+    max_reviews_per_day integer,
+    learning_steps_minutes integer[],
+    relearning_steps_minutes integer[],
+    day_rollover_hour integer,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    leech_threshold integer,
+    daily_goal_type public.daily_goal_type,
+    daily_goal integer
+);
+
+
+--
+-- Name: studyset_study_settings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_study_settings (
+    user_id uuid NOT NULL,
+    studyset_id uuid NOT NULL,
+    desired_retention double precision,
+    max_new_cards_per_day integer,
+    max_reviews_per_day integer,
+    learning_steps_minutes integer[],
+    relearning_steps_minutes integer[],
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    leech_threshold integer
+);
+
+
 --
 -- Name: studysets; Type: TABLE; Schema: public; Owner: -
 --
@@ -386,6 +689,50 @@ CREATE TABLE public.subjects (
 );
 
 
+--
+-- Name: term_insights; Type: MATERIALIZED VIEW; Schema: public; Owner: -
+--
+
+CREATE MATERIALIZED VIEW public.term_insights AS
+ WITH term_stats AS (
+         SELECT review_events.term_id,
+            count(*) AS attempts,
+            count(*) FILTER (WHERE (NOT review_events.correct)) AS incorrect,
+            count(DISTINCT review_events.user_id) AS users
+           FROM public.review_events
+          GROUP BY review_events.term_id
+        ), wrong_terms AS (
+         SELECT DISTINCT ON (review_events.term_id) review_events.term_id,
+            review_events.answered_term_id,
+            count(DISTINCT review_events.user_id) AS users
+           FROM public.review_events
+          WHERE ((NOT review_events.correct) AND (review_events.answered_term_id IS NOT NULL) AND (review_events.answered_term_id <> review_events.term_id))
+          GROUP BY review_events.term_id, review_events.answered_term_id
+          ORDER BY review_events.term_id, (count(DISTINCT review_events.user_id)) DESC, (count(*)) DESC, review_events.answered_term_id
+        ), wrong_strings AS (
+         SELECT DISTINCT ON (review_events.term_id) review_events.term_id,
+            lower(btrim(review_events.answered_string)) AS answered_string,
+            count(DISTINCT review_events.user_id) AS users
+           FROM public.review_events
+          WHERE ((NOT review_events.correct) AND (btrim(review_events.answered_string) <> ''::text))
+          GROUP BY review_events.term_id, (lower(btrim(review_events.answered_string)))
+          ORDER BY review_events.term_id, (count(DISTINCT review_events.user_id)) DESC, (count(*)) DESC, (lower(btrim(review_events.answered_string)))
+        )
+ SELECT ts.term_id,
+    ts.attempts,
+    ts.incorrect,
+    ts.users,
+    wt.answered_term_id AS common_wrong_term_id,
+    wt.users AS common_wrong_term_users,
+    ws.answered_string AS common_wrong_string,
+    ws.users AS common_wrong_string_users,
+    now() AS refreshed_at
+   FROM ((term_stats ts
+     LEFT JOIN wrong_terms wt ON ((wt.term_id = ts.term_id)))
+     LEFT JOIN wrong_strings ws ON ((ws.term_id = ts.term_id)))
+  WITH NO DATA;
+
+
 --
 -- Name: term_progress; Type: TABLE; Schema: public; Owner: -
 --
@@ -403,7 +750,8 @@ CREATE TABLE public.term_progress (
     term_correct_count integer DEFAULT 0 NOT NULL,
     term_incorrect_count integer DEFAULT 0 NOT NULL,
     def_correct_count integer DEFAULT 0 NOT NULL,
-    def_incorrect_count integer DEFAULT 0 NOT NULL
+    def_incorrect_count integer DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
@@ -424,6 +772,38 @@ CREATE TABLE public.terms (
 );
 
 
+--
+-- Name: user_achievements; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_achievements (
+    user_id uuid NOT NULL,
+    achievement_id text NOT NULL,
+    earned_at timestamp with time zone NOT NULL
+);
+
+
+--
+-- Name: web_import_jobs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.web_import_jobs (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    url text NOT NULL,
+    normalized_url text NOT NULL,
+    source text NOT NULL,
+    status public.web_import_job_status DEFAULT 'PENDING'::public.web_import_job_status NOT NULL,
+    fetcher text,
+    result jsonb,
+    error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    download_images boolean DEFAULT false NOT NULL,
+    user_id uuid
+);
+
+
 --
 -- Name: sessions sessions_pkey; Type: CONSTRAINT; Schema: auth; Owner: -
 --
@@ -456,6 +836,14 @@ ALTER TABLE ONLY auth.users
     ADD CONSTRAINT users_username_key UNIQUE (username);
 
 
+--
+-- Name: achievements achievements_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.achievements
+    ADD CONSTRAINT achievements_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: folder_studysets folder_studysets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -488,6 +876,14 @@ ALTER TABLE ONLY public.fsrs_cards
     ADD CONSTRAINT fsrs_cards_pkey PRIMARY KEY (term_id, user_id);
 
 
+--
+-- Name: fsrs_parameters fsrs_parameters_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.fsrs_parameters
+    ADD CONSTRAINT fsrs_parameters_pkey PRIMARY KEY (user_id);
+
+
 --
 -- Name: fsrs_review_logs fsrs_review_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -496,6 +892,30 @@ ALTER TABLE ONLY public.fsrs_review_logs
     ADD CONSTRAINT fsrs_review_logs_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: generated_practice_test_questions generated_practice_test_questions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.generated_practice_test_questions
+    ADD CONSTRAINT generated_practice_test_questions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: generated_practice_tests generated_practice_tests_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.generated_practice_tests
+    ADD CONSTRAINT generated_practice_tests_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: idempotency_keys idempotency_keys_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.idempotency_keys
+    ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (user_id, key);
+
+
 --
 -- Name: images images_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -504,6 +924,30 @@ ALTER TABLE ONLY public.images
     ADD CONSTRAINT images_pkey PRIMARY KEY (object_key);
 
 
+--
+-- Name: learn_session_questions learn_session_questions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_questions
+    ADD CONSTRAINT learn_session_questions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: learn_session_terms learn_session_terms_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_terms
+    ADD CONSTRAINT learn_session_terms_pkey PRIMARY KEY (learn_session_id, term_id);
+
+
+--
+-- Name: learn_sessions learn_sessions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_sessions
+    ADD CONSTRAINT learn_sessions_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: match_activities match_activities_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -520,6 +964,22 @@ ALTER TABLE ONLY public.match_activity_studysets
     ADD CONSTRAINT match_activity_studysets_pkey PRIMARY KEY (match_id, studyset_id);
 
 
+--
+-- Name: notification_preferences notification_preferences_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_pkey PRIMARY KEY (user_id, type);
+
+
+--
+-- Name: notifications notifications_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: practice_test_questions practice_test_questions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -552,6 +1012,22 @@ ALTER TABLE ONLY public.practice_tests
     ADD CONSTRAINT practice_tests_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: reminder_settings reminder_settings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reminder_settings
+    ADD CONSTRAINT reminder_settings_pkey PRIMARY KEY (user_id);
+
+
+--
+-- Name: reminder_settings reminder_settings_unsubscribe_token_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reminder_settings
+    ADD CONSTRAINT reminder_settings_unsubscribe_token_key UNIQUE (unsubscribe_token);
+
+
 --
 -- Name: review_events review_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -584,6 +1060,22 @@ ALTER TABLE ONLY public.schema_migrations
     ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
 
 
+--
+-- Name: study_settings study_settings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.study_settings
+    ADD CONSTRAINT study_settings_pkey PRIMARY KEY (user_id);
+
+
+--
+-- Name: studyset_study_settings studyset_study_settings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_study_settings
+    ADD CONSTRAINT studyset_study_settings_pkey PRIMARY KEY (user_id, studyset_id);
+
+
 --
 -- Name: studysets studysets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -632,6 +1124,85 @@ ALTER TABLE ONLY public.terms
     ADD CONSTRAINT terms_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: user_achievements user_achievements_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_achievements
+    ADD CONSTRAINT user_achievements_pkey PRIMARY KEY (user_id, achievement_id);
+
+
+--
+-- Name: web_import_jobs web_import_jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.web_import_jobs
+    ADD CONSTRAINT web_import_jobs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: fsrs_cards_user_id_due_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX fsrs_cards_user_id_due_idx ON public.fsrs_cards USING btree (user_id, due);
+
+
+--
+-- Name: fsrs_cards_user_id_leech_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX fsrs_cards_user_id_leech_idx ON public.fsrs_cards USING btree (user_id) WHERE leech;
+
+
+--
+-- Name: fsrs_cards_user_id_updated_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX fsrs_cards_user_id_updated_at_idx ON public.fsrs_cards USING btree (user_id, updated_at);
+
+
+--
+-- Name: fsrs_review_logs_user_id_review_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX fsrs_review_logs_user_id_review_idx ON public.fsrs_review_logs USING btree (user_id, review);
+
+
+--
+-- Name: fsrs_review_logs_user_id_term_id_review_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX fsrs_review_logs_user_id_term_id_review_idx ON public.fsrs_review_logs USING btree (user_id, term_id, review);
+
+
+--
+-- Name: generated_practice_test_questions_test_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX generated_practice_test_questions_test_id_idx ON public.generated_practice_test_questions USING btree (generated_practice_test_id, "position");
+
+
+--
+-- Name: generated_practice_tests_expires_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX generated_practice_tests_expires_at_idx ON public.generated_practice_tests USING btree (expires_at);
+
+
+--
+-- Name: generated_practice_tests_user_id_expires_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX generated_practice_tests_user_id_expires_at_idx ON public.generated_practice_tests USING btree (user_id, expires_at);
+
+
+--
+-- Name: idempotency_keys_created_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX idempotency_keys_created_at_idx ON public.idempotency_keys USING btree (created_at);
+
+
 --
 -- Name: idx_pts_studyset_id; Type: INDEX; Schema: public; Owner: -
 --
@@ -674,6 +1245,62 @@ CREATE INDEX idx_terms_def_image_key ON public.terms USING btree (def_image_key)
 CREATE INDEX idx_terms_term_image_key ON public.terms USING btree (term_image_key);
 
 
+--
+-- Name: learn_session_questions_session_id_round_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX learn_session_questions_session_id_round_idx ON public.learn_session_questions USING btree (learn_session_id, round, "position");
+
+
+--
+-- Name: learn_sessions_user_id_updated_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX learn_sessions_user_id_updated_at_idx ON public.learn_sessions USING btree (user_id, updated_at);
+
+
+--
+-- Name: match_activity_studysets_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX match_activity_studysets_studyset_id_idx ON public.match_activity_studysets USING btree (studyset_id);
+
+
+--
+-- Name: notifications_user_id_created_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_user_id_created_at_idx ON public.notifications USING btree (user_id, created_at DESC, id DESC);
+
+
+--
+-- Name: notifications_user_id_unread_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_user_id_unread_idx ON public.notifications USING btree (user_id) WHERE (read_at IS NULL);
+
+
+--
+-- Name: reminder_settings_enabled_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX reminder_settings_enabled_idx ON public.reminder_settings USING btree (user_id) WHERE enabled;
+
+
+--
+-- Name: review_events_match_activity_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX review_events_match_activity_id_idx ON public.review_events USING btree (match_activity_id);
+
+
+--
+-- Name: review_events_user_id_timestamp_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX review_events_user_id_timestamp_idx ON public.review_events USING btree (user_id, "timestamp");
+
+
 --
 -- Name: studysets_title_trgm_idx; Type: INDEX; Schema: public; Owner: -
 --
@@ -688,6 +1315,27 @@ CREATE INDEX studysets_title_trgm_idx ON public.studysets USING gin (lower(title
 CREATE INDEX subject_keywords_trgm_idx ON public.subject_keywords USING gin (keyword public.gin_trgm_ops);
 
 
+--
+-- Name: term_insights_term_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX term_insights_term_id_idx ON public.term_insights USING btree (term_id);
+
+
+--
+-- Name: term_progress_user_id_updated_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX term_progress_user_id_updated_at_idx ON public.term_progress USING btree (user_id, updated_at);
+
+
+--
+-- Name: terms_studyset_id_sort_order_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_studyset_id_sort_order_idx ON public.terms USING btree (studyset_id, sort_order);
+
+
 --
 -- Name: textsearch_title_idx; Type: INDEX; Schema: public; Owner: -
 --
@@ -695,6 +1343,20 @@ CREATE INDEX subject_keywords_trgm_idx ON public.subject_keywords USING gin (key
 CREATE INDEX textsearch_title_idx ON public.studysets USING gin (tsvector_title);
 
 
+--
+-- Name: web_import_jobs_active_url_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX web_import_jobs_active_url_idx ON public.web_import_jobs USING btree (normalized_url, download_images) WHERE (status = ANY (ARRAY['PENDING'::public.web_import_job_status, 'RUNNING'::public.web_import_job_status]));
+
+
+--
+-- Name: web_import_jobs_succeeded_url_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX web_import_jobs_succeeded_url_idx ON public.web_import_jobs USING btree (normalized_url, finished_at DESC) WHERE (status = 'SUCCEEDED'::public.web_import_job_status);
+
+
 --
 -- Name: folder_studysets folder_studysets_folder_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -743,6 +1405,14 @@ ALTER TABLE ONLY public.fsrs_cards
     ADD CONSTRAINT fsrs_cards_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id);
 
 
+--
+-- Name: fsrs_parameters fsrs_parameters_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.fsrs_parameters
+    ADD CONSTRAINT fsrs_parameters_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: fsrs_review_logs fsrs_review_logs_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -759,6 +1429,86 @@ ALTER TABLE ONLY public.fsrs_review_logs
     ADD CONSTRAINT fsrs_review_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id);
 
 
+--
+-- Name: generated_practice_test_questions generated_practice_test_questions_generated_practice_test_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.generated_practice_test_questions
+    ADD CONSTRAINT generated_practice_test_questions_generated_practice_test_id_fkey FOREIGN KEY (generated_practice_test_id) REFERENCES public.generated_practice_tests(id) ON DELETE CASCADE;
+
+
+--
+-- Name: generated_practice_test_questions generated_practice_test_questions_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.generated_practice_test_questions
+    ADD CONSTRAINT generated_practice_test_questions_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: generated_practice_tests generated_practice_tests_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.generated_practice_tests
+    ADD CONSTRAINT generated_practice_tests_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: idempotency_keys idempotency_keys_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.idempotency_keys
+    ADD CONSTRAINT idempotency_keys_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: images images_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.images
+    ADD CONSTRAINT images_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: learn_session_questions learn_session_questions_learn_session_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_questions
+    ADD CONSTRAINT learn_session_questions_learn_session_id_fkey FOREIGN KEY (learn_session_id) REFERENCES public.learn_sessions(id) ON DELETE CASCADE;
+
+
+--
+-- Name: learn_session_questions learn_session_questions_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_questions
+    ADD CONSTRAINT learn_session_questions_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: learn_session_terms learn_session_terms_learn_session_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_terms
+    ADD CONSTRAINT learn_session_terms_learn_session_id_fkey FOREIGN KEY (learn_session_id) REFERENCES public.learn_sessions(id) ON DELETE CASCADE;
+
+
+--
+-- Name: learn_session_terms learn_session_terms_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_session_terms
+    ADD CONSTRAINT learn_session_terms_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: learn_sessions learn_sessions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.learn_sessions
+    ADD CONSTRAINT learn_sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: match_activities match_activities_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -783,6 +1533,38 @@ ALTER TABLE ONLY public.match_activity_studysets
     ADD CONSTRAINT match_activity_studysets_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
 
 
+--
+-- Name: notification_preferences notification_preferences_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: notifications notifications_actor_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_actor_user_id_fkey FOREIGN KEY (actor_user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: notifications notifications_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: notifications notifications_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: practice_test_questions practice_test_questions_practice_test_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -823,6 +1605,14 @@ ALTER TABLE ONLY public.practice_tests
     ADD CONSTRAINT practice_tests_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
 
 
+--
+-- Name: reminder_settings reminder_settings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reminder_settings
+    ADD CONSTRAINT reminder_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: review_events review_events_answered_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -831,6 +1621,14 @@ ALTER TABLE ONLY public.review_events
     ADD CONSTRAINT review_events_answered_term_id_fkey FOREIGN KEY (answered_term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
 
 
+--
+-- Name: review_events review_events_learn_session_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.review_events
+    ADD CONSTRAINT review_events_learn_session_id_fkey FOREIGN KEY (learn_session_id) REFERENCES public.learn_sessions(id) ON DELETE SET NULL;
+
+
 --
 -- Name: review_events review_events_match_activity_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -879,6 +1677,30 @@ ALTER TABLE ONLY public.saved_studysets
     ADD CONSTRAINT saved_studysets_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
 
 
+--
+-- Name: study_settings study_settings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.study_settings
+    ADD CONSTRAINT study_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_study_settings studyset_study_settings_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_study_settings
+    ADD CONSTRAINT studyset_study_settings_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_study_settings studyset_study_settings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_study_settings
+    ADD CONSTRAINT studyset_study_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: studysets studysets_subject_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -943,6 +1765,30 @@ ALTER TABLE ONLY public.terms
     ADD CONSTRAINT terms_term_image_key_fkey FOREIGN KEY (term_image_key) REFERENCES public.images(object_key);
 
 
+--
+-- Name: user_achievements user_achievements_achievement_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_achievements
+    ADD CONSTRAINT user_achievements_achievement_id_fkey FOREIGN KEY (achievement_id) REFERENCES public.achievements(id) ON DELETE CASCADE;
+
+
+--
+-- Name: user_achievements user_achievements_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_achievements
+    ADD CONSTRAINT user_achievements_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: web_import_jobs web_import_jobs_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.web_import_jobs
+    ADD CONSTRAINT web_import_jobs_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -996,4 +1842,21 @@ INSERT INTO public.schema_migrations (version) VALUES
     ('202606291100'),
     ('202606301100'),
     ('202607012025'),
-    ('202608082030');
+    ('202608082030'),
+    ('202610191530'),
+    ('202610191545'),
+    ('202610191600'),
+    ('202610191615'),
+    ('202610191630'),
+    ('202610191645'),
+    ('202610191700'),
+    ('202610191715'),
+    ('202610191730'),
+    ('202610191745'),
+    ('202610191800'),
+    ('202610191815'),
+    ('202610191830'),
+    ('202610191845'),
+    ('202610191900'),
+    ('202610191915'),
+    ('202610191930');
@@ -0,0 +1,191 @@
+package fsrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+optimizations can be requested again after this long,
+which also retries optimizations that got stuck (like if the server restarted while one was running)
+*/
+const OptimizationRequestInterval = time.Hour
+
+/* optimizing is CPU heavy, so only a few run at once */
+var optimizeSlots = make(chan struct{}, 2)
+
+/*
+LoadUserParameters returns the default parameters with the user's optimized weights,
+or the default weights if they haven't been optimized (or the defaults were better)
+*/
+func LoadUserParameters(ctx context.Context, db *pgxpool.Pool, userID string) (Parameters, error) {
+	params := DefaultParameters()
+	var weights []float64
+	err := db.QueryRow(
+		ctx,
+		"SELECT weights FROM fsrs_parameters WHERE user_id = $1",
+		userID,
+	).Scan(&weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return params, nil
+	}
+	if err != nil {
+		return params, err
+	}
+	if weights != nil {
+		params.Weights = weights
+	}
+	return params, params.Validate()
+}
+
+/* LoadReviewHistories loads each of a user's cards' review logs, with the most recently reviewed cards first */
+func LoadReviewHistories(ctx context.Context, db *pgxpool.Pool, userID string) ([]ReviewHistory, error) {
+	rows, err := db.Query(
+		ctx,
+		`SELECT rl.term_id, rl.rating, rl.review
+		FROM fsrs_review_logs rl
+		JOIN (
+			SELECT term_id, max(review) AS last_review
+			FROM fsrs_review_logs
+			WHERE user_id = $1 AND term_id IS NOT NULL AND rating <> 'MANUAL'
+			GROUP BY term_id
+		) cards ON cards.term_id = rl.term_id
+		WHERE rl.user_id = $1 AND rl.rating <> 'MANUAL'
+		ORDER BY cards.last_review DESC, rl.term_id, rl.review`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []ReviewHistory
+	lastTermID := ""
+	for rows.Next() {
+		var termID, rating string
+		var review time.Time
+		if err := rows.Scan(&termID, &rating, &review); err != nil {
+			return nil, err
+		}
+		r, err := ParseRating(rating)
+		if err != nil {
+			return nil, err
+		}
+		if termID != lastTermID {
+			histories = append(histories, nil)
+			lastTermID = termID
+		}
+		histories[len(histories)-1] = append(histories[len(histories)-1], ReviewEntry{Rating: r, Review: review})
+	}
+	return histories, rows.Err()
+}
+
+/*
+RequestOptimization marks a user's parameters as PENDING,
+unless an optimization was already requested in the last OptimizationRequestInterval.
+If requested is true, the caller should run OptimizeUser.
+*/
+func RequestOptimization(ctx context.Context, db *pgxpool.Pool, userID string) (requested bool, err error) {
+	tag, err := db.Exec(
+		ctx,
+		`INSERT INTO fsrs_parameters (user_id, status, requested_at)
+		VALUES ($1, 'PENDING', now())
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = 'PENDING',
+			requested_at = now(),
+			started_at = null,
+			error = null
+		WHERE fsrs_parameters.requested_at < now() - make_interval(secs => $2)`,
+		userID,
+		OptimizationRequestInterval.Seconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+/*
+OptimizeUser fits FSRS weights to a user's review history, if their parameters are PENDING,
+and saves them with their log loss. The fitted weights are only used for scheduling if they're better than the defaults.
+*/
+func OptimizeUser(ctx context.Context, db *pgxpool.Pool, userID string) error {
+	select {
+	case optimizeSlots <- struct{}{}:
+		defer func() { <-optimizeSlots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	tag, err := db.Exec(
+		ctx,
+		`UPDATE fsrs_parameters SET status = 'RUNNING', started_at = now()
+		WHERE user_id = $1 AND status = 'PENDING'`,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start fsrs optimization: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		/* already running or done */
+		return nil
+	}
+
+	result, err := optimizeUser(ctx, db, userID)
+	if err != nil {
+		errMsg := "failed to optimize fsrs parameters"
+		if errors.Is(err, ErrNotEnoughReviews) {
+			errMsg = err.Error()
+		}
+		/* ctx might be done, but the status still needs to be saved */
+		_, dbErr := db.Exec(
+			context.WithoutCancel(ctx),
+			`UPDATE fsrs_parameters SET status = 'FAILED', error = $2
+			WHERE user_id = $1`,
+			userID,
+			errMsg,
+		)
+		if dbErr != nil {
+			return fmt.Errorf("failed to save fsrs optimization error: %w", dbErr)
+		}
+		return err
+	}
+
+	var weights []float64
+	if result.Better() {
+		weights = result.Weights
+	}
+	_, err = db.Exec(
+		ctx,
+		`UPDATE fsrs_parameters SET
+			status = 'SUCCEEDED',
+			weights = $2,
+			log_loss = $3,
+			default_log_loss = $4,
+			review_count = $5,
+			optimized_at = now()
+		WHERE user_id = $1`,
+		userID,
+		weights,
+		result.LogLoss,
+		result.DefaultLogLoss,
+		result.ReviewCount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save fsrs parameters: %w", err)
+	}
+	return nil
+}
+
+func optimizeUser(ctx context.Context, db *pgxpool.Pool, userID string) (*OptimizeResult, error) {
+	histories, err := LoadReviewHistories(ctx, db, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fsrs review logs: %w", err)
+	}
+	return Optimize(ctx, histories)
+}
@@ -0,0 +1,34 @@
+package fsrs
+
+import "time"
+
+/* stops cards with short intervals from being simulated for too long */
+const maxForecastReviews = 100
+
+/*
+ForecastReviews projects when a card will be reviewed between now and until,
+assuming it's reviewed when it's due (or now, if it's overdue) and rated Good every time.
+New cards aren't forecast, because they aren't due until they're studied.
+*/
+func (s *Scheduler) ForecastReviews(card Card, now time.Time, until time.Time) []time.Time {
+	if card.State == New {
+		return nil
+	}
+	var reviews []time.Time
+	for range maxForecastReviews {
+		review := card.Due
+		if review.Before(now) {
+			review = now
+		}
+		if !review.Before(until) {
+			break
+		}
+		next, _, err := s.Review(card, Good, review)
+		if err != nil {
+			break
+		}
+		reviews = append(reviews, review)
+		card = next
+	}
+	return reviews
+}
@@ -0,0 +1,49 @@
+package fsrs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastReviews(t *testing.T) {
+	s := newTestScheduler(t)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	until := now.AddDate(0, 0, 30)
+
+	/* new cards aren't forecast */
+	require.Empty(t, s.ForecastReviews(NewCard(now), now, until))
+
+	/* overdue cards are reviewed now, then again at longer and longer intervals */
+	lastReview := now.AddDate(0, 0, -5)
+	card := Card{
+		Difficulty:    5,
+		Stability:     3,
+		Due:           now.AddDate(0, 0, -2),
+		LastReview:    &lastReview,
+		Reps:          3,
+		ScheduledDays: 3,
+		State:         Review,
+	}
+	reviews := s.ForecastReviews(card, now, until)
+	require.GreaterOrEqual(t, len(reviews), 2)
+	require.Equal(t, now, reviews[0])
+	for i := 1; i < len(reviews); i++ {
+		require.True(t, reviews[i].After(reviews[i-1]))
+		require.True(t, reviews[i].Before(until))
+	}
+	for i := 2; i < len(reviews); i++ {
+		require.Greater(t, reviews[i].Sub(reviews[i-1]), reviews[i-1].Sub(reviews[i-2]))
+	}
+
+	/* cards due after until aren't forecast */
+	card.Due = until.AddDate(0, 0, 1)
+	require.Empty(t, s.ForecastReviews(card, now, until))
+
+	/* learning cards go through their remaining steps first */
+	learning, _, err := s.Review(NewCard(now), Good, now)
+	require.NoError(t, err)
+	reviews = s.ForecastReviews(learning, now, until)
+	require.Equal(t, now.Add(10*time.Minute), reviews[0])
+}
@@ -0,0 +1,429 @@
+/*
+Package fsrs implements the FSRS-6 spaced repetition scheduler,
+matching ts-fsrs (which the web client used before scheduling moved to the API),
+with learning/relearning steps and short-term scheduling, without fuzz.
+*/
+package fsrs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+/* values match the fsrs_state enum in postgres & FSRSState in graphql */
+type State string
+
+const (
+	New        State = "NEW"
+	Learning   State = "LEARNING"
+	Review     State = "REVIEW"
+	Relearning State = "RELEARNING"
+)
+
+type Rating int
+
+const (
+	Again Rating = 1
+	Hard  Rating = 2
+	Good  Rating = 3
+	Easy  Rating = 4
+)
+
+/* String returns the rating like the fsrs_rating enum in postgres & FSRSRating in graphql */
+func (r Rating) String() string {
+	switch r {
+	case Again:
+		return "AGAIN"
+	case Hard:
+		return "HARD"
+	case Good:
+		return "GOOD"
+	case Easy:
+		return "EASY"
+	}
+	return fmt.Sprintf("Rating(%d)", int(r))
+}
+
+func ParseRating(s string) (Rating, error) {
+	switch s {
+	case "AGAIN":
+		return Again, nil
+	case "HARD":
+		return Hard, nil
+	case "GOOD":
+		return Good, nil
+	case "EASY":
+		return Easy, nil
+	}
+	return 0, fmt.Errorf("invalid fsrs rating %q", s)
+}
+
+const (
+	minStability  = 0.001
+	maxStability  = 36500.0
+	minDifficulty = 1.0
+	maxDifficulty = 10.0
+)
+
+/* DefaultWeights are FSRS-6's default parameters (w0 to w20) */
+var DefaultWeights = []float64{
+	0.212, 1.2931, 2.3065, 8.2956, 6.4133,
+	0.8334, 3.0194, 0.001, 1.8722, 0.1666,
+	0.796, 1.4835, 0.0614, 0.2629, 1.6483,
+	0.6014, 1.8729, 0.5425, 0.0912, 0.0658,
+	0.1542,
+}
+
+type Parameters struct {
+	/* 21 FSRS-6 weights */
+	Weights []float64
+	/* target probability of recalling a card when it's due, between 0 and 1 */
+	RequestRetention float64
+	/* in days */
+	MaximumInterval int
+	LearningSteps   []time.Duration
+	RelearningSteps []time.Duration
+	/* short-term stability for same-day reviews */
+	EnableShortTerm bool
+}
+
+func DefaultParameters() Parameters {
+	return Parameters{
+		Weights:          DefaultWeights,
+		RequestRetention: 0.9,
+		MaximumInterval:  36500,
+		LearningSteps:    []time.Duration{time.Minute, 10 * time.Minute},
+		RelearningSteps:  []time.Duration{10 * time.Minute},
+		EnableShortTerm:  true,
+	}
+}
+
+func (p Parameters) Validate() error {
+	if len(p.Weights) != len(DefaultWeights) {
+		return fmt.Errorf("fsrs needs %d weights, got %d", len(DefaultWeights), len(p.Weights))
+	}
+	for _, w := range p.Weights {
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return errors.New("fsrs weights must be finite")
+		}
+	}
+	if p.Weights[20] <= 0 {
+		return errors.New("fsrs decay weight (w20) must be positive")
+	}
+	if p.RequestRetention <= 0 || p.RequestRetention >= 1 {
+		return errors.New("fsrs request retention must be between 0 and 1")
+	}
+	if p.MaximumInterval < 1 {
+		return errors.New("fsrs maximum interval must be at least 1 day")
+	}
+	return nil
+}
+
+type Card struct {
+	Difficulty    float64
+	Stability     float64
+	Due           time.Time
+	LastReview    *time.Time
+	LearningSteps int
+	Reps          int
+	Lapses        int
+	ScheduledDays int
+	State         State
+}
+
+/* NewCard returns a card that hasn't been reviewed yet, due now */
+func NewCard(now time.Time) Card {
+	return Card{
+		Due:   now,
+		State: New,
+	}
+}
+
+/*
+ReviewLog has the card's state BEFORE a review,
+like ts-fsrs review logs & the fsrs_review_logs table
+*/
+type ReviewLog struct {
+	Rating        Rating
+	State         State
+	Due           time.Time
+	Stability     float64
+	Difficulty    float64
+	LearningSteps int
+	ScheduledDays int
+	Review        time.Time
+}
+
+type Scheduler struct {
+	params Parameters
+	decay  float64
+	factor float64
+	/* multiplies stability to get the interval for RequestRetention */
+	intervalModifier float64
+}
+
+func NewScheduler(params Parameters) (*Scheduler, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	decay := -params.Weights[20]
+	factor := math.Pow(0.9, 1/decay) - 1
+	return &Scheduler{
+		params:           params,
+		decay:            decay,
+		factor:           factor,
+		intervalModifier: (math.Pow(params.RequestRetention, 1/decay) - 1) / factor,
+	}, nil
+}
+
+func (s *Scheduler) Parameters() Parameters {
+	return s.params
+}
+
+/* forgettingCurve returns the probability of recall after elapsedDays with stability */
+func (s *Scheduler) forgettingCurve(elapsedDays float64, stability float64) float64 {
+	return math.Pow(1+s.factor*elapsedDays/stability, s.decay)
+}
+
+/* Retrievability returns the card's probability of recall at now (0 for new cards) */
+func (s *Scheduler) Retrievability(card Card, now time.Time) float64 {
+	if card.State == New || card.LastReview == nil {
+		return 0
+	}
+	return s.forgettingCurve(float64(elapsedDays(*card.LastReview, now)), card.Stability)
+}
+
+/* elapsedDays counts calendar days (in UTC) between reviews, like ts-fsrs */
+func elapsedDays(lastReview time.Time, now time.Time) int {
+	last := lastReview.UTC()
+	cur := now.UTC()
+	lastDay := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	curDay := time.Date(cur.Year(), cur.Month(), cur.Day(), 0, 0, 0, 0, time.UTC)
+	return max(0, int(curDay.Sub(lastDay).Hours()/24))
+}
+
+func (s *Scheduler) nextInterval(stability float64) int {
+	interval := math.Round(stability * s.intervalModifier)
+	return int(clamp(interval, 1, float64(s.params.MaximumInterval)))
+}
+
+func (s *Scheduler) initStability(rating Rating) float64 {
+	return max(s.params.Weights[rating-1], 0.1)
+}
+
+/* initDifficulty isn't clamped, because mean reversion uses the unclamped value for Easy */
+func (s *Scheduler) initDifficulty(rating Rating) float64 {
+	w := s.params.Weights
+	return w[4] - math.Exp(w[5]*float64(rating-1)) + 1
+}
+
+func (s *Scheduler) nextDifficulty(difficulty float64, rating Rating) float64 {
+	w := s.params.Weights
+	delta := -w[6] * float64(rating-3)
+	/* linear damping, changes are smaller closer to max difficulty */
+	next := difficulty + delta*(10-difficulty)/9
+	/* mean reversion towards the initial difficulty of an Easy rating */
+	next = w[7]*s.initDifficulty(Easy) + (1-w[7])*next
+	return clamp(next, minDifficulty, maxDifficulty)
+}
+
+func (s *Scheduler) nextRecallStability(difficulty float64, stability float64, retrievability float64, rating Rating) float64 {
+	w := s.params.Weights
+	hardPenalty := 1.0
+	if rating == Hard {
+		hardPenalty = w[15]
+	}
+	easyBonus := 1.0
+	if rating == Easy {
+		easyBonus = w[16]
+	}
+	next := stability * (1 + math.Exp(w[8])*
+		(11-difficulty)*
+		math.Pow(stability, -w[9])*
+		(math.Exp((1-retrievability)*w[10])-1)*
+		hardPenalty*
+		easyBonus)
+	return clamp(next, minStability, maxStability)
+}
+
+func (s *Scheduler) nextForgetStability(difficulty float64, stability float64, retrievability float64) float64 {
+	w := s.params.Weights
+	next := w[11] *
+		math.Pow(difficulty, -w[12]) *
+		(math.Pow(stability+1, w[13]) - 1) *
+		math.Exp((1-retrievability)*w[14])
+	return clamp(next, minStability, maxStability)
+}
+
+func (s *Scheduler) nextShortTermStability(stability float64, rating Rating) float64 {
+	w := s.params.Weights
+	increase := math.Exp(w[17]*(float64(rating)-3+w[18])) * math.Pow(stability, -w[19])
+	if rating >= Good {
+		increase = max(increase, 1)
+	}
+	return clamp(stability*increase, minStability, maxStability)
+}
+
+/* nextMemoryState returns the difficulty & stability after a review of a card that isn't new */
+func (s *Scheduler) nextMemoryState(card Card, elapsed int, rating Rating) (float64, float64) {
+	retrievability := s.forgettingCurve(float64(elapsed), card.Stability)
+	stability := s.nextRecallStability(card.Difficulty, card.Stability, retrievability, rating)
+	if rating == Again {
+		w17, w18 := 0.0, 0.0
+		if s.params.EnableShortTerm {
+			w17, w18 = s.params.Weights[17], s.params.Weights[18]
+		}
+		minNext := card.Stability / math.Exp(w17*w18)
+		stability = min(max(minNext, minStability), s.nextForgetStability(card.Difficulty, card.Stability, retrievability))
+	}
+	if elapsed == 0 && s.params.EnableShortTerm {
+		stability = s.nextShortTermStability(card.Stability, rating)
+	}
+	return s.nextDifficulty(card.Difficulty, rating), stability
+}
+
+/*
+learningStep returns how long until the next learning/relearning step for a rating,
+and the next step index. ok is false if the card should graduate to Review instead.
+Cards in Review only have a step for Again (relearning).
+*/
+func (s *Scheduler) learningStep(state State, currentStep int, rating Rating) (time.Duration, int, bool) {
+	steps := s.params.LearningSteps
+	if state == Review || state == Relearning {
+		steps = s.params.RelearningSteps
+	}
+	if len(steps) == 0 || currentStep >= len(steps) {
+		return 0, 0, false
+	}
+	switch rating {
+	case Again:
+		return steps[0], 0, true
+	case Hard:
+		if state == Review {
+			return 0, 0, false
+		}
+		if len(steps) == 1 {
+			return (steps[0] * 3 / 2).Round(time.Minute), currentStep, true
+		}
+		return ((steps[0] + steps[1]) / 2).Round(time.Minute), currentStep, true
+	case Good:
+		if state == Review || currentStep+1 >= len(steps) {
+			return 0, 0, false
+		}
+		return steps[currentStep+1], currentStep + 1, true
+	}
+	return 0, 0, false
+}
+
+/*
+applyLearningSteps schedules next for its next learning step (staying in toState),
+or graduates it to Review if there isn't one
+*/
+func (s *Scheduler) applyLearningSteps(next *Card, currentState State, currentStep int, rating Rating, toState State, now time.Time) {
+	step, nextStep, ok := s.learningStep(currentState, currentStep, rating)
+	if ok && step > 0 && step < 24*time.Hour {
+		next.State = toState
+		next.LearningSteps = nextStep
+		next.ScheduledDays = 0
+		next.Due = now.Add(step)
+		return
+	}
+	next.State = Review
+	if ok && step >= 24*time.Hour {
+		next.LearningSteps = nextStep
+		next.ScheduledDays = int(step.Hours() / 24)
+		next.Due = now.Add(step)
+		return
+	}
+	next.LearningSteps = 0
+	next.ScheduledDays = s.nextInterval(next.Stability)
+	next.Due = now.AddDate(0, 0, next.ScheduledDays)
+}
+
+/*
+Review returns the card after reviewing it with rating at now,
+and a log of the card before the review
+*/
+func (s *Scheduler) Review(card Card, rating Rating, now time.Time) (Card, ReviewLog, error) {
+	if rating < Again || rating > Easy {
+		return Card{}, ReviewLog{}, fmt.Errorf("invalid fsrs rating %d", rating)
+	}
+	if card.State == "" {
+		card.State = New
+	}
+	if card.LastReview != nil && now.Before(*card.LastReview) {
+		return Card{}, ReviewLog{}, errors.New("review is before the card's last review")
+	}
+
+	reviewLog := ReviewLog{
+		Rating:        rating,
+		State:         card.State,
+		Due:           card.Due,
+		Stability:     card.Stability,
+		Difficulty:    card.Difficulty,
+		LearningSteps: card.LearningSteps,
+		ScheduledDays: card.ScheduledDays,
+		Review:        now,
+	}
+
+	next := card
+	lastReview := now
+	next.LastReview = &lastReview
+	next.Reps++
+
+	switch card.State {
+	case New:
+		next.Difficulty = clamp(s.initDifficulty(rating), minDifficulty, maxDifficulty)
+		next.Stability = s.initStability(rating)
+		s.applyLearningSteps(&next, New, 0, rating, Learning, now)
+
+	case Learning, Relearning:
+		elapsed := 0
+		if card.LastReview != nil {
+			elapsed = elapsedDays(*card.LastReview, now)
+		}
+		next.Difficulty, next.Stability = s.nextMemoryState(card, elapsed, rating)
+		s.applyLearningSteps(&next, card.State, card.LearningSteps, rating, card.State, now)
+
+	case Review:
+		elapsed := 0
+		if card.LastReview != nil {
+			elapsed = elapsedDays(*card.LastReview, now)
+		}
+		if rating == Again {
+			next.Difficulty, next.Stability = s.nextMemoryState(card, elapsed, Again)
+			next.Lapses++
+			s.applyLearningSteps(&next, Review, card.LearningSteps, Again, Relearning, now)
+			break
+		}
+
+		/* intervals are computed for every rating, so Hard <= Good < Easy */
+		var difficulties, stabilities [5]float64
+		for _, r := range []Rating{Hard, Good, Easy} {
+			difficulties[r], stabilities[r] = s.nextMemoryState(card, elapsed, r)
+		}
+		hardInterval := s.nextInterval(stabilities[Hard])
+		goodInterval := s.nextInterval(stabilities[Good])
+		hardInterval = min(hardInterval, goodInterval)
+		goodInterval = max(goodInterval, hardInterval+1)
+		easyInterval := max(s.nextInterval(stabilities[Easy]), goodInterval+1)
+		intervals := map[Rating]int{Hard: hardInterval, Good: goodInterval, Easy: easyInterval}
+
+		next.Difficulty, next.Stability = difficulties[rating], stabilities[rating]
+		next.State = Review
+		next.LearningSteps = 0
+		next.ScheduledDays = intervals[rating]
+		next.Due = now.AddDate(0, 0, next.ScheduledDays)
+
+	default:
+		return Card{}, ReviewLog{}, fmt.Errorf("invalid fsrs card state %q", card.State)
+	}
+
+	return next, reviewLog, nil
+}
+
+func clamp(x float64, lo float64, hi float64) float64 {
+	return min(max(x, lo), hi)
+}
@@ -0,0 +1,179 @@
+package fsrs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	s, err := NewScheduler(DefaultParameters())
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewCard(t *testing.T) {
+	s := newTestScheduler(t)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		rating        Rating
+		state         State
+		due           time.Time
+		learningSteps int
+		scheduledDays int
+	}{
+		{Again, Learning, now.Add(time.Minute), 0, 0},
+		/* halfway between the 1m and 10m steps, rounded */
+		{Hard, Learning, now.Add(6 * time.Minute), 0, 0},
+		{Good, Learning, now.Add(10 * time.Minute), 1, 0},
+		/* Easy skips learning steps, initial stability w3 is ~8.3 days */
+		{Easy, Review, now.AddDate(0, 0, 8), 0, 8},
+	}
+	for _, c := range cases {
+		card, reviewLog, err := s.Review(NewCard(now), c.rating, now)
+		require.NoError(t, err, c.rating)
+		require.Equal(t, c.state, card.State, c.rating)
+		require.Equal(t, c.due, card.Due, c.rating)
+		require.Equal(t, c.learningSteps, card.LearningSteps, c.rating)
+		require.Equal(t, c.scheduledDays, card.ScheduledDays, c.rating)
+		require.Equal(t, 1, card.Reps, c.rating)
+		require.Equal(t, now, *card.LastReview, c.rating)
+		require.InDelta(t, DefaultWeights[c.rating-1], card.Stability, 1e-9, c.rating)
+		require.GreaterOrEqual(t, card.Difficulty, 1.0, c.rating)
+		require.LessOrEqual(t, card.Difficulty, 10.0, c.rating)
+
+		/* review logs have the card's state before the review */
+		require.Equal(t, New, reviewLog.State)
+		require.Equal(t, c.rating, reviewLog.Rating)
+		require.Equal(t, now, reviewLog.Review)
+	}
+
+	again, _, _ := s.Review(NewCard(now), Again, now)
+	easy, _, _ := s.Review(NewCard(now), Easy, now)
+	require.Greater(t, again.Difficulty, easy.Difficulty)
+}
+
+func TestLearningToReview(t *testing.T) {
+	s := newTestScheduler(t)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	card, _, err := s.Review(NewCard(now), Good, now)
+	require.NoError(t, err)
+	require.Equal(t, Learning, card.State)
+
+	/* Good on the last learning step graduates the card */
+	now = card.Due
+	card, _, err = s.Review(card, Good, now)
+	require.NoError(t, err)
+	require.Equal(t, Review, card.State)
+	require.Equal(t, 0, card.LearningSteps)
+	require.GreaterOrEqual(t, card.ScheduledDays, 1)
+	require.Equal(t, now.AddDate(0, 0, card.ScheduledDays), card.Due)
+
+	/* reviewing on time with Good increases stability & the interval */
+	previous := card
+	now = card.Due
+	card, reviewLog, err := s.Review(card, Good, now)
+	require.NoError(t, err)
+	require.Equal(t, Review, card.State)
+	require.Greater(t, card.Stability, previous.Stability)
+	require.Greater(t, card.ScheduledDays, previous.ScheduledDays)
+	require.Equal(t, Review, reviewLog.State)
+	require.Equal(t, previous.Stability, reviewLog.Stability)
+	require.Equal(t, previous.Due, reviewLog.Due)
+	require.Equal(t, 3, card.Reps)
+}
+
+func TestReviewIntervalsOrdered(t *testing.T) {
+	s := newTestScheduler(t)
+	lastReview := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	card := Card{
+		Difficulty:    5,
+		Stability:     10,
+		Due:           lastReview.AddDate(0, 0, 10),
+		LastReview:    &lastReview,
+		Reps:          4,
+		ScheduledDays: 10,
+		State:         Review,
+	}
+	now := card.Due
+
+	intervals := map[Rating]int{}
+	for _, rating := range []Rating{Hard, Good, Easy} {
+		next, _, err := s.Review(card, rating, now)
+		require.NoError(t, err)
+		require.Equal(t, Review, next.State)
+		intervals[rating] = next.ScheduledDays
+	}
+	require.LessOrEqual(t, intervals[Hard], intervals[Good])
+	require.Less(t, intervals[Good], intervals[Easy])
+
+	/* forgetting a review card starts relearning */
+	next, _, err := s.Review(card, Again, now)
+	require.NoError(t, err)
+	require.Equal(t, Relearning, next.State)
+	require.Equal(t, 1, next.Lapses)
+	require.Equal(t, now.Add(10*time.Minute), next.Due)
+	require.Less(t, next.Stability, card.Stability)
+	require.Greater(t, next.Difficulty, card.Difficulty)
+
+	/* Good on the only relearning step goes back to Review */
+	next, _, err = s.Review(next, Good, next.Due)
+	require.NoError(t, err)
+	require.Equal(t, Review, next.State)
+	require.GreaterOrEqual(t, next.ScheduledDays, 1)
+}
+
+func TestRetrievability(t *testing.T) {
+	s := newTestScheduler(t)
+	lastReview := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	card := Card{Stability: 10, Difficulty: 5, LastReview: &lastReview, State: Review}
+
+	require.InDelta(t, 1.0, s.Retrievability(card, lastReview), 1e-9)
+	/* stability is the number of days until retrievability drops to 90% */
+	require.InDelta(t, 0.9, s.Retrievability(card, lastReview.AddDate(0, 0, 10)), 1e-9)
+	require.Less(t, s.Retrievability(card, lastReview.AddDate(0, 0, 30)), 0.9)
+	require.Equal(t, 0.0, s.Retrievability(NewCard(lastReview), lastReview))
+}
+
+func TestRequestRetention(t *testing.T) {
+	params := DefaultParameters()
+	params.RequestRetention = 0.8
+	lenient, err := NewScheduler(params)
+	require.NoError(t, err)
+	strict := newTestScheduler(t)
+
+	/* lower retention means longer intervals */
+	require.Greater(t, lenient.nextInterval(10), strict.nextInterval(10))
+	require.Equal(t, 10, strict.nextInterval(10))
+	require.Equal(t, params.MaximumInterval, strict.nextInterval(math.MaxFloat64))
+}
+
+func TestReviewErrors(t *testing.T) {
+	s := newTestScheduler(t)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	_, _, err := s.Review(NewCard(now), Rating(0), now)
+	require.Error(t, err)
+
+	card, _, err := s.Review(NewCard(now), Good, now)
+	require.NoError(t, err)
+	_, _, err = s.Review(card, Good, now.Add(-time.Hour))
+	require.Error(t, err)
+
+	params := DefaultParameters()
+	params.Weights = params.Weights[:19]
+	_, err = NewScheduler(params)
+	require.Error(t, err)
+
+	rating, err := ParseRating("GOOD")
+	require.NoError(t, err)
+	require.Equal(t, Good, rating)
+	require.Equal(t, "GOOD", rating.String())
+	_, err = ParseRating("MANUAL")
+	require.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package fsrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+/* optimizing with fewer reviews (not counting same-day reviews) usually overfits */
+const MinOptimizationReviews = 400
+
+/* only the most recent reviews are used, so optimizing doesn't take too long */
+const maxOptimizationReviews = 50_000
+
+const (
+	optimizerIterations   = 150
+	optimizerLearningRate = 0.04
+	/* step for finite difference gradients */
+	optimizerGradientStep = 1e-4
+	/* every 5th card is held out to evaluate the fitted weights against the defaults */
+	optimizerHoldoutEvery = 5
+)
+
+var ErrNotEnoughReviews = errors.New("not enough reviews to optimize fsrs parameters")
+
+/* weightBounds keep fitted weights in ranges that make sense (same as the FSRS reference optimizer) */
+var weightBounds = [][2]float64{
+	{0.001, 100}, {0.001, 100}, {0.001, 100}, {0.001, 100},
+	{1, 10}, {0.001, 4}, {0.001, 4}, {0.001, 0.75},
+	{0, 4.5}, {0, 0.8}, {0.001, 3.5}, {0.001, 5},
+	{0.001, 0.25}, {0.001, 0.9}, {0, 4}, {0, 1},
+	{1, 6}, {0, 2}, {0, 2}, {0, 0.8},
+	{0.1, 0.8},
+}
+
+type ReviewEntry struct {
+	Rating Rating
+	Review time.Time
+}
+
+/* ReviewHistory is every review of one card, oldest first */
+type ReviewHistory []ReviewEntry
+
+type OptimizeResult struct {
+	Weights []float64
+	/* log loss of the fitted weights on held out cards */
+	LogLoss float64
+	/* log loss of the default weights on the same held out cards */
+	DefaultLogLoss float64
+	/* reviews used, not counting same-day reviews */
+	ReviewCount int
+}
+
+/* Better reports whether the fitted weights predict recall better than the defaults */
+func (result *OptimizeResult) Better() bool {
+	return result.LogLoss < result.DefaultLogLoss
+}
+
+/* trainingCard is a review history with elapsed days precomputed */
+type trainingCard struct {
+	ratings []Rating
+	elapsed []int
+}
+
+/*
+Optimize fits FSRS weights to review histories by minimizing log loss
+(how well the predicted probability of recall matches whether each review was recalled, rating above Again),
+with Adam and finite difference gradients, starting from the default weights.
+*/
+func Optimize(ctx context.Context, histories []ReviewHistory) (*OptimizeResult, error) {
+	var train, holdout []trainingCard
+	reviewCount := 0
+	for i, history := range histories {
+		if len(history) < 2 {
+			continue
+		}
+		card := trainingCard{
+			ratings: make([]Rating, len(history)),
+			elapsed: make([]int, len(history)),
+		}
+		for j, entry := range history {
+			card.ratings[j] = entry.Rating
+			if j > 0 {
+				card.elapsed[j] = elapsedDays(history[j-1].Review, entry.Review)
+				if card.elapsed[j] > 0 {
+					reviewCount++
+				}
+			}
+		}
+		if i%optimizerHoldoutEvery == optimizerHoldoutEvery-1 {
+			holdout = append(holdout, card)
+		} else {
+			train = append(train, card)
+		}
+		if reviewCount >= maxOptimizationReviews {
+			break
+		}
+	}
+	if reviewCount < MinOptimizationReviews {
+		return nil, fmt.Errorf("%w (need at least %d, have %d)", ErrNotEnoughReviews, MinOptimizationReviews, reviewCount)
+	}
+	if len(holdout) == 0 {
+		holdout = train
+	}
+
+	weights := append([]float64(nil), DefaultWeights...)
+	bestWeights := append([]float64(nil), weights...)
+	bestLoss := logLoss(weights, train)
+
+	/* Adam */
+	m := make([]float64, len(weights))
+	v := make([]float64, len(weights))
+	const beta1, beta2, epsilon = 0.9, 0.999, 1e-8
+	gradient := make([]float64, len(weights))
+	for iteration := 1; iteration <= optimizerIterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		loss := logLoss(weights, train)
+		if loss < bestLoss {
+			bestLoss = loss
+			copy(bestWeights, weights)
+		}
+		for i := range weights {
+			original := weights[i]
+			step := optimizerGradientStep
+			if original+step > weightBounds[i][1] {
+				step = -step
+			}
+			weights[i] = original + step
+			gradient[i] = (logLoss(weights, train) - loss) / step
+			weights[i] = original
+		}
+
+		/* cosine decay, so later iterations settle instead of bouncing around */
+		lr := optimizerLearningRate * (0.1 + 0.9*0.5*(1+math.Cos(math.Pi*float64(iteration)/optimizerIterations)))
+		for i := range weights {
+			m[i] = beta1*m[i] + (1-beta1)*gradient[i]
+			v[i] = beta2*v[i] + (1-beta2)*gradient[i]*gradient[i]
+			mHat := m[i] / (1 - math.Pow(beta1, float64(iteration)))
+			vHat := v[i] / (1 - math.Pow(beta2, float64(iteration)))
+			weights[i] = clamp(weights[i]-lr*mHat/(math.Sqrt(vHat)+epsilon), weightBounds[i][0], weightBounds[i][1])
+		}
+	}
+	if loss := logLoss(weights, train); loss < bestLoss {
+		copy(bestWeights, weights)
+	}
+
+	return &OptimizeResult{
+		Weights:        bestWeights,
+		LogLoss:        logLoss(bestWeights, holdout),
+		DefaultLogLoss: logLoss(DefaultWeights, holdout),
+		ReviewCount:    reviewCount,
+	}, nil
+}
+
+/*
+logLoss replays each card's reviews with weights,
+and returns the mean log loss of the predicted recall for every review after the first
+(same-day reviews update the memory state, but aren't predicted)
+*/
+func logLoss(weights []float64, cards []trainingCard) float64 {
+	decay := -weights[20]
+	s := &Scheduler{
+		params: Parameters{
+			Weights:         weights,
+			EnableShortTerm: true,
+		},
+		decay:  decay,
+		factor: math.Pow(0.9, 1/decay) - 1,
+	}
+
+	total := 0.0
+	count := 0
+	for _, card := range cards {
+		state := Card{
+			Difficulty: clamp(s.initDifficulty(card.ratings[0]), minDifficulty, maxDifficulty),
+			Stability:  s.initStability(card.ratings[0]),
+		}
+		for i := 1; i < len(card.ratings); i++ {
+			elapsed := card.elapsed[i]
+			if elapsed > 0 {
+				r := clamp(s.forgettingCurve(float64(elapsed), state.Stability), 1e-6, 1-1e-6)
+				if card.ratings[i] > Again {
+					total -= math.Log(r)
+				} else {
+					total -= math.Log(1 - r)
+				}
+				count++
+			}
+			state.Difficulty, state.Stability = s.nextMemoryState(state, elapsed, card.ratings[i])
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
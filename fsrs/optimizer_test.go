@@ -0,0 +1,81 @@
+package fsrs
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+/* simulateHistories makes review histories for someone who remembers like trueWeights predicts */
+func simulateHistories(t *testing.T, trueWeights []float64, cards int) []ReviewHistory {
+	t.Helper()
+	params := DefaultParameters()
+	params.Weights = trueWeights
+	s, err := NewScheduler(params)
+	require.NoError(t, err)
+
+	random := rand.New(rand.NewSource(1))
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	histories := make([]ReviewHistory, cards)
+	for i := range histories {
+		now := start
+		rating := Rating(random.Intn(4) + 1)
+		history := ReviewHistory{{Rating: rating, Review: now}}
+		card := Card{
+			Difficulty: clamp(s.initDifficulty(rating), minDifficulty, maxDifficulty),
+			Stability:  s.initStability(rating),
+		}
+		for range 8 {
+			elapsed := random.Intn(30) + 1
+			now = now.AddDate(0, 0, elapsed)
+			if random.Float64() < s.forgettingCurve(float64(elapsed), card.Stability) {
+				rating = Rating(random.Intn(3) + 2)
+			} else {
+				rating = Again
+			}
+			history = append(history, ReviewEntry{Rating: rating, Review: now})
+			card.Difficulty, card.Stability = s.nextMemoryState(card, elapsed, rating)
+		}
+		histories[i] = history
+	}
+	return histories
+}
+
+func TestOptimize(t *testing.T) {
+	/* someone who forgets much faster than the defaults expect */
+	trueWeights := append([]float64(nil), DefaultWeights...)
+	trueWeights[0], trueWeights[1], trueWeights[2], trueWeights[3] = 0.05, 0.2, 0.5, 1.5
+	trueWeights[8] = 0.8
+
+	result, err := Optimize(context.Background(), simulateHistories(t, trueWeights, 500))
+	require.NoError(t, err)
+	require.Equal(t, 500*8, result.ReviewCount)
+	require.Len(t, result.Weights, len(DefaultWeights))
+	require.True(t, result.Better(), "fitted %f, default %f", result.LogLoss, result.DefaultLogLoss)
+	for i, w := range result.Weights {
+		require.GreaterOrEqual(t, w, weightBounds[i][0], i)
+		require.LessOrEqual(t, w, weightBounds[i][1], i)
+	}
+
+	/* fitted weights can be used for scheduling */
+	params := DefaultParameters()
+	params.Weights = result.Weights
+	_, err = NewScheduler(params)
+	require.NoError(t, err)
+
+	/* DefaultWeights isn't changed */
+	require.Equal(t, 0.212, DefaultWeights[0])
+}
+
+func TestOptimizeErrors(t *testing.T) {
+	_, err := Optimize(context.Background(), simulateHistories(t, DefaultWeights, 10))
+	require.ErrorIs(t, err, ErrNotEnoughReviews)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Optimize(ctx, simulateHistories(t, DefaultWeights, 100))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"quizfreely/api/graph/model"
 	"strings"
+	"time"
 )
 
 // SavedStudysetRow is used for MySavedStudysets to include saved_at for cursor.
@@ -123,3 +124,46 @@ func DecodeFolderCursor(cursor string) string {
 	}
 	return s[7:]
 }
+
+// EncodeSyncCursor encodes the server time that a study sync pulled changes up to.
+func EncodeSyncCursor(t time.Time) string {
+	return base64.StdEncoding.EncodeToString([]byte("sync|" + t.UTC().Format(time.RFC3339Nano)))
+}
+
+// DecodeSyncCursor returns the time from a sync cursor, ok is false if it's invalid.
+func DecodeSyncCursor(cursor string) (t time.Time, ok bool) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, false
+	}
+	s := string(raw)
+	if !strings.HasPrefix(s, "sync|") {
+		return time.Time{}, false
+	}
+	t, err = time.Parse(time.RFC3339Nano, s[5:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// EncodeNotificationCursor encodes notification id for keyset pagination (order by created_at, id).
+func EncodeNotificationCursor(id string) string {
+	return base64.StdEncoding.EncodeToString([]byte("notification|" + id))
+}
+
+// DecodeNotificationCursor returns notification id, or "" if invalid.
+func DecodeNotificationCursor(cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return ""
+	}
+	s := string(raw)
+	if !strings.HasPrefix(s, "notification|") {
+		return ""
+	}
+	return s[len("notification|"):]
+}
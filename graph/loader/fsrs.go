@@ -25,7 +25,10 @@ func (dr *dataReader) getFSRSCardsByTermIDs(ctx context.Context, termIDs []strin
     fc.reps,
     fc.scheduled_days,
     fc.stability,
-    fc.state
+    fc.state,
+    fc.suspended,
+	to_char(fc.buried_until, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as buried_until,
+    fc.leech
 FROM unnest($1::uuid[]) WITH ORDINALITY AS input(term_id, og_order)
 LEFT JOIN fsrs_cards fc
 	ON fc.term_id = input.term_id
@@ -50,6 +53,9 @@ ORDER BY input.og_order`,
 		ScheduledDays *int32           `db:"scheduled_days"`
 		Stability     *float64         `db:"stability"`
 		State         *model.FSRSState `db:"state"`
+		Suspended     *bool            `db:"suspended"`
+		BuriedUntil   *string          `db:"buried_until"`
+		Leech         *bool            `db:"leech"`
 	}
 
 	var fsrsCards []*model.FSRSCard
@@ -73,6 +79,9 @@ ORDER BY input.og_order`,
 				ScheduledDays: *fc.ScheduledDays,
 				Stability:     *fc.Stability,
 				State:         *fc.State,
+				Suspended:     *fc.Suspended,
+				BuriedUntil:   fc.BuriedUntil,
+				Leech:         *fc.Leech,
 			}
 			fsrsCards = append(fsrsCards, modelFc)
 		}
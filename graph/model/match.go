@@ -9,3 +9,14 @@ type MatchActivity struct {
 }
 
 func (MatchActivity) IsReviewActivity() {}
+
+type MatchLeaderboardEntry struct {
+	Rank            int32  `json:"rank" db:"rank"`
+	UserID          string `json:"userId" db:"user_id"`
+	User            *User  `json:"user,omitempty"`
+	MatchActivityID string `json:"matchActivityId" db:"match_activity_id"`
+	DurationMs      int32  `json:"durationMs" db:"duration_ms"`
+	IncorrectPairs  int32  `json:"incorrectPairs" db:"incorrect_pairs"`
+	ScoreMs         int32  `json:"scoreMs" db:"score_ms"`
+	EndTimestamp    string `json:"endTimestamp" db:"end_timestamp"`
+}
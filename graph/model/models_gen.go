@@ -13,18 +13,77 @@ type ReviewActivity interface {
 	IsReviewActivity()
 }
 
+type Achievement struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Earned      bool    `json:"earned"`
+	EarnedAt    *string `json:"earnedAt,omitempty"`
+}
+
+type ActivityCalendar struct {
+	Days   []*ActivityPeriod `json:"days"`
+	Weeks  []*ActivityPeriod `json:"weeks"`
+	Months []*ActivityPeriod `json:"months"`
+}
+
+type ActivityPeriod struct {
+	Date       string `json:"date"`
+	Reviews    int32  `json:"reviews"`
+	Minutes    int32  `json:"minutes"`
+	Studysets  int32  `json:"studysets"`
+	Terms      int32  `json:"terms"`
+	ActiveDays int32  `json:"activeDays"`
+}
+
+type AnswerWithScore struct {
+	AnswerWith       AnswerWith `json:"answerWith"`
+	QuestionsCorrect int32      `json:"questionsCorrect"`
+	QuestionsTotal   int32      `json:"questionsTotal"`
+	Score            float64    `json:"score"`
+}
+
+type DailyGoal struct {
+	Type   DailyGoalType `json:"type"`
+	Target int32         `json:"target"`
+}
+
+type DailyGoalProgress struct {
+	Date     string     `json:"date"`
+	Goal     *DailyGoal `json:"goal"`
+	Reviews  int32      `json:"reviews"`
+	Minutes  int32      `json:"minutes"`
+	Progress float64    `json:"progress"`
+	Met      bool       `json:"met"`
+}
+
+type DueCards struct {
+	Terms         []*Term `json:"terms"`
+	DueCount      int32   `json:"dueCount"`
+	LearningCount int32   `json:"learningCount"`
+	NewCount      int32   `json:"newCount"`
+}
+
 type Frq struct {
 	Term              *TermAtp   `json:"term"`
 	AnswerWith        AnswerWith `json:"answerWith"`
 	Correct           bool       `json:"correct"`
 	UserMarkedCorrect *bool      `json:"userMarkedCorrect,omitempty"`
 	AnsweredString    *string    `json:"answeredString,omitempty"`
+	Grade             *FRQGrade  `json:"grade,omitempty"`
+}
+
+type FRQGrade struct {
+	Correct       bool           `json:"correct"`
+	Score         float64        `json:"score"`
+	Reason        FRQGradeReason `json:"reason"`
+	MatchedAnswer string         `json:"matchedAnswer"`
 }
 
 type FRQInput struct {
 	Term              *TermATPInput `json:"term"`
 	AnswerWith        AnswerWith    `json:"answerWith"`
-	Correct           bool          `json:"correct"`
+	Correct           *bool         `json:"correct,omitempty"`
 	UserMarkedCorrect *bool         `json:"userMarkedCorrect,omitempty"`
 	AnsweredString    *string       `json:"answeredString,omitempty"`
 }
@@ -39,6 +98,9 @@ type FSRSCard struct {
 	ScheduledDays int32     `json:"scheduledDays"`
 	Stability     float64   `json:"stability"`
 	State         FSRSState `json:"state"`
+	Suspended     bool      `json:"suspended"`
+	BuriedUntil   *string   `json:"buriedUntil,omitempty"`
+	Leech         bool      `json:"leech"`
 }
 
 type FSRSCardInput struct {
@@ -53,6 +115,18 @@ type FSRSCardInput struct {
 	State         FSRSState `json:"state"`
 }
 
+type FSRSParameters struct {
+	Weights        []float64               `json:"weights"`
+	UsingDefaults  bool                    `json:"usingDefaults"`
+	LogLoss        *float64                `json:"logLoss,omitempty"`
+	DefaultLogLoss *float64                `json:"defaultLogLoss,omitempty"`
+	ReviewCount    int32                   `json:"reviewCount"`
+	Status         *FSRSOptimizationStatus `json:"status,omitempty"`
+	Error          *string                 `json:"error,omitempty"`
+	RequestedAt    *string                 `json:"requestedAt,omitempty"`
+	OptimizedAt    *string                 `json:"optimizedAt,omitempty"`
+}
+
 type FSRSReviewLog struct {
 	ID            string     `json:"id"`
 	Difficulty    float64    `json:"difficulty"`
@@ -87,6 +161,86 @@ type FolderEdge struct {
 	Cursor string  `json:"cursor"`
 }
 
+type GeneratedFrq struct {
+	Term       *TermAtp   `json:"term"`
+	AnswerWith AnswerWith `json:"answerWith"`
+}
+
+type GeneratedMcq struct {
+	Term               *TermAtp   `json:"term"`
+	AnswerWith         AnswerWith `json:"answerWith"`
+	CorrectChoiceIndex int32      `json:"correctChoiceIndex"`
+	Distractors        []*TermAtp `json:"distractors"`
+}
+
+type GeneratedPracticeTest struct {
+	ID          string               `json:"id"`
+	StudysetIds []string             `json:"studysetIds"`
+	ExpiresAt   string               `json:"expiresAt"`
+	Questions   []*GeneratedQuestion `json:"questions"`
+}
+
+type GeneratedQuestion struct {
+	ID  string        `json:"id"`
+	Mcq *GeneratedMcq `json:"mcq,omitempty"`
+	Tfq *GeneratedTfq `json:"tfq,omitempty"`
+	Frq *GeneratedFrq `json:"frq,omitempty"`
+}
+
+type GeneratedTfq struct {
+	Term       *TermAtp   `json:"term"`
+	AnswerWith AnswerWith `json:"answerWith"`
+	Distractor *TermAtp   `json:"distractor,omitempty"`
+}
+
+type IntervalRetention struct {
+	MinDays   int32    `json:"minDays"`
+	MaxDays   *int32   `json:"maxDays,omitempty"`
+	Reviews   int32    `json:"reviews"`
+	Recalled  int32    `json:"recalled"`
+	Retention *float64 `json:"retention,omitempty"`
+}
+
+type LearnAnswerInput struct {
+	AnsweredIndex  *int32  `json:"answeredIndex,omitempty"`
+	AnsweredString *string `json:"answeredString,omitempty"`
+}
+
+type LearnAnswerResult struct {
+	Correct            bool          `json:"correct"`
+	CorrectChoiceIndex *int32        `json:"correctChoiceIndex,omitempty"`
+	Grade              *FRQGrade     `json:"grade,omitempty"`
+	Stage              LearnStage    `json:"stage"`
+	FsrsCard           *FSRSCard     `json:"fsrsCard,omitempty"`
+	Session            *LearnSession `json:"session"`
+}
+
+type LearnProgress struct {
+	Total          int32 `json:"total"`
+	MultipleChoice int32 `json:"multipleChoice"`
+	Written        int32 `json:"written"`
+	Learned        int32 `json:"learned"`
+}
+
+type LearnSession struct {
+	ID          string               `json:"id"`
+	StudysetIds []string             `json:"studysetIds"`
+	AnswerWith  *AnswerWith          `json:"answerWith,omitempty"`
+	RoundSize   int32                `json:"roundSize"`
+	Round       int32                `json:"round"`
+	Completed   bool                 `json:"completed"`
+	CreatedAt   string               `json:"createdAt"`
+	UpdatedAt   string               `json:"updatedAt"`
+	CompletedAt *string              `json:"completedAt,omitempty"`
+	Progress    *LearnProgress       `json:"progress"`
+	Questions   []*GeneratedQuestion `json:"questions"`
+}
+
+type LearnSessionOptions struct {
+	RoundSize  *int32      `json:"roundSize,omitempty"`
+	AnswerWith *AnswerWith `json:"answerWith,omitempty"`
+}
+
 type Mcq struct {
 	Term               *TermAtp   `json:"term"`
 	AnswerWith         AnswerWith `json:"answerWith"`
@@ -107,6 +261,7 @@ type MCQInput struct {
 
 type MatchActivityInput struct {
 	DurationMs       int32      `json:"durationMs"`
+	EndTimestamp     *string    `json:"endTimestamp,omitempty"`
 	TermIds          []string   `json:"termIds"`
 	IncorrectPairIds [][]string `json:"incorrectPairIds"`
 }
@@ -115,9 +270,31 @@ type Mutation struct {
 }
 
 type NewTermInput struct {
-	Term      *string `json:"term,omitempty"`
-	Def       *string `json:"def,omitempty"`
-	SortOrder int32   `json:"sortOrder"`
+	Term         *string `json:"term,omitempty"`
+	Def          *string `json:"def,omitempty"`
+	TermImageKey *string `json:"termImageKey,omitempty"`
+	DefImageKey  *string `json:"defImageKey,omitempty"`
+	SortOrder    int32   `json:"sortOrder"`
+}
+
+type NotificationConnection struct {
+	Edges    []*NotificationEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type NotificationEdge struct {
+	Node   *Notification `json:"node"`
+	Cursor string        `json:"cursor"`
+}
+
+type NotificationPreference struct {
+	Type    NotificationType `json:"type"`
+	Enabled bool             `json:"enabled"`
+}
+
+type NotificationPreferenceInput struct {
+	Type    NotificationType `json:"type"`
+	Enabled bool             `json:"enabled"`
 }
 
 type PageInfo struct {
@@ -128,8 +305,28 @@ type PageInfo struct {
 }
 
 type PracticeTestInput struct {
-	Timestamp *string          `json:"timestamp,omitempty"`
-	Questions []*QuestionInput `json:"questions"`
+	Timestamp               *string          `json:"timestamp,omitempty"`
+	GeneratedPracticeTestID *string          `json:"generatedPracticeTestId,omitempty"`
+	Questions               []*QuestionInput `json:"questions"`
+}
+
+type PracticeTestScore struct {
+	QuestionsCorrect int32                `json:"questionsCorrect"`
+	QuestionsTotal   int32                `json:"questionsTotal"`
+	Score            float64              `json:"score"`
+	ByQuestionType   []*QuestionTypeScore `json:"byQuestionType"`
+	ByAnswerWith     []*AnswerWithScore   `json:"byAnswerWith"`
+}
+
+type PracticeTestTrend struct {
+	Tests   []*PracticeTestTrendPoint `json:"tests"`
+	Overall *PracticeTestScore        `json:"overall"`
+}
+
+type PracticeTestTrendPoint struct {
+	PracticeTestID string             `json:"practiceTestId"`
+	Timestamp      string             `json:"timestamp"`
+	Score          *PracticeTestScore `json:"score"`
 }
 
 type Query struct {
@@ -148,12 +345,112 @@ type QuestionInput struct {
 	Frq *FRQInput `json:"frq,omitempty"`
 }
 
+type QuestionTypeScore struct {
+	QuestionType     QuestionType `json:"questionType"`
+	QuestionsCorrect int32        `json:"questionsCorrect"`
+	QuestionsTotal   int32        `json:"questionsTotal"`
+	Score            float64      `json:"score"`
+}
+
+type RejectedSyncItem struct {
+	Type  SyncItemType `json:"type"`
+	Index int32        `json:"index"`
+	Error string       `json:"error"`
+}
+
+type ReminderSettings struct {
+	Enabled  bool            `json:"enabled"`
+	Time     string          `json:"time"`
+	Timezone string          `json:"timezone"`
+	Channel  ReminderChannel `json:"channel"`
+	// the user's google sign in email, email reminders are only sent to verified emails
+	Email      *string `json:"email,omitempty"`
+	WebhookURL *string `json:"webhookUrl,omitempty"`
+	LastSentAt *string `json:"lastSentAt,omitempty"`
+}
+
+type ReminderSettingsInput struct {
+	Enabled    *bool            `json:"enabled,omitempty"`
+	Time       *string          `json:"time,omitempty"`
+	Timezone   *string          `json:"timezone,omitempty"`
+	Channel    *ReminderChannel `json:"channel,omitempty"`
+	WebhookURL *string          `json:"webhookUrl,omitempty"`
+}
+
+type RetentionStats struct {
+	Reviews    int32                `json:"reviews"`
+	Recalled   int32                `json:"recalled"`
+	Retention  *float64             `json:"retention,omitempty"`
+	ByState    []*StateRetention    `json:"byState"`
+	ByInterval []*IntervalRetention `json:"byInterval"`
+}
+
 type ReviewEventStats struct {
 	Timestamp string `json:"timestamp"`
 	Correct   int32  `json:"correct"`
 	Incorrect int32  `json:"incorrect"`
 }
 
+type ReviewForecastDay struct {
+	Date             string `json:"date"`
+	DueCount         int32  `json:"dueCount"`
+	ProjectedReviews int32  `json:"projectedReviews"`
+}
+
+type StateRetention struct {
+	State     FSRSState `json:"state"`
+	Reviews   int32     `json:"reviews"`
+	Recalled  int32     `json:"recalled"`
+	Retention *float64  `json:"retention,omitempty"`
+}
+
+type Streak struct {
+	Current      int32              `json:"current"`
+	Longest      int32              `json:"longest"`
+	StudiedToday bool               `json:"studiedToday"`
+	Freezes      int32              `json:"freezes"`
+	MaxFreezes   int32              `json:"maxFreezes"`
+	FrozenDays   []string           `json:"frozenDays"`
+	Today        *DailyGoalProgress `json:"today"`
+}
+
+type StudySettings struct {
+	DesiredRetention       float64 `json:"desiredRetention"`
+	MaxNewCardsPerDay      int32   `json:"maxNewCardsPerDay"`
+	MaxReviewsPerDay       int32   `json:"maxReviewsPerDay"`
+	LearningStepsMinutes   []int32 `json:"learningStepsMinutes"`
+	RelearningStepsMinutes []int32 `json:"relearningStepsMinutes"`
+	DayRolloverHour        int32   `json:"dayRolloverHour"`
+	LeechThreshold         int32   `json:"leechThreshold"`
+}
+
+type StudySettingsInput struct {
+	DesiredRetention       *float64 `json:"desiredRetention,omitempty"`
+	MaxNewCardsPerDay      *int32   `json:"maxNewCardsPerDay,omitempty"`
+	MaxReviewsPerDay       *int32   `json:"maxReviewsPerDay,omitempty"`
+	LearningStepsMinutes   []int32  `json:"learningStepsMinutes,omitempty"`
+	RelearningStepsMinutes []int32  `json:"relearningStepsMinutes,omitempty"`
+	DayRolloverHour        *int32   `json:"dayRolloverHour,omitempty"`
+	LeechThreshold         *int32   `json:"leechThreshold,omitempty"`
+}
+
+type StudySyncInput struct {
+	Cursor          *string                   `json:"cursor,omitempty"`
+	Reviews         []*SyncReviewInput        `json:"reviews,omitempty"`
+	TermProgress    []*SyncTermProgressInput  `json:"termProgress,omitempty"`
+	PracticeTests   []*SyncPracticeTestInput  `json:"practiceTests,omitempty"`
+	MatchActivities []*SyncMatchActivityInput `json:"matchActivities,omitempty"`
+}
+
+type StudySyncResult struct {
+	Cursor          string                `json:"cursor"`
+	Cards           []*SyncedFSRSCard     `json:"cards"`
+	TermProgress    []*SyncedTermProgress `json:"termProgress"`
+	PracticeTests   []*PracticeTest       `json:"practiceTests"`
+	MatchActivities []*MatchActivity      `json:"matchActivities"`
+	Rejected        []*RejectedSyncItem   `json:"rejected"`
+}
+
 type StudysetConnection struct {
 	Edges    []*StudysetEdge `json:"edges"`
 	PageInfo *PageInfo       `json:"pageInfo"`
@@ -170,6 +467,59 @@ type StudysetInput struct {
 	SubjectID *string `json:"subjectId,omitempty"`
 }
 
+type StudysetStudySettings struct {
+	StudysetID             string   `json:"studysetId"`
+	DesiredRetention       *float64 `json:"desiredRetention,omitempty"`
+	MaxNewCardsPerDay      *int32   `json:"maxNewCardsPerDay,omitempty"`
+	MaxReviewsPerDay       *int32   `json:"maxReviewsPerDay,omitempty"`
+	LearningStepsMinutes   []int32  `json:"learningStepsMinutes,omitempty"`
+	RelearningStepsMinutes []int32  `json:"relearningStepsMinutes,omitempty"`
+	LeechThreshold         *int32   `json:"leechThreshold,omitempty"`
+}
+
+type StudysetStudySettingsInput struct {
+	DesiredRetention       *float64 `json:"desiredRetention,omitempty"`
+	MaxNewCardsPerDay      *int32   `json:"maxNewCardsPerDay,omitempty"`
+	MaxReviewsPerDay       *int32   `json:"maxReviewsPerDay,omitempty"`
+	LearningStepsMinutes   []int32  `json:"learningStepsMinutes,omitempty"`
+	RelearningStepsMinutes []int32  `json:"relearningStepsMinutes,omitempty"`
+	LeechThreshold         *int32   `json:"leechThreshold,omitempty"`
+}
+
+type SyncMatchActivityInput struct {
+	ClientTimestamp string              `json:"clientTimestamp"`
+	IdempotencyKey  *string             `json:"idempotencyKey,omitempty"`
+	MatchActivity   *MatchActivityInput `json:"matchActivity"`
+}
+
+type SyncPracticeTestInput struct {
+	ClientTimestamp string             `json:"clientTimestamp"`
+	IdempotencyKey  *string            `json:"idempotencyKey,omitempty"`
+	PracticeTest    *PracticeTestInput `json:"practiceTest"`
+}
+
+type SyncReviewInput struct {
+	TermID          string     `json:"termId"`
+	Rating          FSRSRating `json:"rating"`
+	ClientTimestamp string     `json:"clientTimestamp"`
+}
+
+type SyncTermProgressInput struct {
+	ClientTimestamp string               `json:"clientTimestamp"`
+	IdempotencyKey  *string              `json:"idempotencyKey,omitempty"`
+	TermProgress    []*TermProgressInput `json:"termProgress"`
+}
+
+type SyncedFSRSCard struct {
+	TermID string    `json:"termId"`
+	Card   *FSRSCard `json:"card"`
+}
+
+type SyncedTermProgress struct {
+	TermID   string        `json:"termId"`
+	Progress *TermProgress `json:"progress"`
+}
+
 type Tfq struct {
 	Term         *TermAtp   `json:"term"`
 	AnswerWith   AnswerWith `json:"answerWith"`
@@ -205,6 +555,30 @@ type TermInput struct {
 	SortOrder *int32  `json:"sortOrder,omitempty"`
 }
 
+type TermInsight struct {
+	Term              *Term    `json:"term"`
+	EnoughData        bool     `json:"enoughData"`
+	Users             *int32   `json:"users,omitempty"`
+	Attempts          *int32   `json:"attempts,omitempty"`
+	ErrorRate         *float64 `json:"errorRate,omitempty"`
+	CommonWrongTerm   *Term    `json:"commonWrongTerm,omitempty"`
+	CommonWrongAnswer *string  `json:"commonWrongAnswer,omitempty"`
+}
+
+type TermInsights struct {
+	RefreshedAt *string        `json:"refreshedAt,omitempty"`
+	MinUsers    int32          `json:"minUsers"`
+	Terms       []*TermInsight `json:"terms"`
+}
+
+type TermMastery struct {
+	Term           *Term        `json:"term"`
+	Level          MasteryLevel `json:"level"`
+	Score          float64      `json:"score"`
+	RecentAccuracy *float64     `json:"recentAccuracy,omitempty"`
+	LastReviewedAt *string      `json:"lastReviewedAt,omitempty"`
+}
+
 type TermProgressInput struct {
 	TermID                string  `json:"termId"`
 	TermReviewedAt        *string `json:"termReviewedAt,omitempty"`
@@ -325,6 +699,181 @@ func (e AuthType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type DailyGoalType string
+
+const (
+	DailyGoalTypeReviews DailyGoalType = "REVIEWS"
+	DailyGoalTypeMinutes DailyGoalType = "MINUTES"
+)
+
+var AllDailyGoalType = []DailyGoalType{
+	DailyGoalTypeReviews,
+	DailyGoalTypeMinutes,
+}
+
+func (e DailyGoalType) IsValid() bool {
+	switch e {
+	case DailyGoalTypeReviews, DailyGoalTypeMinutes:
+		return true
+	}
+	return false
+}
+
+func (e DailyGoalType) String() string {
+	return string(e)
+}
+
+func (e *DailyGoalType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DailyGoalType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DailyGoalType", str)
+	}
+	return nil
+}
+
+func (e DailyGoalType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DailyGoalType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DailyGoalType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FRQGradeReason string
+
+const (
+	FRQGradeReasonExact      FRQGradeReason = "EXACT"
+	FRQGradeReasonNormalized FRQGradeReason = "NORMALIZED"
+	FRQGradeReasonTypo       FRQGradeReason = "TYPO"
+	FRQGradeReasonIncorrect  FRQGradeReason = "INCORRECT"
+	FRQGradeReasonEmpty      FRQGradeReason = "EMPTY"
+)
+
+var AllFRQGradeReason = []FRQGradeReason{
+	FRQGradeReasonExact,
+	FRQGradeReasonNormalized,
+	FRQGradeReasonTypo,
+	FRQGradeReasonIncorrect,
+	FRQGradeReasonEmpty,
+}
+
+func (e FRQGradeReason) IsValid() bool {
+	switch e {
+	case FRQGradeReasonExact, FRQGradeReasonNormalized, FRQGradeReasonTypo, FRQGradeReasonIncorrect, FRQGradeReasonEmpty:
+		return true
+	}
+	return false
+}
+
+func (e FRQGradeReason) String() string {
+	return string(e)
+}
+
+func (e *FRQGradeReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FRQGradeReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FRQGradeReason", str)
+	}
+	return nil
+}
+
+func (e FRQGradeReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FRQGradeReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FRQGradeReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FSRSOptimizationStatus string
+
+const (
+	FSRSOptimizationStatusPending   FSRSOptimizationStatus = "PENDING"
+	FSRSOptimizationStatusRunning   FSRSOptimizationStatus = "RUNNING"
+	FSRSOptimizationStatusSucceeded FSRSOptimizationStatus = "SUCCEEDED"
+	FSRSOptimizationStatusFailed    FSRSOptimizationStatus = "FAILED"
+)
+
+var AllFSRSOptimizationStatus = []FSRSOptimizationStatus{
+	FSRSOptimizationStatusPending,
+	FSRSOptimizationStatusRunning,
+	FSRSOptimizationStatusSucceeded,
+	FSRSOptimizationStatusFailed,
+}
+
+func (e FSRSOptimizationStatus) IsValid() bool {
+	switch e {
+	case FSRSOptimizationStatusPending, FSRSOptimizationStatusRunning, FSRSOptimizationStatusSucceeded, FSRSOptimizationStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e FSRSOptimizationStatus) String() string {
+	return string(e)
+}
+
+func (e *FSRSOptimizationStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FSRSOptimizationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FSRSOptimizationStatus", str)
+	}
+	return nil
+}
+
+func (e FSRSOptimizationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FSRSOptimizationStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FSRSOptimizationStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type FSRSRating string
 
 const (
@@ -445,54 +994,50 @@ func (e FSRSState) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-type SubjectCategory string
+type LearnStage string
 
 const (
-	SubjectCategoryLang          SubjectCategory = "LANG"
-	SubjectCategoryStem          SubjectCategory = "STEM"
-	SubjectCategorySocialStudies SubjectCategory = "SOCIAL_STUDIES"
-	SubjectCategoryLa            SubjectCategory = "LA"
-	SubjectCategoryMath          SubjectCategory = "MATH"
+	LearnStageMultipleChoice LearnStage = "MULTIPLE_CHOICE"
+	LearnStageWritten        LearnStage = "WRITTEN"
+	LearnStageLearned        LearnStage = "LEARNED"
 )
 
-var AllSubjectCategory = []SubjectCategory{
-	SubjectCategoryLang,
-	SubjectCategoryStem,
-	SubjectCategorySocialStudies,
-	SubjectCategoryLa,
-	SubjectCategoryMath,
+var AllLearnStage = []LearnStage{
+	LearnStageMultipleChoice,
+	LearnStageWritten,
+	LearnStageLearned,
 }
 
-func (e SubjectCategory) IsValid() bool {
+func (e LearnStage) IsValid() bool {
 	switch e {
-	case SubjectCategoryLang, SubjectCategoryStem, SubjectCategorySocialStudies, SubjectCategoryLa, SubjectCategoryMath:
+	case LearnStageMultipleChoice, LearnStageWritten, LearnStageLearned:
 		return true
 	}
 	return false
 }
 
-func (e SubjectCategory) String() string {
+func (e LearnStage) String() string {
 	return string(e)
 }
 
-func (e *SubjectCategory) UnmarshalGQL(v any) error {
+func (e *LearnStage) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = SubjectCategory(str)
+	*e = LearnStage(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid SubjectCategory", str)
+		return fmt.Errorf("%s is not a valid LearnStage", str)
 	}
 	return nil
 }
 
-func (e SubjectCategory) MarshalGQL(w io.Writer) {
+func (e LearnStage) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *SubjectCategory) UnmarshalJSON(b []byte) error {
+func (e *LearnStage) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -500,7 +1045,524 @@ func (e *SubjectCategory) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e SubjectCategory) MarshalJSON() ([]byte, error) {
+func (e LearnStage) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MasteryLevel string
+
+const (
+	MasteryLevelNew      MasteryLevel = "NEW"
+	MasteryLevelLearning MasteryLevel = "LEARNING"
+	MasteryLevelFamiliar MasteryLevel = "FAMILIAR"
+	MasteryLevelMastered MasteryLevel = "MASTERED"
+)
+
+var AllMasteryLevel = []MasteryLevel{
+	MasteryLevelNew,
+	MasteryLevelLearning,
+	MasteryLevelFamiliar,
+	MasteryLevelMastered,
+}
+
+func (e MasteryLevel) IsValid() bool {
+	switch e {
+	case MasteryLevelNew, MasteryLevelLearning, MasteryLevelFamiliar, MasteryLevelMastered:
+		return true
+	}
+	return false
+}
+
+func (e MasteryLevel) String() string {
+	return string(e)
+}
+
+func (e *MasteryLevel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MasteryLevel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MasteryLevel", str)
+	}
+	return nil
+}
+
+func (e MasteryLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MasteryLevel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MasteryLevel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MatchLeaderboardPeriod string
+
+const (
+	MatchLeaderboardPeriodDay  MatchLeaderboardPeriod = "DAY"
+	MatchLeaderboardPeriodWeek MatchLeaderboardPeriod = "WEEK"
+	MatchLeaderboardPeriodAll  MatchLeaderboardPeriod = "ALL"
+)
+
+var AllMatchLeaderboardPeriod = []MatchLeaderboardPeriod{
+	MatchLeaderboardPeriodDay,
+	MatchLeaderboardPeriodWeek,
+	MatchLeaderboardPeriodAll,
+}
+
+func (e MatchLeaderboardPeriod) IsValid() bool {
+	switch e {
+	case MatchLeaderboardPeriodDay, MatchLeaderboardPeriodWeek, MatchLeaderboardPeriodAll:
+		return true
+	}
+	return false
+}
+
+func (e MatchLeaderboardPeriod) String() string {
+	return string(e)
+}
+
+func (e *MatchLeaderboardPeriod) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MatchLeaderboardPeriod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MatchLeaderboardPeriod", str)
+	}
+	return nil
+}
+
+func (e MatchLeaderboardPeriod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MatchLeaderboardPeriod) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MatchLeaderboardPeriod) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeStudysetSaved             NotificationType = "STUDYSET_SAVED"
+	NotificationTypeStudysetHiddenByModerator NotificationType = "STUDYSET_HIDDEN_BY_MODERATOR"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeStudysetSaved,
+	NotificationTypeStudysetHiddenByModerator,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeStudysetSaved, NotificationTypeStudysetHiddenByModerator:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PracticeTestWeighting string
+
+const (
+	PracticeTestWeightingUniform  PracticeTestWeighting = "UNIFORM"
+	PracticeTestWeightingFsrs     PracticeTestWeighting = "FSRS"
+	PracticeTestWeightingAccuracy PracticeTestWeighting = "ACCURACY"
+	PracticeTestWeightingMastery  PracticeTestWeighting = "MASTERY"
+)
+
+var AllPracticeTestWeighting = []PracticeTestWeighting{
+	PracticeTestWeightingUniform,
+	PracticeTestWeightingFsrs,
+	PracticeTestWeightingAccuracy,
+	PracticeTestWeightingMastery,
+}
+
+func (e PracticeTestWeighting) IsValid() bool {
+	switch e {
+	case PracticeTestWeightingUniform, PracticeTestWeightingFsrs, PracticeTestWeightingAccuracy, PracticeTestWeightingMastery:
+		return true
+	}
+	return false
+}
+
+func (e PracticeTestWeighting) String() string {
+	return string(e)
+}
+
+func (e *PracticeTestWeighting) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PracticeTestWeighting(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PracticeTestWeighting", str)
+	}
+	return nil
+}
+
+func (e PracticeTestWeighting) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PracticeTestWeighting) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PracticeTestWeighting) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type QuestionType string
+
+const (
+	QuestionTypeMcq QuestionType = "MCQ"
+	QuestionTypeTfq QuestionType = "TFQ"
+	QuestionTypeFrq QuestionType = "FRQ"
+)
+
+var AllQuestionType = []QuestionType{
+	QuestionTypeMcq,
+	QuestionTypeTfq,
+	QuestionTypeFrq,
+}
+
+func (e QuestionType) IsValid() bool {
+	switch e {
+	case QuestionTypeMcq, QuestionTypeTfq, QuestionTypeFrq:
+		return true
+	}
+	return false
+}
+
+func (e QuestionType) String() string {
+	return string(e)
+}
+
+func (e *QuestionType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QuestionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QuestionType", str)
+	}
+	return nil
+}
+
+func (e QuestionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *QuestionType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e QuestionType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReminderChannel string
+
+const (
+	ReminderChannelEmail   ReminderChannel = "EMAIL"
+	ReminderChannelWebhook ReminderChannel = "WEBHOOK"
+)
+
+var AllReminderChannel = []ReminderChannel{
+	ReminderChannelEmail,
+	ReminderChannelWebhook,
+}
+
+func (e ReminderChannel) IsValid() bool {
+	switch e {
+	case ReminderChannelEmail, ReminderChannelWebhook:
+		return true
+	}
+	return false
+}
+
+func (e ReminderChannel) String() string {
+	return string(e)
+}
+
+func (e *ReminderChannel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReminderChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReminderChannel", str)
+	}
+	return nil
+}
+
+func (e ReminderChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReminderChannel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReminderChannel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SubjectCategory string
+
+const (
+	SubjectCategoryLang          SubjectCategory = "LANG"
+	SubjectCategoryStem          SubjectCategory = "STEM"
+	SubjectCategorySocialStudies SubjectCategory = "SOCIAL_STUDIES"
+	SubjectCategoryLa            SubjectCategory = "LA"
+	SubjectCategoryMath          SubjectCategory = "MATH"
+)
+
+var AllSubjectCategory = []SubjectCategory{
+	SubjectCategoryLang,
+	SubjectCategoryStem,
+	SubjectCategorySocialStudies,
+	SubjectCategoryLa,
+	SubjectCategoryMath,
+}
+
+func (e SubjectCategory) IsValid() bool {
+	switch e {
+	case SubjectCategoryLang, SubjectCategoryStem, SubjectCategorySocialStudies, SubjectCategoryLa, SubjectCategoryMath:
+		return true
+	}
+	return false
+}
+
+func (e SubjectCategory) String() string {
+	return string(e)
+}
+
+func (e *SubjectCategory) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SubjectCategory(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SubjectCategory", str)
+	}
+	return nil
+}
+
+func (e SubjectCategory) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SubjectCategory) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SubjectCategory) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SyncItemType string
+
+const (
+	SyncItemTypeReview        SyncItemType = "REVIEW"
+	SyncItemTypeTermProgress  SyncItemType = "TERM_PROGRESS"
+	SyncItemTypePracticeTest  SyncItemType = "PRACTICE_TEST"
+	SyncItemTypeMatchActivity SyncItemType = "MATCH_ACTIVITY"
+)
+
+var AllSyncItemType = []SyncItemType{
+	SyncItemTypeReview,
+	SyncItemTypeTermProgress,
+	SyncItemTypePracticeTest,
+	SyncItemTypeMatchActivity,
+}
+
+func (e SyncItemType) IsValid() bool {
+	switch e {
+	case SyncItemTypeReview, SyncItemTypeTermProgress, SyncItemTypePracticeTest, SyncItemTypeMatchActivity:
+		return true
+	}
+	return false
+}
+
+func (e SyncItemType) String() string {
+	return string(e)
+}
+
+func (e *SyncItemType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SyncItemType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SyncItemType", str)
+	}
+	return nil
+}
+
+func (e SyncItemType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SyncItemType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SyncItemType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TermMasteryOrder string
+
+const (
+	TermMasteryOrderStudyset TermMasteryOrder = "STUDYSET"
+	TermMasteryOrderWeakest  TermMasteryOrder = "WEAKEST"
+)
+
+var AllTermMasteryOrder = []TermMasteryOrder{
+	TermMasteryOrderStudyset,
+	TermMasteryOrderWeakest,
+}
+
+func (e TermMasteryOrder) IsValid() bool {
+	switch e {
+	case TermMasteryOrderStudyset, TermMasteryOrderWeakest:
+		return true
+	}
+	return false
+}
+
+func (e TermMasteryOrder) String() string {
+	return string(e)
+}
+
+func (e *TermMasteryOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TermMasteryOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TermMasteryOrder", str)
+	}
+	return nil
+}
+
+func (e TermMasteryOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TermMasteryOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TermMasteryOrder) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
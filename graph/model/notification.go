@@ -0,0 +1,14 @@
+package model
+
+type Notification struct {
+	ID          string           `json:"id" db:"id"`
+	Type        NotificationType `json:"type" db:"type"`
+	ActorUserID *string          `json:"-" db:"actor_user_id"`
+	Actor       *User            `json:"actor,omitempty" db:"-"`
+	StudysetID  *string          `json:"-" db:"studyset_id"`
+	Studyset    *Studyset        `json:"studyset,omitempty" db:"-"`
+	URL         *string          `json:"url,omitempty" db:"url"`
+	Read        bool             `json:"read" db:"read"`
+	CreatedAt   string           `json:"createdAt" db:"created_at"`
+	ReadAt      *string          `json:"readAt,omitempty" db:"read_at"`
+}
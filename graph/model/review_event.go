@@ -21,4 +21,5 @@ type ReviewActivityTypeEnum string
 const (
 	ReviewActivityTypePracticeTest ReviewActivityTypeEnum = "PRACTICE_TEST"
 	ReviewActivityTypeMatch        ReviewActivityTypeEnum = "MATCH"
+	ReviewActivityTypeLearn        ReviewActivityTypeEnum = "LEARN"
 )
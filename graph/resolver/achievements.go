@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"context"
+
+	"quizfreely/api/achievements"
+
+	"github.com/rs/zerolog/log"
+)
+
+type deferredAchievementsKey struct{}
+
+/*
+deferAchievements returns a context that makes evaluateAchievements save events to events instead of evaluating them,
+so mutations that record lots of activity (like syncStudyActivity) can evaluate achievements once at the end
+*/
+func deferAchievements(ctx context.Context, events *[]achievements.Event) context.Context {
+	return context.WithValue(ctx, deferredAchievementsKey{}, events)
+}
+
+/*
+evaluateAchievements checks a user's achievements after study activity is recorded (after its transaction commits).
+Errors are logged instead of failing the mutation, the achievements backfill job catches anything that's missed
+*/
+func (r *Resolver) evaluateAchievements(ctx context.Context, userID string, events ...achievements.Event) {
+	if deferred, ok := ctx.Value(deferredAchievementsKey{}).(*[]achievements.Event); ok {
+		*deferred = append(*deferred, events...)
+		return
+	}
+
+	settings, err := r.studySettings(ctx, userID, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("DB Error getting study settings for achievements")
+		return
+	}
+	_, err = achievements.Evaluate(ctx, r.DB, userID, achievements.Options{
+		Location:     userLocation(ctx),
+		RolloverHour: settings.DayRolloverHour,
+	}, events...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to evaluate achievements")
+	}
+}
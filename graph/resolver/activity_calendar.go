@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/streak"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/*
+activityCalendar builds a calendar (see streak.BuildCalendar) of a user's reviews and match activities
+for the study days from `from` to `to` (dates at midnight UTC, like streak.Date).
+FSRS reviews count like review events, but MANUAL ratings aren't reviews,
+and learn answers (which are a review event and an FSRS review) only count once
+*/
+func (r *Resolver) activityCalendar(ctx context.Context, userID string, from time.Time, to time.Time, loc *time.Location, rolloverHour int32) (*model.ActivityCalendar, error) {
+	/* from's study day starts at the rollover hour, and to's ends at the next day's rollover hour */
+	start := time.Date(from.Year(), from.Month(), from.Day(), int(rolloverHour), 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, int(rolloverHour), 0, 0, 0, loc)
+
+	var reviews []streak.Review
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&reviews,
+		`SELECT re."timestamp" AS reviewed_at, re.term_id::text AS term_id,
+	COALESCE(t.studyset_id::text, '') AS studyset_id, re.review_activity_type = $4 AS match
+FROM review_events re
+LEFT JOIN terms t ON t.id = re.term_id
+WHERE re.user_id = $1 AND re."timestamp" >= $2 AND re."timestamp" < $3
+UNION ALL
+SELECT l.review AS reviewed_at, COALESCE(l.term_id::text, '') AS term_id,
+	COALESCE(t.studyset_id::text, '') AS studyset_id, false AS match
+FROM fsrs_review_logs l
+LEFT JOIN terms t ON t.id = l.term_id
+WHERE l.user_id = $1 AND l.rating <> 'MANUAL' AND l.review >= $2 AND l.review < $3
+AND NOT EXISTS (
+	SELECT 1 FROM review_events lre
+	WHERE lre.user_id = l.user_id AND lre.term_id = l.term_id AND lre."timestamp" = l.review
+	AND lre.review_activity_type = $5
+)`,
+		userID,
+		start,
+		end,
+		model.ReviewActivityTypeMatch,
+		model.ReviewActivityTypeLearn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchRows []struct {
+		EndTimestamp time.Time `db:"end_timestamp"`
+		DurationMs   int32     `db:"duration_ms"`
+	}
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&matchRows,
+		`SELECT end_timestamp, duration_ms FROM match_activities
+		WHERE user_id = $1 AND end_timestamp >= $2 AND end_timestamp < $3`,
+		userID,
+		start,
+		end,
+	)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]streak.MatchActivity, len(matchRows))
+	for i, m := range matchRows {
+		matches[i] = streak.MatchActivity{
+			EndedAt:  m.EndTimestamp,
+			Duration: time.Duration(m.DurationMs) * time.Millisecond,
+		}
+	}
+
+	calendar := streak.BuildCalendar(reviews, matches, from, to, loc, rolloverHour)
+	return &model.ActivityCalendar{
+		Days:   activityPeriods(calendar.Days),
+		Weeks:  activityPeriods(calendar.Weeks),
+		Months: activityPeriods(calendar.Months),
+	}, nil
+}
+
+func activityPeriods(periods []streak.Period) []*model.ActivityPeriod {
+	result := make([]*model.ActivityPeriod, len(periods))
+	for i, p := range periods {
+		result[i] = &model.ActivityPeriod{
+			Date:       p.Start.Format(time.DateOnly),
+			Reviews:    int32(p.Reviews),
+			Minutes:    int32(p.StudyTime / time.Minute),
+			Studysets:  int32(p.Studysets),
+			Terms:      int32(p.Terms),
+			ActiveDays: int32(p.ActiveDays),
+		}
+	}
+	return result
+}
@@ -0,0 +1,549 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"quizfreely/api/fsrs"
+	"quizfreely/api/graph/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+/* reviews can't be in the future, but clients' clocks can be a little ahead */
+const maxReviewClockSkew = time.Minute
+
+var defaultFSRSScheduler, _ = fsrs.NewScheduler(fsrs.DefaultParameters())
+
+/* fsrs optimizations started by optimizeFsrsParameters are cancelled after this long */
+const fsrsOptimizationTimeout = 10 * time.Minute
+
+/*
+fsrsScheduler returns the scheduler to use for a user's reviews with their study settings,
+and their optimized weights (if they have them)
+*/
+func (r *Resolver) fsrsScheduler(ctx context.Context, userID string, settings *model.StudySettings) *fsrs.Scheduler {
+	params, err := fsrs.LoadUserParameters(ctx, r.DB, userID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load fsrs parameters, using defaults")
+		params = fsrs.DefaultParameters()
+	}
+	params.RequestRetention = settings.DesiredRetention
+	params.LearningSteps = stepsToDurations(settings.LearningStepsMinutes)
+	params.RelearningSteps = stepsToDurations(settings.RelearningStepsMinutes)
+
+	scheduler, err := fsrs.NewScheduler(params)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid fsrs parameters, using defaults")
+		return defaultFSRSScheduler
+	}
+	return scheduler
+}
+
+/*
+isNewLeech reports whether a review that changed a card's lapses makes it a leech.
+Like Anki, cards become leeches when their lapses reach the threshold,
+then again every half threshold after that (in case the leech tag was cleared).
+*/
+func isNewLeech(previousLapses int, lapses int, threshold int32) bool {
+	t := int(threshold)
+	if t <= 0 || lapses <= previousLapses || lapses < t {
+		return false
+	}
+	return (lapses-t)%max(t/2, 1) == 0
+}
+
+type dbFSRSParameters struct {
+	Weights        []float64                     `db:"weights"`
+	LogLoss        *float64                      `db:"log_loss"`
+	DefaultLogLoss *float64                      `db:"default_log_loss"`
+	ReviewCount    int32                         `db:"review_count"`
+	Status         *model.FSRSOptimizationStatus `db:"status"`
+	Error          *string                       `db:"error"`
+	RequestedAt    *string                       `db:"requested_at"`
+	OptimizedAt    *string                       `db:"optimized_at"`
+}
+
+/* fsrsParameters returns a user's fsrs parameters, or the defaults (with a null status) if they've never been optimized */
+func (r *Resolver) fsrsParameters(ctx context.Context, userID string) (*model.FSRSParameters, error) {
+	var rows []*dbFSRSParameters
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`SELECT weights,
+			log_loss,
+			default_log_loss,
+			review_count,
+			status,
+			error,
+			to_char(requested_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as requested_at,
+			to_char(optimized_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as optimized_at
+		FROM fsrs_parameters
+		WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &model.FSRSParameters{
+		Weights:       fsrs.DefaultWeights,
+		UsingDefaults: true,
+	}
+	if len(rows) > 0 {
+		row := rows[0]
+		if row.Weights != nil {
+			params.Weights = row.Weights
+			params.UsingDefaults = false
+		}
+		params.LogLoss = row.LogLoss
+		params.DefaultLogLoss = row.DefaultLogLoss
+		params.ReviewCount = row.ReviewCount
+		params.Status = row.Status
+		params.Error = row.Error
+		params.RequestedAt = row.RequestedAt
+		params.OptimizedAt = row.OptimizedAt
+	}
+	return params, nil
+}
+
+/*
+studyScopeCTE selects the studysets in scope for a user's ($1) study queue/stats:
+studysetIds ($2), or studysets in the user's folder ($3),
+or by default, the user's own studysets, saved studysets, and studysets with cards the user has reviewed
+*/
+const studyScopeCTE = `scope AS (
+	SELECT s.id FROM studysets s
+	WHERE $2::uuid[] IS NOT NULL AND s.id = ANY($2::uuid[])
+	AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+	UNION
+	SELECT s.id FROM folder_studysets fs
+	JOIN studysets s ON s.id = fs.studyset_id
+	WHERE $2::uuid[] IS NULL AND fs.folder_id = $3::uuid AND fs.user_id = $1
+	AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+	UNION
+	SELECT s.id FROM studysets s
+	WHERE $2::uuid[] IS NULL AND $3::uuid IS NULL
+	AND s.user_id = $1 AND s.draft = false
+	UNION
+	SELECT s.id FROM saved_studysets ss
+	JOIN studysets s ON s.id = ss.studyset_id
+	WHERE $2::uuid[] IS NULL AND $3::uuid IS NULL AND ss.user_id = $1
+	AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+	UNION
+	SELECT s.id FROM fsrs_cards fc
+	JOIN terms t ON t.id = fc.term_id
+	JOIN studysets s ON s.id = t.studyset_id
+	WHERE $2::uuid[] IS NULL AND $3::uuid IS NULL AND fc.user_id = $1
+	AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+)`
+
+/* fsrsCardColumns are fsrs_cards' columns for model.FSRSCard */
+const fsrsCardColumns = `difficulty,
+    to_char(due, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as due,
+    lapses,
+    to_char(last_review, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as last_review,
+    learning_steps,
+    reps,
+    scheduled_days,
+    stability,
+    state,
+    suspended,
+    to_char(buried_until, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as buried_until,
+    leech`
+
+type dbFSRSCard struct {
+	Difficulty    float64    `db:"difficulty"`
+	Due           time.Time  `db:"due"`
+	Lapses        int        `db:"lapses"`
+	LastReview    *time.Time `db:"last_review"`
+	LearningSteps int        `db:"learning_steps"`
+	Reps          int        `db:"reps"`
+	ScheduledDays int        `db:"scheduled_days"`
+	Stability     float64    `db:"stability"`
+	State         fsrs.State `db:"state"`
+	BuriedUntil   *time.Time `db:"buried_until"`
+}
+
+func (c *dbFSRSCard) fsrsCard() fsrs.Card {
+	return fsrs.Card{
+		Difficulty:    c.Difficulty,
+		Stability:     c.Stability,
+		Due:           c.Due,
+		LastReview:    c.LastReview,
+		LearningSteps: c.LearningSteps,
+		Reps:          c.Reps,
+		Lapses:        c.Lapses,
+		ScheduledDays: c.ScheduledDays,
+		State:         c.State,
+	}
+}
+
+/*
+reviewFSRSCard loads the user's card for a term (locking it until tx ends, so concurrent reviews don't overwrite each other),
+schedules the review, and saves the new card & a review log in tx
+*/
+func (r *Resolver) reviewFSRSCard(ctx context.Context, tx pgx.Tx, userID string, termID string, studysetID string, rating fsrs.Rating, reviewedAt time.Time) (*model.FSRSCard, error) {
+	var dbCards []*dbFSRSCard
+	err := pgxscan.Select(
+		ctx,
+		tx,
+		&dbCards,
+		`SELECT difficulty, due, lapses, last_review, learning_steps, reps, scheduled_days, stability, state
+		FROM fsrs_cards
+		WHERE term_id = $1 AND user_id = $2
+		FOR UPDATE`,
+		termID,
+		userID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error getting fsrs card in reviewFSRSCard")
+		return nil, errors.New("DB error getting fsrs card")
+	}
+
+	card := fsrs.NewCard(reviewedAt)
+	if len(dbCards) > 0 {
+		card = dbCards[0].fsrsCard()
+	}
+
+	settings, err := r.studySettings(ctx, userID, &studysetID)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error getting study settings in reviewFSRSCard")
+		return nil, errors.New("DB error getting study settings")
+	}
+	nextCard, reviewLog, err := r.fsrsScheduler(ctx, userID, settings).Review(card, rating, reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := saveFSRSCard(ctx, tx, userID, termID, nextCard, isNewLeech(card.Lapses, nextCard.Lapses, settings.LeechThreshold))
+	if err != nil {
+		return nil, err
+	}
+	if err := saveFSRSReviewLogs(ctx, tx, userID, termID, []fsrs.ReviewLog{reviewLog}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+/*
+replayFSRSCard applies a batch of (possibly old) reviews to the user's card for a term in tx, like reviews synced by offline clients.
+Reviews after the card's last review are scheduled from the current card, like reviewFSRSCard.
+If any are older (reviewed offline before a review from another device), the card is rebuilt by replaying
+all of its rated review logs with the new reviews in order, and the review logs are rewritten.
+Reviews that are already logged (same rating & time, like from a retried sync) are skipped.
+*/
+func (r *Resolver) replayFSRSCard(ctx context.Context, tx pgx.Tx, userID string, termID string, studysetID string, reviews []fsrs.ReviewEntry) (*model.FSRSCard, error) {
+	var dbCards []*dbFSRSCard
+	err := pgxscan.Select(
+		ctx,
+		tx,
+		&dbCards,
+		`SELECT difficulty, due, lapses, last_review, learning_steps, reps, scheduled_days, stability, state
+		FROM fsrs_cards
+		WHERE term_id = $1 AND user_id = $2
+		FOR UPDATE`,
+		termID,
+		userID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error getting fsrs card in replayFSRSCard")
+		return nil, errors.New("DB error getting fsrs card")
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT rating, review FROM fsrs_review_logs
+		WHERE term_id = $1 AND user_id = $2 AND rating <> 'MANUAL'
+		ORDER BY review`,
+		termID,
+		userID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error getting fsrs review logs in replayFSRSCard")
+		return nil, errors.New("DB error getting fsrs review logs")
+	}
+	var logged []fsrs.ReviewEntry
+	for rows.Next() {
+		var rating string
+		var entry fsrs.ReviewEntry
+		if err := rows.Scan(&rating, &entry.Review); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan fsrs review log: %w", err)
+		}
+		if entry.Rating, err = fsrs.ParseRating(rating); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		logged = append(logged, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get fsrs review logs: %w", err)
+	}
+
+	/* the db stores microseconds, so new reviews are compared with logged reviews at that precision */
+	type reviewKey struct {
+		rating fsrs.Rating
+		review int64
+	}
+	seen := make(map[reviewKey]bool, len(logged)+len(reviews))
+	for _, entry := range logged {
+		seen[reviewKey{entry.Rating, entry.Review.UnixMicro()}] = true
+	}
+	var newReviews []fsrs.ReviewEntry
+	for _, entry := range reviews {
+		entry.Review = entry.Review.Truncate(time.Microsecond)
+		key := reviewKey{entry.Rating, entry.Review.UnixMicro()}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		newReviews = append(newReviews, entry)
+	}
+	sort.SliceStable(newReviews, func(i, j int) bool {
+		return newReviews[i].Review.Before(newReviews[j].Review)
+	})
+
+	if len(newReviews) == 0 {
+		var result model.FSRSCard
+		err = pgxscan.Get(ctx, tx, &result, `SELECT `+fsrsCardColumns+` FROM fsrs_cards WHERE term_id = $1 AND user_id = $2`, termID, userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fsrs card: %w", err)
+		}
+		return &result, nil
+	}
+
+	card := fsrs.NewCard(newReviews[0].Review)
+	if len(dbCards) > 0 {
+		card = dbCards[0].fsrsCard()
+	}
+	previousLapses := card.Lapses
+
+	/* the card is only rebuilt if a new review conflicts with (is older than) its last review */
+	replay := newReviews
+	conflict := card.LastReview != nil && newReviews[0].Review.Before(*card.LastReview)
+	if conflict {
+		replay = append(logged, newReviews...)
+		sort.SliceStable(replay, func(i, j int) bool {
+			return replay[i].Review.Before(replay[j].Review)
+		})
+		card = fsrs.NewCard(replay[0].Review)
+	}
+
+	settings, err := r.studySettings(ctx, userID, &studysetID)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error getting study settings in replayFSRSCard")
+		return nil, errors.New("DB error getting study settings")
+	}
+	scheduler := r.fsrsScheduler(ctx, userID, settings)
+
+	leech := false
+	reviewLogs := make([]fsrs.ReviewLog, 0, len(replay))
+	for _, entry := range replay {
+		nextCard, reviewLog, err := scheduler.Review(card, entry.Rating, entry.Review)
+		if err != nil {
+			return nil, err
+		}
+		/* lapses that were already counted before this sync don't tag the card again */
+		if nextCard.Lapses > previousLapses && isNewLeech(card.Lapses, nextCard.Lapses, settings.LeechThreshold) {
+			leech = true
+		}
+		card = nextCard
+		reviewLogs = append(reviewLogs, reviewLog)
+	}
+
+	if conflict {
+		_, err = tx.Exec(
+			ctx,
+			`DELETE FROM fsrs_review_logs WHERE term_id = $1 AND user_id = $2 AND rating <> 'MANUAL'`,
+			termID,
+			userID,
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("DB error deleting fsrs review logs in replayFSRSCard")
+			return nil, errors.New("DB error replacing fsrs review logs")
+		}
+	}
+
+	result, err := saveFSRSCard(ctx, tx, userID, termID, card, leech)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveFSRSReviewLogs(ctx, tx, userID, termID, reviewLogs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+/* saveFSRSCard upserts the user's card for a term in tx, leech only tags the card (it doesn't clear the tag) */
+func saveFSRSCard(ctx context.Context, tx pgx.Tx, userID string, termID string, card fsrs.Card, leech bool) (*model.FSRSCard, error) {
+	var result model.FSRSCard
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&result,
+		`INSERT INTO fsrs_cards (
+    term_id,
+    user_id,
+    difficulty,
+    due,
+    lapses,
+    last_review,
+    learning_steps,
+    reps,
+    scheduled_days,
+    stability,
+    state,
+    leech
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (term_id, user_id)
+DO UPDATE SET
+    difficulty = EXCLUDED.difficulty,
+    due = EXCLUDED.due,
+    lapses = EXCLUDED.lapses,
+    last_review = EXCLUDED.last_review,
+    learning_steps = EXCLUDED.learning_steps,
+    reps = EXCLUDED.reps,
+    scheduled_days = EXCLUDED.scheduled_days,
+    stability = EXCLUDED.stability,
+    state = EXCLUDED.state,
+    leech = fsrs_cards.leech OR EXCLUDED.leech,
+    updated_at = now()
+RETURNING `+fsrsCardColumns,
+		termID,
+		userID,
+		card.Difficulty,
+		card.Due,
+		card.Lapses,
+		card.LastReview,
+		card.LearningSteps,
+		card.Reps,
+		card.ScheduledDays,
+		card.Stability,
+		card.State,
+		leech,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error saving fsrs card in saveFSRSCard")
+		return nil, errors.New("DB error saving fsrs card")
+	}
+	return &result, nil
+}
+
+func saveFSRSReviewLogs(ctx context.Context, tx pgx.Tx, userID string, termID string, reviewLogs []fsrs.ReviewLog) error {
+	if len(reviewLogs) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(reviewLogs))
+	args := make([]any, 0, len(reviewLogs)*8+2)
+	args = append(args, termID, userID)
+	for i, reviewLog := range reviewLogs {
+		base := i*8 + 3
+		placeholders[i] = fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", base, base+1, base+2, base+3, base+4, base+5, base+6, base+7)
+		args = append(args,
+			reviewLog.Difficulty,
+			reviewLog.Due,
+			reviewLog.LearningSteps,
+			reviewLog.Rating.String(),
+			reviewLog.Review,
+			reviewLog.ScheduledDays,
+			reviewLog.Stability,
+			reviewLog.State,
+		)
+	}
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO fsrs_review_logs (
+    term_id,
+    user_id,
+    difficulty,
+    due,
+    learning_steps,
+    rating,
+    review,
+    scheduled_days,
+    stability,
+    state
+) VALUES `+strings.Join(placeholders, ","),
+		args...,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error saving fsrs review logs in saveFSRSReviewLogs")
+		return errors.New("DB error saving fsrs review log")
+	}
+	return nil
+}
+
+/*
+updateFSRSCard updates a user's card for a term they can view with set (like "suspended = true", args start at $3),
+creating a new card first if they haven't studied the term yet, so new cards can be suspended/buried too.
+It returns nil if the term doesn't exist or the user can't view it.
+*/
+func (r *Resolver) updateFSRSCard(ctx context.Context, userID string, termID string, set string, args ...any) (*model.FSRSCard, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var visible bool
+	err = tx.QueryRow(
+		ctx,
+		`SELECT EXISTS (
+			SELECT 1 FROM terms t
+			JOIN studysets s ON s.id = t.studyset_id
+			WHERE t.id = $1
+			AND ((s.draft = false AND s.private = false) OR s.user_id = $2)
+		)`,
+		termID,
+		userID,
+	).Scan(&visible)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check term: %w", err)
+	}
+	if !visible {
+		return nil, nil
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO fsrs_cards (term_id, user_id, difficulty, due, lapses, learning_steps, reps, scheduled_days, stability, state)
+		VALUES ($1, $2, 0, now(), 0, 0, 0, 0, 0, 'NEW')
+		ON CONFLICT (term_id, user_id) DO NOTHING`,
+		termID,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsrs card: %w", err)
+	}
+
+	var card model.FSRSCard
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&card,
+		`UPDATE fsrs_cards SET `+set+`, updated_at = now()
+		WHERE term_id = $1 AND user_id = $2
+		RETURNING `+fsrsCardColumns,
+		append([]any{termID, userID}, args...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fsrs card: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &card, nil
+}
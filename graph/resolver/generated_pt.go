@@ -0,0 +1,336 @@
+// NOTE: filename can't be `practice_test.go` because that ends with `_test.go`
+
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"quizfreely/api/fsrs"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/mastery"
+	"quizfreely/api/practicetest"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+/* generated practice tests have to be submitted before they expire */
+const generatedPracticeTestTTL = 24 * time.Hour
+
+/* even well known terms are sometimes picked */
+const minPracticeTestWeight = 0.05
+
+type practiceTestTermRow struct {
+	ID                 string      `db:"id"`
+	StudysetID         string      `db:"studyset_id"`
+	Term               string      `db:"term"`
+	Def                string      `db:"def"`
+	State              *fsrs.State `db:"state"`
+	Stability          *float64    `db:"stability"`
+	LastReview         *time.Time  `db:"last_review"`
+	TermCorrectCount   int32       `db:"term_correct_count"`
+	TermIncorrectCount int32       `db:"term_incorrect_count"`
+	DefCorrectCount    int32       `db:"def_correct_count"`
+	DefIncorrectCount  int32       `db:"def_incorrect_count"`
+	/* only loaded for MASTERY weighting */
+	Mastery *mastery.Mastery `db:"-"`
+}
+
+/* generatedQuestionData is the same as practice_test_questions.data, without the user's answers */
+type generatedQuestionData struct {
+	Distractors        []*model.TermATPInput `json:"distractors,omitempty"`
+	CorrectChoiceIndex *int32                `json:"correctChoiceIndex,omitempty"`
+	Distractor         *model.TermATPInput   `json:"distractor,omitempty"`
+}
+
+/* loadPracticeTestTerms loads the terms in studysets the user can view, with their fsrs cards and term progress */
+func (r *Resolver) loadPracticeTestTerms(ctx context.Context, userID string, studysetIDs []string) ([]practiceTestTermRow, error) {
+	var rows []practiceTestTermRow
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`SELECT t.id, t.studyset_id, t.term, t.def,
+	fc.state, fc.stability, fc.last_review,
+	COALESCE(tp.term_correct_count, 0) AS term_correct_count,
+	COALESCE(tp.term_incorrect_count, 0) AS term_incorrect_count,
+	COALESCE(tp.def_correct_count, 0) AS def_correct_count,
+	COALESCE(tp.def_incorrect_count, 0) AS def_incorrect_count
+FROM terms t
+JOIN studysets s ON s.id = t.studyset_id
+LEFT JOIN fsrs_cards fc ON fc.term_id = t.id AND fc.user_id = $1
+LEFT JOIN term_progress tp ON tp.term_id = t.id AND tp.user_id = $1
+WHERE t.studyset_id = ANY($2::uuid[])
+AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+ORDER BY t.studyset_id, t.sort_order`,
+		userID,
+		studysetIDs,
+	)
+	return rows, err
+}
+
+/*
+practiceTestWeight returns how likely a term is to be picked:
+FSRS picks terms the user is least likely to remember (and new terms),
+ACCURACY picks terms the user gets wrong most often in practice tests (for answerWith, or both ways if it's nil),
+MASTERY picks terms with the lowest mastery (see the mastery package)
+*/
+func practiceTestWeight(row *practiceTestTermRow, weighting model.PracticeTestWeighting, answerWith *model.AnswerWith, scheduler *fsrs.Scheduler, now time.Time) float64 {
+	switch weighting {
+	case model.PracticeTestWeightingFsrs:
+		if row.State == nil || *row.State == fsrs.New || row.Stability == nil {
+			return 1
+		}
+		card := fsrs.Card{State: *row.State, Stability: *row.Stability, LastReview: row.LastReview}
+		return math.Max(1-scheduler.Retrievability(card, now), minPracticeTestWeight)
+	case model.PracticeTestWeightingAccuracy:
+		var correct, incorrect int32
+		if answerWith == nil || *answerWith == model.AnswerWithTerm {
+			correct += row.TermCorrectCount
+			incorrect += row.TermIncorrectCount
+		}
+		if answerWith == nil || *answerWith == model.AnswerWithDef {
+			correct += row.DefCorrectCount
+			incorrect += row.DefIncorrectCount
+		}
+		/* terms without answers are 50/50 */
+		return float64(incorrect+1) / float64(correct+incorrect+2)
+	case model.PracticeTestWeightingMastery:
+		if row.Mastery == nil {
+			return 1
+		}
+		return math.Max(1-row.Mastery.Score, minPracticeTestWeight)
+	default:
+		return 1
+	}
+}
+
+func termATP(term *practicetest.Term) *model.TermAtp {
+	return &model.TermAtp{ID: &term.ID, Term: term.Term, Def: term.Def}
+}
+
+func termATPInput(term *practicetest.Term) *model.TermATPInput {
+	return &model.TermATPInput{ID: term.ID, Term: term.Term, Def: term.Def}
+}
+
+/* saveGeneratedPracticeTest saves a generated test's questions (and removes the user's expired generated tests) */
+func (r *Resolver) saveGeneratedPracticeTest(ctx context.Context, userID string, studysetIDs []string, questions []practicetest.Question) (*model.GeneratedPracticeTest, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
+		ctx,
+		"DELETE FROM generated_practice_tests WHERE user_id = $1 AND expires_at < now()",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired practice tests: %w", err)
+	}
+
+	generated := model.GeneratedPracticeTest{
+		StudysetIds: studysetIDs,
+		Questions:   make([]*model.GeneratedQuestion, len(questions)),
+	}
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO generated_practice_tests (user_id, studyset_ids, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		RETURNING id, to_char(expires_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM')`,
+		userID,
+		studysetIDs,
+		generatedPracticeTestTTL.Seconds(),
+	).Scan(&generated.ID, &generated.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save practice test: %w", err)
+	}
+	if len(questions) == 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return &generated, nil
+	}
+
+	placeholders := make([]string, len(questions))
+	args := make([]interface{}, 0, len(questions)*7+1)
+	args = append(args, generated.ID)
+	for i, q := range questions {
+		var data generatedQuestionData
+		switch q.Type {
+		case practicetest.MCQ:
+			data.Distractors = make([]*model.TermATPInput, len(q.Distractors))
+			for j := range q.Distractors {
+				data.Distractors[j] = termATPInput(&q.Distractors[j])
+			}
+			correctChoiceIndex := int32(q.CorrectChoiceIndex)
+			data.CorrectChoiceIndex = &correctChoiceIndex
+		case practicetest.TFQ:
+			if len(q.Distractors) > 0 {
+				data.Distractor = termATPInput(&q.Distractors[0])
+			}
+		}
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal question data: %w", err)
+		}
+
+		base := i*7 + 2
+		placeholders[i] = fmt.Sprintf("($1, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", base, base+1, base+2, base+3, base+4, base+5, base+6)
+		args = append(args, q.Term.ID, q.Term.Term, q.Term.Def, string(q.Type), string(q.AnswerWith), int32(i), dataBytes)
+	}
+	var questionIDs []string
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&questionIDs,
+		fmt.Sprintf(`INSERT INTO generated_practice_test_questions
+	(generated_practice_test_id, term_id, term_snapshot, def_snapshot, type, answer_with, position, data)
+VALUES %s
+RETURNING id`, strings.Join(placeholders, ",")),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save practice test questions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, q := range questions {
+		term := termATP(&q.Term)
+		answerWith := model.AnswerWith(q.AnswerWith)
+		question := &model.GeneratedQuestion{ID: questionIDs[i]}
+		switch q.Type {
+		case practicetest.MCQ:
+			distractors := make([]*model.TermAtp, len(q.Distractors))
+			for j := range q.Distractors {
+				distractors[j] = termATP(&q.Distractors[j])
+			}
+			question.Mcq = &model.GeneratedMcq{
+				Term:               term,
+				AnswerWith:         answerWith,
+				CorrectChoiceIndex: int32(q.CorrectChoiceIndex),
+				Distractors:        distractors,
+			}
+		case practicetest.TFQ:
+			question.Tfq = &model.GeneratedTfq{Term: term, AnswerWith: answerWith}
+			if len(q.Distractors) > 0 {
+				question.Tfq.Distractor = termATP(&q.Distractors[0])
+			}
+		default:
+			question.Frq = &model.GeneratedFrq{Term: term, AnswerWith: answerWith}
+		}
+		generated.Questions[i] = question
+	}
+	return &generated, nil
+}
+
+/* checkAnsweredString rejects free response answers too long to save or grade */
+func checkAnsweredString(answeredString *string) error {
+	if answeredString != nil && len(*answeredString) > MaxAnsweredStringLength {
+		return fmt.Errorf("answeredString is too long, max is %d bytes", MaxAnsweredStringLength)
+	}
+	return nil
+}
+
+/* gradeFRQ grades an FRQ's answer against the side of the term it's answered with */
+func gradeFRQ(frq *model.FRQInput) *model.FRQGrade {
+	expected := frq.Term.Def
+	if frq.AnswerWith == model.AnswerWithTerm {
+		expected = frq.Term.Term
+	}
+	answered := ""
+	if frq.AnsweredString != nil {
+		answered = *frq.AnsweredString
+	}
+	grade := practicetest.GradeAnswer(answered, expected)
+	return &model.FRQGrade{
+		Correct:       grade.Correct,
+		Score:         grade.Score,
+		Reason:        model.FRQGradeReason(grade.Reason),
+		MatchedAnswer: grade.MatchedAnswer,
+	}
+}
+
+/*
+applyGeneratedPracticeTest checks a submission against the generated test it answers,
+then replaces each question's terms, distractors, and correctness with the generated ones,
+so only the user's answers come from the client. Each generated test can only be submitted once.
+*/
+func applyGeneratedPracticeTest(ctx context.Context, tx pgx.Tx, userID string, generatedID string, questions []*model.QuestionInput) error {
+	tag, err := tx.Exec(
+		ctx,
+		`UPDATE generated_practice_tests SET submitted_at = now()
+		WHERE id = $1 AND user_id = $2 AND submitted_at IS NULL AND expires_at > now()`,
+		generatedID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update generated practice test: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("generated practice test not found, expired, or already submitted")
+	}
+
+	var rows []*model.QuestionRow
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&rows,
+		`SELECT id, term_id, term_snapshot, def_snapshot, type, answer_with, position, data
+		FROM generated_practice_test_questions
+		WHERE generated_practice_test_id = $1
+		ORDER BY position ASC`,
+		generatedID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch generated practice test questions: %w", err)
+	}
+	if len(rows) != len(questions) {
+		return errors.New("questions don't match the generated practice test")
+	}
+
+	for i, row := range rows {
+		q := questions[i]
+		if q == nil {
+			return errors.New("questions don't match the generated practice test")
+		}
+		var data generatedQuestionData
+		if err := json.Unmarshal(row.Data, &data); err != nil {
+			return fmt.Errorf("failed to unmarshal generated question data: %w", err)
+		}
+		term := &model.TermATPInput{ID: *row.TermID, Term: row.Term, Def: row.Def}
+
+		switch {
+		case row.Type == "MCQ" && q.Mcq != nil && q.Mcq.Term != nil && q.Mcq.Term.ID == term.ID:
+			q.Mcq.Term = term
+			q.Mcq.AnswerWith = row.AnswerWith
+			q.Mcq.Distractors = data.Distractors
+			q.Mcq.CorrectChoiceIndex = *data.CorrectChoiceIndex
+			q.Mcq.Correct = q.Mcq.AnsweredIndex != nil && *q.Mcq.AnsweredIndex == q.Mcq.CorrectChoiceIndex
+		case row.Type == "TFQ" && q.Tfq != nil && q.Tfq.Term != nil && q.Tfq.Term.ID == term.ID:
+			q.Tfq.Term = term
+			q.Tfq.AnswerWith = row.AnswerWith
+			q.Tfq.Distractor = data.Distractor
+			/* the pair shown is true if there's no distractor */
+			q.Tfq.Correct = q.Tfq.AnsweredBool != nil && *q.Tfq.AnsweredBool == (data.Distractor == nil)
+		case row.Type == "FRQ" && q.Frq != nil && q.Frq.Term != nil && q.Frq.Term.ID == term.ID:
+			q.Frq.Term = term
+			q.Frq.AnswerWith = row.AnswerWith
+			/* graded by RecordPracticeTest (userMarkedCorrect can still override it) */
+			q.Frq.Correct = nil
+		default:
+			return fmt.Errorf("question %d doesn't match the generated practice test", i+1)
+		}
+	}
+	return nil
+}
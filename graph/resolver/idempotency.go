@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+/* used if the config doesn't set idempotency_key_retention */
+const DefaultIdempotencyKeyRetention = 7 * 24 * time.Hour
+
+const MaxIdempotencyKeyLen = 255
+
+func (r *Resolver) idempotencyKeyRetention() time.Duration {
+	if r.IdempotencyKeyRetention > 0 {
+		return r.IdempotencyKeyRetention
+	}
+	return DefaultIdempotencyKeyRetention
+}
+
+/*
+claimIdempotencyKey claims a client's idempotency key for a mutation in tx (if key isn't nil).
+If the user already used the key (within the retention window), it returns the original result,
+which should be returned instead of running the mutation again.
+Retries that arrive while the original request is still running wait for its transaction.
+*/
+func (r *Resolver) claimIdempotencyKey(ctx context.Context, tx pgx.Tx, userID string, mutation string, key *string) ([]byte, error) {
+	if key == nil {
+		return nil, nil
+	}
+	if len(*key) == 0 || len(*key) > MaxIdempotencyKeyLen {
+		return nil, fmt.Errorf("idempotencyKey must be 1 to %d characters", MaxIdempotencyKeyLen)
+	}
+
+	/* expired keys are reused like new keys */
+	var claimed bool
+	err := tx.QueryRow(
+		ctx,
+		`INSERT INTO idempotency_keys (user_id, key, mutation)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			mutation = EXCLUDED.mutation,
+			result = null,
+			created_at = now()
+		WHERE idempotency_keys.created_at < now() - make_interval(secs => $4)
+		RETURNING true`,
+		userID,
+		*key,
+		mutation,
+		r.idempotencyKeyRetention().Seconds(),
+	).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	var usedMutation string
+	var result []byte
+	err = tx.QueryRow(
+		ctx,
+		"SELECT mutation, result FROM idempotency_keys WHERE user_id = $1 AND key = $2",
+		userID,
+		*key,
+	).Scan(&usedMutation, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if usedMutation != mutation {
+		return nil, fmt.Errorf("idempotencyKey was already used for %s", usedMutation)
+	}
+	if result == nil {
+		return nil, errors.New("idempotencyKey was already used, but its result wasn't saved")
+	}
+	return result, nil
+}
+
+/* saveIdempotencyResult saves a mutation's result for replays of key (if key isn't nil), in the same tx as the mutation */
+func saveIdempotencyResult(ctx context.Context, tx pgx.Tx, userID string, key *string, result any) error {
+	if key == nil {
+		return nil
+	}
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency result: %w", err)
+	}
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE idempotency_keys SET result = $3 WHERE user_id = $1 AND key = $2",
+		userID,
+		*key,
+		resultBytes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency result: %w", err)
+	}
+	return nil
+}
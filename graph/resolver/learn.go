@@ -0,0 +1,395 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/learn"
+	"quizfreely/api/practicetest"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+type learnSessionRow struct {
+	ID          string            `db:"id"`
+	StudysetIDs []string          `db:"studyset_ids"`
+	AnswerWith  *model.AnswerWith `db:"answer_with"`
+	RoundSize   int32             `db:"round_size"`
+	Round       int32             `db:"round"`
+	CreatedAt   string            `db:"created_at"`
+	UpdatedAt   string            `db:"updated_at"`
+	CompletedAt *string           `db:"completed_at"`
+}
+
+const learnSessionColumns = `id, studyset_ids::text[] AS studyset_ids, answer_with, round_size, round,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS updated_at,
+	to_char(completed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS completed_at`
+
+type learnQuestionRow struct {
+	ID         string           `db:"id"`
+	TermID     string           `db:"term_id"`
+	Type       string           `db:"type"`
+	AnswerWith model.AnswerWith `db:"answer_with"`
+	Term       string           `db:"term_snapshot"`
+	Def        string           `db:"def_snapshot"`
+	Data       []byte           `db:"data"`
+}
+
+/* generatedQuestion returns a saved learn question like a generated practice test question */
+func (row *learnQuestionRow) generatedQuestion() (*model.GeneratedQuestion, error) {
+	var data generatedQuestionData
+	if err := json.Unmarshal(row.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal learn question data: %w", err)
+	}
+	term := &model.TermAtp{ID: &row.TermID, Term: row.Term, Def: row.Def}
+	question := &model.GeneratedQuestion{ID: row.ID}
+	if row.Type == string(practicetest.MCQ) {
+		distractors := make([]*model.TermAtp, len(data.Distractors))
+		for i, d := range data.Distractors {
+			distractors[i] = &model.TermAtp{ID: &d.ID, Term: d.Term, Def: d.Def}
+		}
+		var correctChoiceIndex int32
+		if data.CorrectChoiceIndex != nil {
+			correctChoiceIndex = *data.CorrectChoiceIndex
+		}
+		question.Mcq = &model.GeneratedMcq{
+			Term:               term,
+			AnswerWith:         row.AnswerWith,
+			CorrectChoiceIndex: correctChoiceIndex,
+			Distractors:        distractors,
+		}
+	} else {
+		question.Frq = &model.GeneratedFrq{Term: term, AnswerWith: row.AnswerWith}
+	}
+	return question, nil
+}
+
+/* loadLearnStates loads the stage of every term in a learn session */
+func loadLearnStates(ctx context.Context, tx pgx.Tx, sessionID string) ([]learn.TermState, error) {
+	var rows []struct {
+		TermID         string      `db:"term_id"`
+		Stage          learn.Stage `db:"stage"`
+		Position       int         `db:"position"`
+		CorrectCount   int         `db:"correct_count"`
+		IncorrectCount int         `db:"incorrect_count"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		tx,
+		&rows,
+		`SELECT term_id, stage, "position", correct_count, incorrect_count
+		FROM learn_session_terms
+		WHERE learn_session_id = $1
+		ORDER BY "position"`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]learn.TermState, len(rows))
+	for i, row := range rows {
+		states[i] = learn.TermState{
+			TermID:    row.TermID,
+			Stage:     row.Stage,
+			Position:  row.Position,
+			Correct:   row.CorrectCount,
+			Incorrect: row.IncorrectCount,
+		}
+	}
+	return states, nil
+}
+
+/*
+startLearnRound saves the next round's questions for a learn session (with distractors from the session's other terms),
+or completes the session if every term is learned
+*/
+func startLearnRound(ctx context.Context, tx pgx.Tx, session *learnSessionRow) error {
+	states, err := loadLearnStates(ctx, tx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get learn session terms: %w", err)
+	}
+	if learn.Learned(states) {
+		_, err := tx.Exec(
+			ctx,
+			`UPDATE learn_sessions SET completed_at = now(), updated_at = now()
+			WHERE id = $1 AND completed_at IS NULL`,
+			session.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to complete learn session: %w", err)
+		}
+		return nil
+	}
+
+	var terms []practicetest.Term
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&terms,
+		`SELECT t.id, t.studyset_id, t.term, t.def
+		FROM learn_session_terms lst
+		JOIN terms t ON t.id = lst.term_id
+		WHERE lst.learn_session_id = $1
+		ORDER BY lst."position"`,
+		session.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get learn session terms: %w", err)
+	}
+	termsByID := make(map[string]practicetest.Term, len(terms))
+	for _, term := range terms {
+		termsByID[term.ID] = term
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	round := learn.NextRound(states, int(session.RoundSize), random)
+	placeholders := make([]string, 0, len(round))
+	args := make([]interface{}, 0, len(round)*7+2)
+	args = append(args, session.ID, session.Round+1)
+	for i, state := range round {
+		var answerWith practicetest.AnswerWith
+		if session.AnswerWith != nil {
+			answerWith = practicetest.AnswerWith(*session.AnswerWith)
+		} else {
+			answerWith = []practicetest.AnswerWith{practicetest.AnswerWithTerm, practicetest.AnswerWithDef}[random.Intn(2)]
+		}
+		q := practicetest.NewQuestion(termsByID[state.TermID], learn.QuestionType(state.Stage), answerWith, terms, random)
+
+		var data generatedQuestionData
+		if q.Type == practicetest.MCQ {
+			data.Distractors = make([]*model.TermATPInput, len(q.Distractors))
+			for j := range q.Distractors {
+				data.Distractors[j] = termATPInput(&q.Distractors[j])
+			}
+			correctChoiceIndex := int32(q.CorrectChoiceIndex)
+			data.CorrectChoiceIndex = &correctChoiceIndex
+		}
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal learn question data: %w", err)
+		}
+
+		base := len(args) + 1
+		placeholders = append(placeholders, fmt.Sprintf("($1, $2, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", base, base+1, base+2, base+3, base+4, base+5, base+6))
+		args = append(args, q.Term.ID, int32(i), string(q.Type), string(q.AnswerWith), q.Term.Term, q.Term.Def, dataBytes)
+	}
+	if len(placeholders) > 0 {
+		_, err = tx.Exec(
+			ctx,
+			fmt.Sprintf(`INSERT INTO learn_session_questions
+	(learn_session_id, round, term_id, "position", type, answer_with, term_snapshot, def_snapshot, data)
+VALUES %s`, strings.Join(placeholders, ",")),
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save learn questions: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE learn_sessions SET round = round + 1, updated_at = now() WHERE id = $1",
+		session.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update learn session: %w", err)
+	}
+	session.Round++
+	return nil
+}
+
+/*
+resumeLearnSession loads a user's learn session in tx (locking it until tx ends),
+and starts the next round if the current round doesn't have any unanswered questions
+(like if their terms were deleted). It returns nil if the session isn't found
+*/
+func resumeLearnSession(ctx context.Context, tx pgx.Tx, userID string, sessionID string) (*model.LearnSession, error) {
+	var sessions []*learnSessionRow
+	err := pgxscan.Select(
+		ctx,
+		tx,
+		&sessions,
+		`SELECT `+learnSessionColumns+`
+		FROM learn_sessions
+		WHERE id = $1 AND user_id = $2
+		FOR UPDATE`,
+		sessionID,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learn session: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	session := sessions[0]
+
+	if session.CompletedAt == nil {
+		var unanswered bool
+		err = tx.QueryRow(
+			ctx,
+			`SELECT EXISTS (
+				SELECT 1 FROM learn_session_questions
+				WHERE learn_session_id = $1 AND round = $2 AND answered_at IS NULL
+			)`,
+			session.ID,
+			session.Round,
+		).Scan(&unanswered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get learn questions: %w", err)
+		}
+		if !unanswered {
+			if err := startLearnRound(ctx, tx, session); err != nil {
+				return nil, err
+			}
+			/* reload the session for its new round, timestamps, & completion */
+			return resumeLearnSession(ctx, tx, userID, sessionID)
+		}
+	}
+	return learnSessionResult(ctx, tx, session)
+}
+
+/* learnSessionResult loads a learn session's progress and its current round's unanswered questions */
+func learnSessionResult(ctx context.Context, tx pgx.Tx, session *learnSessionRow) (*model.LearnSession, error) {
+	result := &model.LearnSession{
+		ID:          session.ID,
+		StudysetIds: session.StudysetIDs,
+		AnswerWith:  session.AnswerWith,
+		RoundSize:   session.RoundSize,
+		Round:       session.Round,
+		Completed:   session.CompletedAt != nil,
+		CreatedAt:   session.CreatedAt,
+		UpdatedAt:   session.UpdatedAt,
+		CompletedAt: session.CompletedAt,
+		Progress:    &model.LearnProgress{},
+		Questions:   []*model.GeneratedQuestion{},
+	}
+	err := tx.QueryRow(
+		ctx,
+		`SELECT count(*),
+	count(*) FILTER (WHERE stage = 'MULTIPLE_CHOICE'),
+	count(*) FILTER (WHERE stage = 'WRITTEN'),
+	count(*) FILTER (WHERE stage = 'LEARNED')
+FROM learn_session_terms
+WHERE learn_session_id = $1`,
+		session.ID,
+	).Scan(&result.Progress.Total, &result.Progress.MultipleChoice, &result.Progress.Written, &result.Progress.Learned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learn session progress: %w", err)
+	}
+
+	var rows []*learnQuestionRow
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&rows,
+		`SELECT id, term_id, type, answer_with, term_snapshot, def_snapshot, data
+		FROM learn_session_questions
+		WHERE learn_session_id = $1 AND round = $2 AND answered_at IS NULL
+		ORDER BY "position"`,
+		session.ID,
+		session.Round,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learn questions: %w", err)
+	}
+	for _, row := range rows {
+		question, err := row.generatedQuestion()
+		if err != nil {
+			return nil, err
+		}
+		result.Questions = append(result.Questions, question)
+	}
+	return result, nil
+}
+
+/* learnQuestionAnswer is a learn question being answered, with its session & term */
+type learnQuestionAnswer struct {
+	learnQuestionRow
+	SessionID  string      `db:"learn_session_id"`
+	StudysetID string      `db:"studyset_id"`
+	Stage      learn.Stage `db:"stage"`
+	Answered   bool        `db:"answered"`
+}
+
+/*
+gradeLearnQuestion grades an answer to a learn question,
+and returns the term the user picked for MCQs (nil if they didn't pick one)
+*/
+func gradeLearnQuestion(q *learnQuestionAnswer, answer model.LearnAnswerInput) (*model.LearnAnswerResult, *string, error) {
+	result := &model.LearnAnswerResult{}
+	if q.Type == string(practicetest.MCQ) {
+		var data generatedQuestionData
+		if err := json.Unmarshal(q.Data, &data); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal learn question data: %w", err)
+		}
+		if answer.AnsweredIndex == nil {
+			return nil, nil, errors.New("multiple choice questions need answeredIndex")
+		}
+		correctChoiceIndex := int32(0)
+		if data.CorrectChoiceIndex != nil {
+			correctChoiceIndex = *data.CorrectChoiceIndex
+		}
+		result.CorrectChoiceIndex = &correctChoiceIndex
+		result.Correct = *answer.AnsweredIndex == correctChoiceIndex
+
+		var answeredTermID *string
+		index := *answer.AnsweredIndex
+		switch {
+		case index == correctChoiceIndex:
+			answeredTermID = &q.TermID
+		case index >= 0 && index < correctChoiceIndex && int(index) < len(data.Distractors):
+			answeredTermID = &data.Distractors[index].ID
+		case index > correctChoiceIndex && int(index-1) < len(data.Distractors):
+			answeredTermID = &data.Distractors[index-1].ID
+		}
+		return result, answeredTermID, nil
+	}
+
+	frq := &model.FRQInput{
+		Term:           &model.TermATPInput{ID: q.TermID, Term: q.Term, Def: q.Def},
+		AnswerWith:     q.AnswerWith,
+		AnsweredString: answer.AnsweredString,
+	}
+	result.Grade = gradeFRQ(frq)
+	result.Correct = result.Grade.Correct
+	return result, nil, nil
+}
+
+/* saveLearnTermProgress adds a learn answer to the user's term progress, for the side of the term it was answered with */
+func saveLearnTermProgress(ctx context.Context, tx pgx.Tx, userID string, termID string, answerWith model.AnswerWith, correct bool, reviewedAt time.Time) error {
+	side, otherSide := "term", "def"
+	if answerWith == model.AnswerWithDef {
+		side, otherSide = "def", "term"
+	}
+	result := "incorrect"
+	if correct {
+		result = "correct"
+	}
+	_, err := tx.Exec(
+		ctx,
+		fmt.Sprintf(`INSERT INTO term_progress (
+	term_id, user_id,
+	%[1]s_first_reviewed_at, %[1]s_last_reviewed_at, %[1]s_review_count, %[3]s_review_count, %[1]s_%[2]s_count
+)
+VALUES ($1, $2, $3, $3, 1, 0, 1)
+ON CONFLICT (term_id, user_id) DO UPDATE SET
+	%[1]s_first_reviewed_at = COALESCE(term_progress.%[1]s_first_reviewed_at, EXCLUDED.%[1]s_first_reviewed_at),
+	%[1]s_last_reviewed_at = EXCLUDED.%[1]s_last_reviewed_at,
+	%[1]s_review_count = COALESCE(term_progress.%[1]s_review_count, 0) + 1,
+	%[1]s_%[2]s_count = term_progress.%[1]s_%[2]s_count + 1,
+	updated_at = now()`, side, result, otherSide),
+		termID,
+		userID,
+		reviewedAt,
+	)
+	return err
+}
@@ -0,0 +1,128 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"quizfreely/api/graph/loader"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/mastery"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/*
+termMasteryStats loads mastery.Stats for the user for each term in studysets, by term id.
+Recent answers are from review events and FSRS reviews (AGAIN is incorrect),
+terms without any are scored from their term progress counts (like from flashcards)
+*/
+func (r *Resolver) termMasteryStats(ctx context.Context, userID string, studysetIDs []string) (map[string]mastery.Stats, error) {
+	var rows []struct {
+		TermID       string     `db:"term_id"`
+		Correct      int        `db:"correct"`
+		Incorrect    int        `db:"incorrect"`
+		Stability    *float64   `db:"stability"`
+		LastReviewed *time.Time `db:"last_reviewed"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`WITH answers AS (
+	SELECT re.term_id, re.correct, re."timestamp" AS answered_at
+	FROM review_events re
+	JOIN terms t ON t.id = re.term_id
+	WHERE re.user_id = $1 AND t.studyset_id = ANY($2::uuid[])
+	UNION ALL
+	SELECT rl.term_id, rl.rating <> 'AGAIN', rl.review
+	FROM fsrs_review_logs rl
+	JOIN terms t ON t.id = rl.term_id
+	WHERE rl.user_id = $1 AND t.studyset_id = ANY($2::uuid[]) AND rl.rating <> 'MANUAL'
+	/* learn answers are already a review event */
+	AND NOT EXISTS (
+		SELECT 1 FROM review_events lre
+		WHERE lre.user_id = rl.user_id AND lre.term_id = rl.term_id AND lre."timestamp" = rl.review
+		AND lre.review_activity_type = $4
+	)
+),
+recent AS (
+	SELECT term_id, correct, answered_at,
+		row_number() OVER (PARTITION BY term_id ORDER BY answered_at DESC) AS n
+	FROM answers
+),
+recent_stats AS (
+	SELECT term_id,
+		count(*) FILTER (WHERE correct) AS correct,
+		count(*) FILTER (WHERE NOT correct) AS incorrect,
+		max(answered_at) AS last_answered_at
+	FROM recent
+	WHERE n <= $3
+	GROUP BY term_id
+)
+SELECT t.id AS term_id,
+	COALESCE(rs.correct, tp.term_correct_count + tp.def_correct_count, 0)::int AS correct,
+	COALESCE(rs.incorrect, tp.term_incorrect_count + tp.def_incorrect_count, 0)::int AS incorrect,
+	CASE WHEN fc.state <> 'NEW' THEN fc.stability END AS stability,
+	GREATEST(rs.last_answered_at, tp.term_last_reviewed_at, tp.def_last_reviewed_at, fc.last_review) AS last_reviewed
+FROM terms t
+LEFT JOIN recent_stats rs ON rs.term_id = t.id
+LEFT JOIN term_progress tp ON tp.term_id = t.id AND tp.user_id = $1
+LEFT JOIN fsrs_cards fc ON fc.term_id = t.id AND fc.user_id = $1
+WHERE t.studyset_id = ANY($2::uuid[])`,
+		userID,
+		studysetIDs,
+		mastery.RecentAnswers,
+		model.ReviewActivityTypeLearn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]mastery.Stats, len(rows))
+	for _, row := range rows {
+		stats[row.TermID] = mastery.Stats{
+			Correct:      row.Correct,
+			Incorrect:    row.Incorrect,
+			Stability:    row.Stability,
+			LastReviewed: row.LastReviewed,
+		}
+	}
+	return stats, nil
+}
+
+/* studysetTermMastery returns the user's mastery of each term in a studyset, in the studyset's order */
+func (r *Resolver) studysetTermMastery(ctx context.Context, userID string, studysetID string) ([]*model.TermMastery, []mastery.Mastery, error) {
+	terms, err := loader.GetTermsByStudysetID(ctx, studysetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats, err := r.termMasteryStats(ctx, userID, []string{studysetID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	termMastery := make([]*model.TermMastery, 0, len(terms))
+	masteries := make([]mastery.Mastery, 0, len(terms))
+	for _, term := range terms {
+		if term == nil || term.ID == nil {
+			continue
+		}
+		termStats := stats[*term.ID]
+		m := mastery.Compute(termStats, now)
+		masteries = append(masteries, m)
+
+		tm := &model.TermMastery{
+			Term:           term,
+			Level:          model.MasteryLevel(m.Level),
+			Score:          m.Score,
+			RecentAccuracy: m.Accuracy,
+		}
+		if termStats.LastReviewed != nil {
+			lastReviewedAt := termStats.LastReviewed.UTC().Format(time.RFC3339Nano)
+			tm.LastReviewedAt = &lastReviewedAt
+		}
+		termMastery = append(termMastery, tm)
+	}
+	return termMastery, masteries, nil
+}
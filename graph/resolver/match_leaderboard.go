@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"time"
+
+	"quizfreely/api/graph/model"
+)
+
+/* each incorrect pair adds this much to a match game's time on leaderboards */
+const matchIncorrectPairPenalty = time.Second
+
+/*
+matchBestGamesSQL is a CTE (best) with each user's best match game for a studyset ($1) that ended after $2 (if it isn't null).
+Games are scored by their duration plus $3 ms for each incorrect pair,
+and only games that matched every term in the studyset count
+*/
+const matchBestGamesSQL = `WITH games AS (
+	SELECT ma.id, ma.user_id, ma.duration_ms, ma.end_timestamp,
+		count(*) FILTER (WHERE NOT re.correct) AS incorrect_pairs,
+		count(DISTINCT re.term_id) FILTER (WHERE re.correct AND t.studyset_id = $1) AS matched_terms
+	FROM match_activity_studysets mas
+	JOIN match_activities ma ON ma.id = mas.match_id
+	LEFT JOIN review_events re ON re.match_activity_id = ma.id
+	LEFT JOIN terms t ON t.id = re.term_id
+	WHERE mas.studyset_id = $1
+	AND ($2::timestamptz IS NULL OR ma.end_timestamp >= $2)
+	GROUP BY ma.id
+),
+best AS (
+	SELECT DISTINCT ON (g.user_id)
+		g.user_id,
+		g.id AS match_activity_id,
+		g.duration_ms,
+		g.incorrect_pairs::int AS incorrect_pairs,
+		(g.duration_ms + g.incorrect_pairs * $3)::int AS score_ms,
+		g.end_timestamp
+	FROM games g
+	WHERE g.matched_terms > 0
+	AND g.matched_terms = (SELECT count(*) FROM terms WHERE studyset_id = $1)
+	ORDER BY g.user_id, score_ms, g.end_timestamp
+)`
+
+/* matchLeaderboardSince returns when a leaderboard period started, or nil for ALL */
+func matchLeaderboardSince(period *model.MatchLeaderboardPeriod) *time.Time {
+	var since time.Time
+	switch {
+	case period == nil || *period == model.MatchLeaderboardPeriodAll:
+		return nil
+	case *period == model.MatchLeaderboardPeriodDay:
+		since = time.Now().Add(-24 * time.Hour)
+	case *period == model.MatchLeaderboardPeriodWeek:
+		since = time.Now().AddDate(0, 0, -7)
+	}
+	return &since
+}
@@ -9,9 +9,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"quizfreely/api/achievements"
 	"quizfreely/api/auth"
+	"quizfreely/api/fsrs"
 	"quizfreely/api/graph"
+	"quizfreely/api/graph/cursor"
 	"quizfreely/api/graph/model"
+	"quizfreely/api/learn"
+	"quizfreely/api/notifications"
+	"quizfreely/api/practicetest"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -144,17 +152,41 @@ func (r *mutationResolver) CreateTerms(ctx context.Context, studysetID string, t
 		return []*model.Term{}, nil
 	}
 
-	values := make([]interface{}, 0, len(terms)*4)
+	/* image keys are from UploadTermImage or web imports with downloadImages,
+	and have to already be in the images table (foreign key),
+	so check them here instead of surfacing a foreign key violation */
+	imageKeys := make([]string, 0)
+	seenKeys := make(map[string]bool)
+	for _, t := range terms {
+		for _, key := range []*string{t.TermImageKey, t.DefImageKey} {
+			if key != nil && !seenKeys[*key] {
+				seenKeys[*key] = true
+				imageKeys = append(imageKeys, *key)
+			}
+		}
+	}
+	if len(imageKeys) > 0 {
+		var foundKeys int
+		err = tx.QueryRow(ctx, "SELECT count(*) FROM images WHERE object_key = ANY($1)", imageKeys).Scan(&foundKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check image keys: %w", err)
+		}
+		if foundKeys != len(imageKeys) {
+			return nil, fmt.Errorf("invalid image key")
+		}
+	}
+
+	values := make([]interface{}, 0, len(terms)*6+1)
 	placeholders := make([]string, 0, len(terms))
 
 	values = append(values, r.UsercontentBaseURL)
 	for i, t := range terms {
-		placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d)", i*4+2, i*4+3, i*4+4, i*4+5))
-		values = append(values, studysetID, t.Term, t.Def, t.SortOrder)
+		placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d)", i*6+2, i*6+3, i*6+4, i*6+5, i*6+6, i*6+7))
+		values = append(values, studysetID, t.Term, t.Def, t.TermImageKey, t.DefImageKey, t.SortOrder)
 	}
 
 	sql := fmt.Sprintf(`
-		INSERT INTO terms (studyset_id, term, def, sort_order)
+		INSERT INTO terms (studyset_id, term, def, term_image_key, def_image_key, sort_order)
 		VALUES %s
 		RETURNING id, term, def, ($1||term_image_key) as term_image_url, ($1||def_image_key) as def_image_url, sort_order,
 			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
@@ -321,7 +353,7 @@ func (r *mutationResolver) DeleteStudyset(ctx context.Context, id string) (*stri
 }
 
 // UpdateUser is the resolver for the updateUser field.
-func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string) (*model.AuthedUser, error) {
+func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string, hideFromLeaderboards *bool) (*model.AuthedUser, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil {
 		return nil, fmt.Errorf("not authenticated")
@@ -335,7 +367,7 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string)
 		if *displayName != trimmedDisplayName {
 			return nil, fmt.Errorf("display name must not have leading or trailing whitespace")
 		}
-	} else {
+	} else if hideFromLeaderboards == nil {
 		return authedUser, nil
 	}
 
@@ -347,8 +379,11 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string)
 
 	var updatedUser model.AuthedUser
 	err = pgxscan.Get(ctx, tx, &updatedUser,
-		`UPDATE auth.users SET display_name = $1 WHERE id = $2 RETURNING id, username, display_name`,
-		*displayName, authedUser.ID)
+		`UPDATE auth.users SET
+			display_name = COALESCE($1, display_name),
+			hide_from_leaderboards = COALESCE($3, hide_from_leaderboards)
+		WHERE id = $2 RETURNING id, username, display_name`,
+		displayName, authedUser.ID, hideFromLeaderboards)
 
 	if err != nil {
 		if pgxscan.NotFound(err) {
@@ -365,13 +400,13 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string)
 }
 
 // UpdateTermProgress is the resolver for the updateTermProgress field.
-func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termProgress []*model.TermProgressInput) ([]*model.TermProgress, error) {
+func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termProgress []*model.TermProgressInput, idempotencyKey *string) ([]*model.TermProgress, error) {
 	if len(termProgress) > MaxBatchMutationSize {
 		return nil, fmt.Errorf("too many items in a single request (max %d)", MaxBatchMutationSize)
 	}
 
 	authedUser := auth.AuthedUserContext(ctx)
-	if authedUser == nil {
+	if authedUser == nil || authedUser.ID == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 	if len(termProgress) == 0 {
@@ -384,6 +419,18 @@ func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termProgress
 	}
 	defer tx.Rollback(ctx)
 
+	replay, err := r.claimIdempotencyKey(ctx, tx, *authedUser.ID, "updateTermProgress", idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		var results []*model.TermProgress
+		if err := json.Unmarshal(replay, &results); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency result: %w", err)
+		}
+		return results, nil
+	}
+
 	// ---- Build bulk insert ----
 	valueStrings := make([]string, 0, len(termProgress))
 	valueArgs := make([]interface{}, 0, len(termProgress)*8)
@@ -449,7 +496,8 @@ func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termProgress
 			term_correct_count = term_progress.term_correct_count + EXCLUDED.term_correct_count,
 			term_incorrect_count = term_progress.term_incorrect_count + EXCLUDED.term_incorrect_count,
 			def_correct_count = term_progress.def_correct_count + EXCLUDED.def_correct_count,
-			def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count
+			def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count,
+			updated_at = now()
 		RETURNING term_progress.id,
 			to_char(term_progress.term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
 			to_char(term_progress.term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
@@ -470,30 +518,69 @@ func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termProgress
 		return nil, fmt.Errorf("some terms not found or not accessible")
 	}
 
+	if err := saveIdempotencyResult(ctx, tx, *authedUser.ID, idempotencyKey, results); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.evaluateAchievements(ctx, *authedUser.ID, achievements.EventTermProgress)
 
 	return results, nil
 }
 
 // RecordPracticeTest is the resolver for the recordPracticeTest field.
-func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.PracticeTestInput) (*model.PracticeTest, error) {
+func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.PracticeTestInput, idempotencyKey *string) (*model.PracticeTest, error) {
 	authedUser := auth.AuthedUserContext(ctx)
-	if authedUser == nil {
+	if authedUser == nil || authedUser.ID == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
+	recordedAt := time.Now()
+	if input.Timestamp != nil {
+		timestamp, err := parseClientTimestamp(*input.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		recordedAt = timestamp
+	}
+	for _, q := range input.Questions {
+		if q != nil && q.Frq != nil {
+			if err := checkAnsweredString(q.Frq.AnsweredString); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	tx, err := r.DB.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	replay, err := r.claimIdempotencyKey(ctx, tx, *authedUser.ID, "recordPracticeTest", idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		var practiceTest model.PracticeTest
+		if err := json.Unmarshal(replay, &practiceTest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency result: %w", err)
+		}
+		return &practiceTest, nil
+	}
+
+	if input.GeneratedPracticeTestID != nil {
+		if err := applyGeneratedPracticeTest(ctx, tx, *authedUser.ID, *input.GeneratedPracticeTestID, input.Questions); err != nil {
+			return nil, err
+		}
+	}
+
 	var questionsCorrect int32 = 0
 	var questionsTotal int32 = int32(len(input.Questions))
 
-	nowStr := time.Now().Format(time.RFC3339)
+	reviewedAt := recordedAt.Format(time.RFC3339)
 	termProgressMap := make(map[string]*model.TermProgressInput)
 
 	var questionRows []model.QuestionRow
@@ -549,7 +636,14 @@ func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.P
 			defStr = q.Frq.Term.Def
 			qType = "FRQ"
 			answerWith = q.Frq.AnswerWith
-			correct = q.Frq.Correct
+			/* answers the client didn't grade are graded here */
+			var grade *model.FRQGrade
+			if q.Frq.Correct != nil {
+				correct = *q.Frq.Correct
+			} else {
+				grade = gradeFRQ(q.Frq)
+				correct = grade.Correct
+			}
 			userMarkedCorrect := false
 			if q.Frq.UserMarkedCorrect != nil && *q.Frq.UserMarkedCorrect {
 				correct = true
@@ -558,6 +652,7 @@ func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.P
 			data = map[string]interface{}{
 				"answeredString":    q.Frq.AnsweredString,
 				"userMarkedCorrect": userMarkedCorrect,
+				"grade":             grade,
 			}
 			allTermIDs = append(allTermIDs, termID)
 		}
@@ -606,18 +701,18 @@ func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.P
 		if correct {
 			if answerWith == model.AnswerWithDef {
 				defCorrectIncrease = 1
-				tp.DefReviewedAt = &nowStr
+				tp.DefReviewedAt = &reviewedAt
 			} else {
 				termCorrectIncrease = 1
-				tp.TermReviewedAt = &nowStr
+				tp.TermReviewedAt = &reviewedAt
 			}
 		} else {
 			if answerWith == model.AnswerWithDef {
 				defIncorrectIncrease = 1
-				tp.DefReviewedAt = &nowStr
+				tp.DefReviewedAt = &reviewedAt
 			} else {
 				termIncorrectIncrease = 1
-				tp.TermReviewedAt = &nowStr
+				tp.TermReviewedAt = &reviewedAt
 			}
 		}
 
@@ -676,7 +771,7 @@ func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input model.P
 		&practiceTest,
 		`INSERT INTO practice_tests
 	(timestamp, user_id, questions_correct, questions_total)
-VALUES (now(), $1, $2, $3)
+VALUES ($4, $1, $2, $3)
 RETURNING
 	id,
 	to_char(timestamp, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as timestamp,
@@ -685,6 +780,7 @@ RETURNING
 		authedUser.ID,
 		questionsCorrect,
 		questionsTotal,
+		recordedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("database error in RecordPracticeTest: %w", err)
@@ -715,12 +811,13 @@ RETURNING
 		}
 
 		reviewEventPlaceholders := make([]string, len(insertedQuestions))
-		reviewEventArgs := make([]interface{}, 0, len(insertedQuestions)*9+1)
-		reviewEventArgs = append(reviewEventArgs, authedUser.ID)
+		/* review events are at the practice test's (client) timestamp, so offline tests count on the day they were taken */
+		reviewEventArgs := make([]interface{}, 0, len(insertedQuestions)*8+2)
+		reviewEventArgs = append(reviewEventArgs, authedUser.ID, recordedAt)
 
 		for i, iq := range insertedQuestions {
-			base := i*8 + 2
-			reviewEventPlaceholders[i] = fmt.Sprintf("($1, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			base := i*8 + 3
+			reviewEventPlaceholders[i] = fmt.Sprintf("($1, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $2)",
 				base, base+1, base+2, base+3, base+4, base+5, base+6, base+7)
 
 			var answeredTermID *string
@@ -774,7 +871,7 @@ RETURNING
 		reSql := fmt.Sprintf(`INSERT INTO review_events (
 			user_id, term_id, practice_test_question_id, correct, answer_with,
 			answered_term_id, practice_test_question_type, review_activity_type,
-			answered_string
+			answered_string, "timestamp"
 		) VALUES %s`, strings.Join(reviewEventPlaceholders, ","))
 
 		if _, err := tx.Exec(ctx, reSql, reviewEventArgs...); err != nil {
@@ -871,7 +968,8 @@ RETURNING
 				term_correct_count = term_progress.term_correct_count + EXCLUDED.term_correct_count,
 				term_incorrect_count = term_progress.term_incorrect_count + EXCLUDED.term_incorrect_count,
 				def_correct_count = term_progress.def_correct_count + EXCLUDED.def_correct_count,
-				def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count
+				def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count,
+				updated_at = now()
 			RETURNING term_progress.id,
 				to_char(term_progress.term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
 				to_char(term_progress.term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
@@ -889,9 +987,14 @@ RETURNING
 		}
 	}
 
+	if err := saveIdempotencyResult(ctx, tx, *authedUser.ID, idempotencyKey, practiceTest); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.evaluateAchievements(ctx, *authedUser.ID, achievements.EventPracticeTest)
 
 	return &practiceTest, nil
 }
@@ -997,7 +1100,8 @@ func (r *mutationResolver) UpdatePracticeTestQuestion(ctx context.Context, id st
 				SET term_correct_count = term_correct_count + $3,
 				    term_incorrect_count = term_incorrect_count + $4,
 				    def_correct_count = def_correct_count + $5,
-				    def_incorrect_count = def_incorrect_count + $6
+				    def_incorrect_count = def_incorrect_count + $6,
+				    updated_at = now()
 				WHERE term_id = $1 AND user_id = $2
 			`, *row.TermID, authedUser.ID, termCorrectInc, termIncorrectInc, defCorrectInc, defIncorrectInc)
 			if err != nil {
@@ -1034,6 +1138,12 @@ func (r *mutationResolver) UpdatePracticeTestQuestion(ctx context.Context, id st
 	if answeredStr, ok := data["answeredString"].(string); ok {
 		q.Frq.AnsweredString = &answeredStr
 	}
+	if grade, ok := data["grade"]; ok && grade != nil {
+		gradeBytes, err := json.Marshal(grade)
+		if err == nil {
+			_ = json.Unmarshal(gradeBytes, &q.Frq.Grade)
+		}
+	}
 
 	return q, nil
 }
@@ -1213,6 +1323,12 @@ func (r *mutationResolver) SaveStudyset(ctx context.Context, studysetID string)
 		return nil, fmt.Errorf("studyset not found, private, or is a draft")
 	}
 
+	/* who saved it isn't shown, and the save worked even if the author can't be notified */
+	_, err = notifications.NotifyStudysetOwner(ctx, r.DB, studysetID, notifications.TypeStudysetSaved, authedUser.ID, false)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to notify studyset owner about a save")
+	}
+
 	yay := true
 	return &yay, nil
 }
@@ -1247,17 +1363,32 @@ func (r *mutationResolver) SetStudysetSeoIndexing(ctx context.Context, studysetI
 	if authedUser.ModPerms == nil || !*authedUser.ModPerms {
 		return false, errors.New("missing moderator permissions needed to set studyset SEO indexing approval")
 	}
-	tag, err := r.DB.Exec(
+	var wasApproved bool
+	err := r.DB.QueryRow(
 		ctx,
-		"UPDATE studysets SET seo_indexing_approved = $1 WHERE id = $2",
+		`UPDATE studysets s SET seo_indexing_approved = $1
+		FROM (SELECT id, seo_indexing_approved FROM studysets WHERE id = $2 FOR UPDATE) old
+		WHERE s.id = old.id
+		RETURNING old.seo_indexing_approved`,
 		approved,
 		studysetID,
-	)
+	).Scan(&wasApproved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("DB Error in SetStudysetSeoIndexing")
 		return false, errors.New("DB Error in SetStudysetSeoIndexing")
 	}
-	return tag.RowsAffected() == 1, nil
+
+	/* moderators aren't shown, and the studyset is hidden even if its author can't be notified */
+	if wasApproved && !approved {
+		_, err = notifications.NotifyStudysetOwner(ctx, r.DB, studysetID, notifications.TypeStudysetHiddenByModerator, authedUser.ID, false)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to notify studyset owner about hidden studyset")
+		}
+	}
+	return true, nil
 }
 
 // UpdateFsrsCard is the resolver for the updateFsrsCard field.
@@ -1293,7 +1424,8 @@ DO UPDATE SET
     reps = EXCLUDED.reps,
     scheduled_days = EXCLUDED.scheduled_days,
     stability = EXCLUDED.stability,
-    state = EXCLUDED.state`,
+    state = EXCLUDED.state,
+    updated_at = now()`,
 		termID,
 		authedUser.ID,
 		card.Difficulty,
@@ -1352,25 +1484,540 @@ func (r *mutationResolver) RecordFsrsReviewLog(ctx context.Context, termID strin
 	return tag.RowsAffected() == 1, nil
 }
 
+// ReviewTerm is the resolver for the reviewTerm field.
+func (r *mutationResolver) ReviewTerm(ctx context.Context, termID string, rating model.FSRSRating, reviewedAt *string) (*model.FSRSCard, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+
+	fsrsRating, err := fsrs.ParseRating(rating.String())
+	if err != nil {
+		return nil, errors.New("invalid rating, reviewTerm needs AGAIN, HARD, GOOD, or EASY")
+	}
+
+	reviewTime := time.Now()
+	if reviewedAt != nil {
+		reviewTime, err = time.Parse(time.RFC3339, *reviewedAt)
+		if err != nil {
+			return nil, errors.New("invalid reviewedAt timestamp, expected RFC3339")
+		}
+		if reviewTime.After(time.Now().Add(maxReviewClockSkew)) {
+			return nil, errors.New("reviewedAt can't be in the future")
+		}
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var studysetIDs []string
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&studysetIDs,
+		`SELECT t.studyset_id FROM terms t
+		JOIN studysets s ON s.id = t.studyset_id
+		WHERE t.id = $1
+		AND ((s.draft = false AND s.private = false) OR s.user_id = $2)`,
+		termID,
+		authedUser.ID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB Error checking term in ReviewTerm")
+		return nil, errors.New("DB Error in ReviewTerm")
+	}
+	if len(studysetIDs) == 0 {
+		return nil, errors.New("term not found")
+	}
+
+	card, err := r.reviewFSRSCard(ctx, tx, *authedUser.ID, termID, studysetIDs[0], fsrsRating, reviewTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return card, nil
+}
+
+// SuspendTerm is the resolver for the suspendTerm field.
+func (r *mutationResolver) SuspendTerm(ctx context.Context, termID string) (*model.FSRSCard, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	card, err := r.updateFSRSCard(ctx, *authedUser.ID, termID, "suspended = true")
+	if err != nil {
+		log.Error().Err(err).Msg("DB error in SuspendTerm")
+		return nil, errors.New("DB error in SuspendTerm")
+	}
+	if card == nil {
+		return nil, errors.New("term not found")
+	}
+	return card, nil
+}
+
+// UnsuspendTerm is the resolver for the unsuspendTerm field.
+func (r *mutationResolver) UnsuspendTerm(ctx context.Context, termID string) (*model.FSRSCard, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	card, err := r.updateFSRSCard(ctx, *authedUser.ID, termID, "suspended = false")
+	if err != nil {
+		log.Error().Err(err).Msg("DB error in UnsuspendTerm")
+		return nil, errors.New("DB error in UnsuspendTerm")
+	}
+	if card == nil {
+		return nil, errors.New("term not found")
+	}
+	return card, nil
+}
+
+// BuryTermUntil is the resolver for the buryTermUntil field.
+func (r *mutationResolver) BuryTermUntil(ctx context.Context, termID string, until *string) (*model.FSRSCard, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	/* null until unburies the card */
+	var buriedUntil *time.Time
+	if until != nil {
+		t, err := time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return nil, errors.New("invalid until timestamp, expected RFC3339")
+		}
+		buriedUntil = &t
+	}
+	card, err := r.updateFSRSCard(ctx, *authedUser.ID, termID, "buried_until = $3", buriedUntil)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error in BuryTermUntil")
+		return nil, errors.New("DB error in BuryTermUntil")
+	}
+	if card == nil {
+		return nil, errors.New("term not found")
+	}
+	return card, nil
+}
+
+// ClearLeech is the resolver for the clearLeech field.
+func (r *mutationResolver) ClearLeech(ctx context.Context, termID string) (*model.FSRSCard, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	card, err := r.updateFSRSCard(ctx, *authedUser.ID, termID, "leech = false")
+	if err != nil {
+		log.Error().Err(err).Msg("DB error in ClearLeech")
+		return nil, errors.New("DB error in ClearLeech")
+	}
+	if card == nil {
+		return nil, errors.New("term not found")
+	}
+	return card, nil
+}
+
+// OptimizeFsrsParameters is the resolver for the optimizeFsrsParameters field.
+func (r *mutationResolver) OptimizeFsrsParameters(ctx context.Context) (*model.FSRSParameters, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	userID := *authedUser.ID
+
+	/* if it was already requested recently, this just returns the current status */
+	requested, err := fsrs.RequestOptimization(ctx, r.DB, userID)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error requesting fsrs optimization")
+		return nil, errors.New("DB error requesting fsrs optimization")
+	}
+	if requested {
+		go func() {
+			optimizeCtx, cancel := context.WithTimeout(context.Background(), fsrsOptimizationTimeout)
+			defer cancel()
+			err := fsrs.OptimizeUser(optimizeCtx, r.DB, userID)
+			if err != nil && !errors.Is(err, fsrs.ErrNotEnoughReviews) {
+				log.Error().Err(err).Msg("error optimizing fsrs parameters in OptimizeFsrsParameters")
+			}
+		}()
+	}
+
+	params, err := r.fsrsParameters(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fsrs parameters: %w", err)
+	}
+	return params, nil
+}
+
+// UpdateStudySettings is the resolver for the updateStudySettings field.
+func (r *mutationResolver) UpdateStudySettings(ctx context.Context, settings model.StudySettingsInput) (*model.StudySettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	if err := validateStudySettingsInput(settings); err != nil {
+		return nil, err
+	}
+
+	/* settings that aren't in the input are unchanged */
+	_, err := r.DB.Exec(
+		ctx,
+		`INSERT INTO study_settings (
+    user_id,
+    desired_retention,
+    max_new_cards_per_day,
+    max_reviews_per_day,
+    learning_steps_minutes,
+    relearning_steps_minutes,
+    day_rollover_hour,
+    leech_threshold
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (user_id)
+DO UPDATE SET
+    desired_retention = COALESCE(EXCLUDED.desired_retention, study_settings.desired_retention),
+    max_new_cards_per_day = COALESCE(EXCLUDED.max_new_cards_per_day, study_settings.max_new_cards_per_day),
+    max_reviews_per_day = COALESCE(EXCLUDED.max_reviews_per_day, study_settings.max_reviews_per_day),
+    learning_steps_minutes = COALESCE(EXCLUDED.learning_steps_minutes, study_settings.learning_steps_minutes),
+    relearning_steps_minutes = COALESCE(EXCLUDED.relearning_steps_minutes, study_settings.relearning_steps_minutes),
+    day_rollover_hour = COALESCE(EXCLUDED.day_rollover_hour, study_settings.day_rollover_hour),
+    leech_threshold = COALESCE(EXCLUDED.leech_threshold, study_settings.leech_threshold),
+    updated_at = now()`,
+		authedUser.ID,
+		settings.DesiredRetention,
+		settings.MaxNewCardsPerDay,
+		settings.MaxReviewsPerDay,
+		settings.LearningStepsMinutes,
+		settings.RelearningStepsMinutes,
+		settings.DayRolloverHour,
+		settings.LeechThreshold,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error updating study settings")
+		return nil, errors.New("DB error updating study settings")
+	}
+
+	result, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateStudysetStudySettings is the resolver for the updateStudysetStudySettings field.
+func (r *mutationResolver) UpdateStudysetStudySettings(ctx context.Context, studysetID string, settings model.StudysetStudySettingsInput) (*model.StudysetStudySettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	err := validateStudySettingsInput(model.StudySettingsInput{
+		DesiredRetention:       settings.DesiredRetention,
+		MaxNewCardsPerDay:      settings.MaxNewCardsPerDay,
+		MaxReviewsPerDay:       settings.MaxReviewsPerDay,
+		LearningStepsMinutes:   settings.LearningStepsMinutes,
+		RelearningStepsMinutes: settings.RelearningStepsMinutes,
+		LeechThreshold:         settings.LeechThreshold,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	/* overrides that aren't in the input are unchanged,
+	and users can only set overrides for studysets they can view */
+	var overrides []*model.StudysetStudySettings
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&overrides,
+		`INSERT INTO studyset_study_settings (
+    user_id,
+    studyset_id,
+    desired_retention,
+    max_new_cards_per_day,
+    max_reviews_per_day,
+    learning_steps_minutes,
+    relearning_steps_minutes,
+    leech_threshold
+)
+SELECT $1, s.id, $3, $4, $5, $6, $7, $8
+FROM studysets s
+WHERE s.id = $2 AND ((s.private = false AND s.draft = false) OR s.user_id = $1)
+ON CONFLICT (user_id, studyset_id)
+DO UPDATE SET
+    desired_retention = COALESCE(EXCLUDED.desired_retention, studyset_study_settings.desired_retention),
+    max_new_cards_per_day = COALESCE(EXCLUDED.max_new_cards_per_day, studyset_study_settings.max_new_cards_per_day),
+    max_reviews_per_day = COALESCE(EXCLUDED.max_reviews_per_day, studyset_study_settings.max_reviews_per_day),
+    learning_steps_minutes = COALESCE(EXCLUDED.learning_steps_minutes, studyset_study_settings.learning_steps_minutes),
+    relearning_steps_minutes = COALESCE(EXCLUDED.relearning_steps_minutes, studyset_study_settings.relearning_steps_minutes),
+    leech_threshold = COALESCE(EXCLUDED.leech_threshold, studyset_study_settings.leech_threshold),
+    updated_at = now()
+RETURNING studyset_id, desired_retention, max_new_cards_per_day, max_reviews_per_day,
+    learning_steps_minutes, relearning_steps_minutes, leech_threshold`,
+		authedUser.ID,
+		studysetID,
+		settings.DesiredRetention,
+		settings.MaxNewCardsPerDay,
+		settings.MaxReviewsPerDay,
+		settings.LearningStepsMinutes,
+		settings.RelearningStepsMinutes,
+		settings.LeechThreshold,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error updating studyset study settings")
+		return nil, errors.New("DB error updating studyset study settings")
+	}
+	if len(overrides) == 0 {
+		return nil, errors.New("studyset not found")
+	}
+	return overrides[0], nil
+}
+
+// ResetStudysetStudySettings is the resolver for the resetStudysetStudySettings field.
+func (r *mutationResolver) ResetStudysetStudySettings(ctx context.Context, studysetID string) (bool, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return false, errors.New("not authenticated")
+	}
+
+	_, err := r.DB.Exec(
+		ctx,
+		"DELETE FROM studyset_study_settings WHERE user_id = $1 AND studyset_id = $2",
+		authedUser.ID,
+		studysetID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error resetting studyset study settings")
+		return false, errors.New("DB error resetting studyset study settings")
+	}
+	return true, nil
+}
+
+// SetDailyGoal is the resolver for the setDailyGoal field.
+func (r *mutationResolver) SetDailyGoal(ctx context.Context, typeArg model.DailyGoalType, target int32) (*model.DailyGoal, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	switch typeArg {
+	case model.DailyGoalTypeReviews:
+		if target < 1 || target > MaxStudyDailyLimit {
+			return nil, fmt.Errorf("review goals must be between 1 and %d reviews", MaxStudyDailyLimit)
+		}
+	case model.DailyGoalTypeMinutes:
+		if target < 1 || target > MaxDailyGoalMinutes {
+			return nil, fmt.Errorf("minute goals must be between 1 and %d minutes", MaxDailyGoalMinutes)
+		}
+	default:
+		return nil, errors.New("invalid daily goal type")
+	}
+
+	var goal model.DailyGoal
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&goal,
+		`INSERT INTO study_settings (user_id, daily_goal_type, daily_goal)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id)
+DO UPDATE SET
+    daily_goal_type = EXCLUDED.daily_goal_type,
+    daily_goal = EXCLUDED.daily_goal,
+    updated_at = now()
+RETURNING daily_goal_type AS type, daily_goal AS target`,
+		authedUser.ID,
+		typeArg,
+		target,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error setting daily goal")
+		return nil, errors.New("DB error setting daily goal")
+	}
+	return &goal, nil
+}
+
+// UpdateReminderSettings is the resolver for the updateReminderSettings field.
+func (r *mutationResolver) UpdateReminderSettings(ctx context.Context, settings model.ReminderSettingsInput) (*model.ReminderSettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	row, err := r.reminderSettings(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+	if row == nil {
+		/* new reminders are on, in the timezone from the X-Timezone header (if it's not in settings) */
+		row = &reminderSettingsRow{
+			Enabled:        true,
+			ReminderMinute: defaultReminderMinute,
+			Timezone:       userLocation(ctx).String(),
+			Channel:        model.ReminderChannelEmail,
+			Email:          authedUser.OAuthGoogleEmail,
+		}
+	}
+	if err := applyReminderSettingsInput(row, settings); err != nil {
+		return nil, err
+	}
+	if row.Enabled {
+		switch row.Channel {
+		case model.ReminderChannelEmail:
+			if authedUser.OAuthGoogleEmail == nil || *authedUser.OAuthGoogleEmail == "" {
+				return nil, fmt.Errorf("email reminders need a verified email, sign in with google or use webhook reminders")
+			}
+		case model.ReminderChannelWebhook:
+			if row.WebhookURL == nil {
+				return nil, fmt.Errorf("webhook reminders need a webhookUrl")
+			}
+		}
+	}
+
+	_, err = r.DB.Exec(
+		ctx,
+		`INSERT INTO reminder_settings (
+	user_id, enabled, reminder_minute, timezone, channel, webhook_url
+) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE SET
+	enabled = EXCLUDED.enabled,
+	reminder_minute = EXCLUDED.reminder_minute,
+	timezone = EXCLUDED.timezone,
+	channel = EXCLUDED.channel,
+	webhook_url = EXCLUDED.webhook_url,
+	updated_at = now()`,
+		*authedUser.ID,
+		row.Enabled,
+		row.ReminderMinute,
+		row.Timezone,
+		row.Channel,
+		row.WebhookURL,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reminder settings: %w", err)
+	}
+	return row.toModel(), nil
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return 0, fmt.Errorf("not authenticated")
+	}
+	if len(ids) > MaxMarkNotificationsRead {
+		return 0, fmt.Errorf("too many notifications, max is %d", MaxMarkNotificationsRead)
+	}
+
+	/* ids is null to mark every notification read */
+	res, err := r.DB.Exec(
+		ctx,
+		`UPDATE notifications SET read_at = now()
+WHERE user_id = $1 AND read_at IS NULL
+AND ($2::uuid[] IS NULL OR id = ANY($2::uuid[]))`,
+		authedUser.ID,
+		ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int32(res.RowsAffected()), nil
+}
+
+// UpdateNotificationPreferences is the resolver for the updateNotificationPreferences field.
+func (r *mutationResolver) UpdateNotificationPreferences(ctx context.Context, preferences []*model.NotificationPreferenceInput) ([]*model.NotificationPreference, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	types := make([]string, 0, len(preferences))
+	enabled := make([]bool, 0, len(preferences))
+	seen := make(map[model.NotificationType]bool, len(preferences))
+	for _, preference := range preferences {
+		if preference == nil {
+			continue
+		}
+		if !preference.Type.IsValid() {
+			return nil, fmt.Errorf("invalid notification type")
+		}
+		if seen[preference.Type] {
+			return nil, fmt.Errorf("notification type %s is listed more than once", preference.Type)
+		}
+		seen[preference.Type] = true
+		types = append(types, string(preference.Type))
+		enabled = append(enabled, preference.Enabled)
+	}
+
+	_, err := r.DB.Exec(
+		ctx,
+		`INSERT INTO notification_preferences (user_id, type, enabled)
+SELECT $1, p.type::notification_type, p.enabled
+FROM unnest($2::text[], $3::boolean[]) AS p(type, enabled)
+ON CONFLICT (user_id, type) DO UPDATE SET
+	enabled = EXCLUDED.enabled,
+	updated_at = now()`,
+		authedUser.ID,
+		types,
+		enabled,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	updated, err := r.notificationPreferences(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return updated, nil
+}
+
 // RecordMatchActivity is the resolver for the recordMatchActivity field.
-func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.MatchActivityInput) (*model.MatchActivity, error) {
+func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.MatchActivityInput, idempotencyKey *string) (*model.MatchActivity, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil || authedUser.ID == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
+	/* durations are used for leaderboards, achievements, and study time, so they have to be realistic */
+	if input.DurationMs <= 0 || input.DurationMs > MaxMatchDurationMs {
+		return nil, fmt.Errorf("durationMs must be between 1 and %d", MaxMatchDurationMs)
+	}
+
+	endedAt := time.Now()
+	if input.EndTimestamp != nil {
+		timestamp, err := parseClientTimestamp(*input.EndTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		endedAt = timestamp
+	}
+
 	tx, err := r.DB.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	replay, err := r.claimIdempotencyKey(ctx, tx, *authedUser.ID, "recordMatchActivity", idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		var matchActivity model.MatchActivity
+		if err := json.Unmarshal(replay, &matchActivity); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency result: %w", err)
+		}
+		return &matchActivity, nil
+	}
+
 	var matchActivityID string
 	err = tx.QueryRow(ctx, `
-		INSERT INTO public.match_activities (user_id, duration_ms)
-		VALUES ($1, $2)
+		INSERT INTO public.match_activities (user_id, duration_ms, end_timestamp)
+		VALUES ($1, $2, $3)
 		RETURNING id
-	`, authedUser.ID, input.DurationMs).Scan(&matchActivityID)
+	`, authedUser.ID, input.DurationMs, endedAt).Scan(&matchActivityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert match activity: %w", err)
 	}
@@ -1405,11 +2052,12 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 	var errs []string
 	idx := 0
 	var placeholders []string
-	args := []interface{}{authedUser.ID, matchActivityID, model.ReviewActivityTypeMatch}
+	/* review events are at the match's (client) end timestamp, so offline games count on the day they were played */
+	args := []interface{}{authedUser.ID, matchActivityID, model.ReviewActivityTypeMatch, endedAt}
 
 	for _, termID := range input.TermIds {
-		base := idx*2 + 4
-		placeholders = append(placeholders, fmt.Sprintf("($1, $%d, true, NULL, $%d, $3, $2)", base, base+1))
+		base := idx*2 + 5
+		placeholders = append(placeholders, fmt.Sprintf("($1, $%d, true, NULL, $%d, $3, $2, $4)", base, base+1))
 		args = append(args, termID, termID)
 		idx++
 	}
@@ -1419,15 +2067,15 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 			errs = append(errs, "invalid incorrect pair: expected at least 2 elements")
 			continue
 		}
-		base := idx*2 + 4
-		placeholders = append(placeholders, fmt.Sprintf("($1, $%d, false, NULL, $%d, $3, $2)", base, base+1))
+		base := idx*2 + 5
+		placeholders = append(placeholders, fmt.Sprintf("($1, $%d, false, NULL, $%d, $3, $2, $4)", base, base+1))
 		args = append(args, pair[0], pair[1])
 		idx++
 	}
 
 	if len(placeholders) > 0 {
 		sql := fmt.Sprintf(`INSERT INTO review_events (
-			user_id, term_id, correct, answer_with, answered_term_id, review_activity_type, match_activity_id
+			user_id, term_id, correct, answer_with, answered_term_id, review_activity_type, match_activity_id, "timestamp"
 		) VALUES %s`, strings.Join(placeholders, ","))
 
 		if _, err := tx.Exec(ctx, sql, args...); err != nil {
@@ -1435,7 +2083,7 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 		}
 	}
 
-	nowStr := time.Now().Format(time.RFC3339)
+	reviewedAt := endedAt.Format(time.RFC3339)
 	termProgressMap := make(map[string]*model.TermProgressInput)
 
 	for _, termID := range input.TermIds {
@@ -1450,7 +2098,7 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 		} else {
 			*tp.TermCorrectIncrease += inc
 		}
-		tp.TermReviewedAt = &nowStr
+		tp.TermReviewedAt = &reviewedAt
 	}
 
 	for _, pair := range input.IncorrectPairIds {
@@ -1469,7 +2117,7 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 		} else {
 			*tp.TermIncorrectIncrease += inc
 		}
-		tp.TermReviewedAt = &nowStr
+		tp.TermReviewedAt = &reviewedAt
 	}
 
 	if len(termProgressMap) > 0 {
@@ -1539,7 +2187,8 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 				term_correct_count = term_progress.term_correct_count + EXCLUDED.term_correct_count,
 				term_incorrect_count = term_progress.term_incorrect_count + EXCLUDED.term_incorrect_count,
 				def_correct_count = term_progress.def_correct_count + EXCLUDED.def_correct_count,
-				def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count
+				def_incorrect_count = term_progress.def_incorrect_count + EXCLUDED.def_incorrect_count,
+				updated_at = now()
 			RETURNING term_progress.id,
 				to_char(term_progress.term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
 				to_char(term_progress.term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
@@ -1557,21 +2206,381 @@ func (r *mutationResolver) RecordMatchActivity(ctx context.Context, input model.
 		}
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	result := &model.MatchActivity{
 		ID:               &matchActivityID,
 		DurationMs:       input.DurationMs,
-		EndTimestamp:     time.Now().UTC().Format(time.RFC3339Nano),
+		EndTimestamp:     endedAt.UTC().Format(time.RFC3339Nano),
 		TermIds:          input.TermIds,
 		IncorrectPairIds: input.IncorrectPairIds,
 	}
+	if err := saveIdempotencyResult(ctx, tx, *authedUser.ID, idempotencyKey, result); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.evaluateAchievements(ctx, *authedUser.ID, achievements.EventMatchActivity)
 
 	if len(errs) > 0 {
-		return result, fmt.Errorf(strings.Join(errs, "; "))
+		return result, errors.New(strings.Join(errs, "; "))
+	}
+
+	return result, nil
+}
+
+// SyncStudyActivity is the resolver for the syncStudyActivity field.
+func (r *mutationResolver) SyncStudyActivity(ctx context.Context, input model.StudySyncInput) (*model.StudySyncResult, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, errors.New("not authenticated")
+	}
+	if len(input.Reviews)+len(input.TermProgress)+len(input.PracticeTests)+len(input.MatchActivities) > MaxStudySyncItems {
+		return nil, fmt.Errorf("too many items in a single sync (max %d)", MaxStudySyncItems)
+	}
+
+	var since *time.Time
+	if input.Cursor != nil {
+		t, ok := cursor.DecodeSyncCursor(*input.Cursor)
+		if !ok {
+			return nil, errors.New("invalid cursor")
+		}
+		since = &t
+	}
+
+	/* the next sync pulls changes after this (see syncCursorOverlap) */
+	var syncedAt time.Time
+	if err := r.DB.QueryRow(ctx, "SELECT now()").Scan(&syncedAt); err != nil {
+		log.Error().Err(err).Msg("DB Error getting time in SyncStudyActivity")
+		return nil, errors.New("DB Error in SyncStudyActivity")
+	}
+
+	result := &model.StudySyncResult{
+		PracticeTests:   make([]*model.PracticeTest, len(input.PracticeTests)),
+		MatchActivities: make([]*model.MatchActivity, len(input.MatchActivities)),
+		Rejected:        []*model.RejectedSyncItem{},
+	}
+
+	/* reviews are applied to each card in timestamp order by replaying its review logs when they conflict */
+	result.Rejected = append(result.Rejected, r.syncReviews(ctx, *authedUser.ID, input.Reviews)...)
+
+	/* other activity is applied oldest first, like it would've been if the client was online */
+	type syncItem struct {
+		itemType        model.SyncItemType
+		index           int
+		clientTimestamp time.Time
+	}
+	var items []syncItem
+	addItem := func(itemType model.SyncItemType, index int, clientTimestamp string) {
+		t, err := parseClientTimestamp(clientTimestamp)
+		if err != nil {
+			result.Rejected = append(result.Rejected, rejectedSyncItem(itemType, index, err))
+			return
+		}
+		items = append(items, syncItem{itemType: itemType, index: index, clientTimestamp: t})
+	}
+	for i, item := range input.TermProgress {
+		addItem(model.SyncItemTypeTermProgress, i, item.ClientTimestamp)
+	}
+	for i, item := range input.PracticeTests {
+		addItem(model.SyncItemTypePracticeTest, i, item.ClientTimestamp)
+	}
+	for i, item := range input.MatchActivities {
+		addItem(model.SyncItemTypeMatchActivity, i, item.ClientTimestamp)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].clientTimestamp.Before(items[j].clientTimestamp)
+	})
+
+	/* achievements are evaluated once after everything is applied, instead of after each item */
+	var achievementEvents []achievements.Event
+	itemCtx := deferAchievements(ctx, &achievementEvents)
+	for _, item := range items {
+		var err error
+		switch item.itemType {
+		case model.SyncItemTypeTermProgress:
+			synced := input.TermProgress[item.index]
+			_, err = r.UpdateTermProgress(itemCtx, synced.TermProgress, synced.IdempotencyKey)
+		case model.SyncItemTypePracticeTest:
+			synced := input.PracticeTests[item.index]
+			practiceTest := *synced.PracticeTest
+			if practiceTest.Timestamp == nil {
+				practiceTest.Timestamp = &synced.ClientTimestamp
+			}
+			result.PracticeTests[item.index], err = r.RecordPracticeTest(itemCtx, practiceTest, synced.IdempotencyKey)
+		case model.SyncItemTypeMatchActivity:
+			synced := input.MatchActivities[item.index]
+			matchActivity := *synced.MatchActivity
+			if matchActivity.EndTimestamp == nil {
+				matchActivity.EndTimestamp = &synced.ClientTimestamp
+			}
+			result.MatchActivities[item.index], err = r.RecordMatchActivity(itemCtx, matchActivity, synced.IdempotencyKey)
+		}
+		if err != nil {
+			result.Rejected = append(result.Rejected, rejectedSyncItem(item.itemType, item.index, err))
+		}
+	}
+
+	if len(achievementEvents) > 0 {
+		r.evaluateAchievements(ctx, *authedUser.ID, achievementEvents...)
+	}
+
+	cards, err := r.syncedCards(ctx, *authedUser.ID, since)
+	if err != nil {
+		log.Error().Err(err).Msg("DB Error getting cards in SyncStudyActivity")
+		return nil, errors.New("DB Error in SyncStudyActivity")
+	}
+	termProgress, err := r.syncedTermProgress(ctx, *authedUser.ID, since)
+	if err != nil {
+		log.Error().Err(err).Msg("DB Error getting term progress in SyncStudyActivity")
+		return nil, errors.New("DB Error in SyncStudyActivity")
+	}
+	result.Cards = cards
+	result.TermProgress = termProgress
+	result.Cursor = cursor.EncodeSyncCursor(syncedAt.Add(-syncCursorOverlap))
+	return result, nil
+}
+
+// StartLearnSession is the resolver for the startLearnSession field.
+func (r *mutationResolver) StartLearnSession(ctx context.Context, studysetIds []string, options *model.LearnSessionOptions) (*model.LearnSession, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if len(studysetIds) == 0 || len(studysetIds) > MaxLearnSessionStudysets {
+		return nil, fmt.Errorf("startLearnSession needs 1 to %d studysetIds", MaxLearnSessionStudysets)
+	}
+	roundSize := int32(learn.DefaultRoundSize)
+	var answerWith *model.AnswerWith
+	if options != nil {
+		if options.RoundSize != nil {
+			if *options.RoundSize < 1 || *options.RoundSize > MaxLearnRoundSize {
+				return nil, fmt.Errorf("roundSize must be between 1 and %d", MaxLearnRoundSize)
+			}
+			roundSize = *options.RoundSize
+		}
+		answerWith = options.AnswerWith
+	}
+
+	rows, err := r.loadPracticeTestTerms(ctx, *authedUser.ID, studysetIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms for learn session: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("studysets not found or don't have any terms")
+	}
+	/* terms are learned in the order of the studysets they're in, then their order in the studyset */
+	studysetOrder := make(map[string]int, len(studysetIds))
+	for i, id := range studysetIds {
+		if _, exists := studysetOrder[id]; !exists {
+			studysetOrder[id] = i
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b practiceTestTermRow) int {
+		return studysetOrder[a.StudysetID] - studysetOrder[b.StudysetID]
+	})
+	termIDs := make([]string, len(rows))
+	positions := make([]int32, len(rows))
+	studysetIDs := make([]string, 0, len(studysetOrder))
+	for i, row := range rows {
+		termIDs[i] = row.ID
+		positions[i] = int32(i)
+		if i == 0 || rows[i-1].StudysetID != row.StudysetID {
+			studysetIDs = append(studysetIDs, row.StudysetID)
+		}
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sessionID string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO learn_sessions (user_id, studyset_ids, answer_with, round_size)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		authedUser.ID,
+		studysetIDs,
+		answerWith,
+		roundSize,
+	).Scan(&sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save learn session: %w", err)
+	}
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO learn_session_terms (learn_session_id, term_id, "position")
+		SELECT $1, t.term_id, t.position
+		FROM unnest($2::uuid[], $3::int[]) AS t(term_id, position)`,
+		sessionID,
+		termIDs,
+		positions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save learn session terms: %w", err)
+	}
+
+	session, err := resumeLearnSession(ctx, tx, *authedUser.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return session, nil
+}
+
+// AnswerLearnQuestion is the resolver for the answerLearnQuestion field.
+func (r *mutationResolver) AnswerLearnQuestion(ctx context.Context, questionID string, answer model.LearnAnswerInput, idempotencyKey *string) (*model.LearnAnswerResult, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if err := checkAnsweredString(answer.AnsweredString); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	replay, err := r.claimIdempotencyKey(ctx, tx, *authedUser.ID, "answerLearnQuestion", idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		var result model.LearnAnswerResult
+		if err := json.Unmarshal(replay, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotency result: %w", err)
+		}
+		return &result, nil
+	}
+
+	/* the session is locked, so answers from other devices wait for this one */
+	var questions []*learnQuestionAnswer
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&questions,
+		`SELECT q.id, q.learn_session_id, q.term_id, q.type, q.answer_with, q.term_snapshot, q.def_snapshot, q.data,
+	q.answered_at IS NOT NULL AS answered, t.studyset_id, lst.stage
+FROM learn_session_questions q
+JOIN learn_sessions ls ON ls.id = q.learn_session_id
+JOIN learn_session_terms lst ON lst.learn_session_id = q.learn_session_id AND lst.term_id = q.term_id
+JOIN terms t ON t.id = q.term_id
+JOIN studysets s ON s.id = t.studyset_id
+WHERE q.id = $1 AND ls.user_id = $2
+AND ((s.private = false AND s.draft = false) OR s.user_id = $2)
+FOR UPDATE OF ls, q, lst`,
+		questionID,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learn question: %w", err)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("learn question not found")
+	}
+	q := questions[0]
+	if q.Answered {
+		return nil, fmt.Errorf("learn question already answered")
+	}
+
+	result, answeredTermID, err := gradeLearnQuestion(q, answer)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE learn_session_questions SET answered_at = $2, correct = $3 WHERE id = $1",
+		q.ID,
+		now,
+		result.Correct,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update learn question: %w", err)
+	}
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO review_events (
+			user_id, term_id, correct, answer_with, answered_term_id, practice_test_question_type,
+			review_activity_type, answered_string, learn_session_id, "timestamp"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		authedUser.ID,
+		q.TermID,
+		result.Correct,
+		q.AnswerWith,
+		answeredTermID,
+		q.Type,
+		model.ReviewActivityTypeLearn,
+		answer.AnsweredString,
+		q.SessionID,
+		now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert review event: %w", err)
+	}
+	if err := saveLearnTermProgress(ctx, tx, *authedUser.ID, q.TermID, q.AnswerWith, result.Correct, now); err != nil {
+		return nil, fmt.Errorf("failed to update term progress: %w", err)
+	}
+
+	var gradeReason practicetest.GradeReason
+	if result.Grade != nil {
+		gradeReason = practicetest.GradeReason(result.Grade.Reason)
+	}
+	rating := learn.Rating(practicetest.QuestionType(q.Type), result.Correct, gradeReason)
+	result.FsrsCard, err = r.reviewFSRSCard(ctx, tx, *authedUser.ID, q.TermID, q.StudysetID, rating, now)
+	if err != nil {
+		return nil, err
+	}
+
+	stage := learn.Advance(q.Stage, result.Correct)
+	correctIncrease, incorrectIncrease := 0, 1
+	if result.Correct {
+		correctIncrease, incorrectIncrease = 1, 0
+	}
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE learn_session_terms SET
+			stage = $3,
+			correct_count = correct_count + $4,
+			incorrect_count = incorrect_count + $5
+		WHERE learn_session_id = $1 AND term_id = $2`,
+		q.SessionID,
+		q.TermID,
+		stage,
+		correctIncrease,
+		incorrectIncrease,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update learn session term: %w", err)
+	}
+	result.Stage = model.LearnStage(stage)
+
+	_, err = tx.Exec(ctx, "UPDATE learn_sessions SET updated_at = now() WHERE id = $1", q.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update learn session: %w", err)
+	}
+	/* starts the next round if this was the round's last question */
+	result.Session, err = resumeLearnSession(ctx, tx, *authedUser.ID, q.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveIdempotencyResult(ctx, tx, *authedUser.ID, idempotencyKey, result); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.evaluateAchievements(ctx, *authedUser.ID, achievements.EventLearnAnswer)
 
 	return result, nil
 }
@@ -0,0 +1,36 @@
+package resolver
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"quizfreely/api/graph"
+	"quizfreely/api/graph/loader"
+	"quizfreely/api/graph/model"
+)
+
+// Actor is the resolver for the actor field.
+func (r *notificationResolver) Actor(ctx context.Context, obj *model.Notification) (*model.User, error) {
+	if obj.ActorUserID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.ActorUserID)
+}
+
+// Studyset is the resolver for the studyset field.
+func (r *notificationResolver) Studyset(ctx context.Context, obj *model.Notification) (*model.Studyset, error) {
+	if obj.StudysetID == nil {
+		return nil, nil
+	}
+
+	/* nil if the studyset was made private (and the user doesn't own it) */
+	return loader.GetStudysetByID(ctx, *obj.StudysetID)
+}
+
+// Notification returns graph.NotificationResolver implementation.
+func (r *Resolver) Notification() graph.NotificationResolver { return &notificationResolver{r} }
+
+type notificationResolver struct{ *Resolver }
@@ -0,0 +1,35 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/notifications"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/* notificationPreferences lists every notification type, enabled unless the user turned it off */
+func (r *Resolver) notificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	var disabled []string
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&disabled,
+		"SELECT type::text FROM notification_preferences WHERE user_id = $1 AND NOT enabled",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]*model.NotificationPreference, 0, len(notifications.Types))
+	for _, t := range notifications.Types {
+		preferences = append(preferences, &model.NotificationPreference{
+			Type:    model.NotificationType(t),
+			Enabled: !slices.Contains(disabled, string(t)),
+		})
+	}
+	return preferences, nil
+}
@@ -0,0 +1,230 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/practicetest"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/* the parts of practice_test_questions.data that retakes reuse */
+type missedQuestionData struct {
+	Distractors []*model.TermAtp `json:"distractors"`
+	Distractor  *model.TermAtp   `json:"distractor"`
+}
+
+/*
+retakeMissedQuestions makes new questions from a practice test's incorrect questions (with the same types, sides, and distractors).
+Terms & distractors have their current text, distractors the user can't view anymore have the text from when the test was taken.
+Questions for deleted terms, or terms the user can't view anymore, are skipped, because answers to them can't be recorded
+*/
+func (r *Resolver) retakeMissedQuestions(ctx context.Context, userID string, practiceTestID string, random *rand.Rand) ([]practicetest.Question, error) {
+	var missed []struct {
+		TermID     string           `db:"term_id"`
+		StudysetID string           `db:"studyset_id"`
+		Term       string           `db:"term"`
+		Def        string           `db:"def"`
+		Type       string           `db:"type"`
+		AnswerWith model.AnswerWith `db:"answer_with"`
+		Data       []byte           `db:"data"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&missed,
+		`SELECT q.term_id, t.studyset_id, q.type, q.answer_with, q.data,
+	COALESCE(t.term, '') AS term, COALESCE(t.def, '') AS def
+FROM practice_test_questions q
+JOIN terms t ON t.id = q.term_id
+JOIN studysets s ON s.id = t.studyset_id
+WHERE q.practice_test_id = $1 AND q.correct = false
+AND ((s.private = false AND s.draft = false) OR s.user_id = $2)
+ORDER BY q."position"`,
+		practiceTestID,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]practicetest.Question, len(missed))
+	var distractorIDs []string
+	distractors := make([][]*model.TermAtp, len(missed))
+	for i, m := range missed {
+		var data missedQuestionData
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal question data: %w", err)
+		}
+		switch practicetest.QuestionType(m.Type) {
+		case practicetest.MCQ:
+			distractors[i] = data.Distractors
+		case practicetest.TFQ:
+			if data.Distractor != nil {
+				distractors[i] = []*model.TermAtp{data.Distractor}
+			}
+		}
+		for _, d := range distractors[i] {
+			if d != nil && d.ID != nil {
+				distractorIDs = append(distractorIDs, *d.ID)
+			}
+		}
+		questions[i] = practicetest.Question{
+			Term: practicetest.Term{
+				ID:         m.TermID,
+				StudysetID: m.StudysetID,
+				Term:       m.Term,
+				Def:        m.Def,
+			},
+			Type:       practicetest.QuestionType(m.Type),
+			AnswerWith: practicetest.AnswerWith(m.AnswerWith),
+		}
+	}
+
+	var currentDistractors []practicetest.Term
+	if len(distractorIDs) > 0 {
+		err = pgxscan.Select(
+			ctx,
+			r.DB,
+			&currentDistractors,
+			`SELECT t.id, t.studyset_id, COALESCE(t.term, '') AS term, COALESCE(t.def, '') AS def
+			FROM terms t
+			JOIN studysets s ON s.id = t.studyset_id
+			WHERE t.id = ANY($1::uuid[])
+			AND ((s.private = false AND s.draft = false) OR s.user_id = $2)`,
+			distractorIDs,
+			userID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	current := make(map[string]practicetest.Term, len(currentDistractors))
+	for _, term := range currentDistractors {
+		current[term.ID] = term
+	}
+
+	for i := range questions {
+		for _, d := range distractors[i] {
+			if d == nil {
+				continue
+			}
+			distractor := practicetest.Term{Term: d.Term, Def: d.Def}
+			if d.ID != nil {
+				distractor.ID = *d.ID
+				if term, exists := current[*d.ID]; exists {
+					distractor = term
+				}
+			}
+			questions[i].Distractors = append(questions[i].Distractors, distractor)
+		}
+		/* the correct choice moves, so it can't be remembered from the first try */
+		if questions[i].Type == practicetest.MCQ {
+			questions[i].CorrectChoiceIndex = random.Intn(len(questions[i].Distractors) + 1)
+		}
+	}
+	return questions, nil
+}
+
+/*
+practiceTestTrend returns the scores of a user's last practice tests with questions from a studyset (oldest first),
+only counting questions for the studyset's terms
+*/
+func (r *Resolver) practiceTestTrend(ctx context.Context, userID string, studysetID string, last int32) (*model.PracticeTestTrend, error) {
+	var rows []struct {
+		PracticeTestID string                    `db:"practice_test_id"`
+		Timestamp      string                    `db:"timestamp"`
+		Type           practicetest.QuestionType `db:"type"`
+		AnswerWith     practicetest.AnswerWith   `db:"answer_with"`
+		Correct        int                       `db:"correct"`
+		Total          int                       `db:"total"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`WITH tests AS (
+	SELECT pt.id, pt."timestamp" FROM practice_tests pt
+	WHERE pt.user_id = $1
+	AND EXISTS (
+		SELECT 1 FROM practice_test_questions q
+		JOIN terms t ON t.id = q.term_id
+		WHERE q.practice_test_id = pt.id AND t.studyset_id = $2
+	)
+	ORDER BY pt."timestamp" DESC
+	LIMIT $3
+)
+SELECT tests.id AS practice_test_id,
+	to_char(tests."timestamp", 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS "timestamp",
+	q.type, q.answer_with,
+	count(*) FILTER (WHERE q.correct) AS correct,
+	count(*) AS total
+FROM tests
+JOIN practice_test_questions q ON q.practice_test_id = tests.id
+JOIN terms t ON t.id = q.term_id AND t.studyset_id = $2
+GROUP BY tests.id, tests."timestamp", q.type, q.answer_with
+ORDER BY tests."timestamp", tests.id`,
+		userID,
+		studysetID,
+		last,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	scoreRows := make([]practicetest.ScoreRow, len(rows))
+	for i, row := range rows {
+		scoreRows[i] = practicetest.ScoreRow{
+			PracticeTestID: row.PracticeTestID,
+			Timestamp:      row.Timestamp,
+			Type:           row.Type,
+			AnswerWith:     row.AnswerWith,
+			Score:          practicetest.Score{Correct: row.Correct, Total: row.Total},
+		}
+	}
+	trend := practicetest.BuildTrend(scoreRows)
+
+	result := &model.PracticeTestTrend{
+		Tests:   make([]*model.PracticeTestTrendPoint, len(trend.Tests)),
+		Overall: practiceTestScore(trend.Overall),
+	}
+	for i, point := range trend.Tests {
+		result.Tests[i] = &model.PracticeTestTrendPoint{
+			PracticeTestID: point.PracticeTestID,
+			Timestamp:      point.Timestamp,
+			Score:          practiceTestScore(point.Breakdown),
+		}
+	}
+	return result, nil
+}
+
+func practiceTestScore(b practicetest.Breakdown) *model.PracticeTestScore {
+	score := &model.PracticeTestScore{
+		QuestionsCorrect: int32(b.Correct),
+		QuestionsTotal:   int32(b.Total),
+		Score:            b.Ratio(),
+		ByQuestionType:   make([]*model.QuestionTypeScore, len(b.ByQuestionType)),
+		ByAnswerWith:     make([]*model.AnswerWithScore, len(b.ByAnswerWith)),
+	}
+	for i, s := range b.ByQuestionType {
+		score.ByQuestionType[i] = &model.QuestionTypeScore{
+			QuestionType:     model.QuestionType(s.Type),
+			QuestionsCorrect: int32(s.Correct),
+			QuestionsTotal:   int32(s.Total),
+			Score:            s.Ratio(),
+		}
+	}
+	for i, s := range b.ByAnswerWith {
+		score.ByAnswerWith[i] = &model.AnswerWithScore{
+			AnswerWith:       model.AnswerWith(s.AnswerWith),
+			QuestionsCorrect: int32(s.Correct),
+			QuestionsTotal:   int32(s.Total),
+			Score:            s.Ratio(),
+		}
+	}
+	return score
+}
@@ -8,12 +8,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"quizfreely/api/auth"
+	"quizfreely/api/fsrs"
 	"quizfreely/api/graph"
 	"quizfreely/api/graph/cursor"
 	"quizfreely/api/graph/loader"
 	"quizfreely/api/graph/model"
+	"quizfreely/api/mastery"
+	"quizfreely/api/practicetest"
 	"quizfreely/api/server/middleware"
+	"quizfreely/api/streak"
 	"time"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
@@ -155,8 +160,9 @@ func (r *practiceTestResolver) Questions(ctx context.Context, obj *model.Practic
 			}
 		case "FRQ":
 			var data struct {
-				AnsweredString    *string `json:"answeredString"`
-				UserMarkedCorrect bool    `json:"userMarkedCorrect"`
+				AnsweredString    *string         `json:"answeredString"`
+				UserMarkedCorrect bool            `json:"userMarkedCorrect"`
+				Grade             *model.FRQGrade `json:"grade"`
 			}
 			if err := json.Unmarshal(row.Data, &data); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal FRQ data: %w", err)
@@ -168,6 +174,7 @@ func (r *practiceTestResolver) Questions(ctx context.Context, obj *model.Practic
 				Correct:           row.Correct,
 				UserMarkedCorrect: &data.UserMarkedCorrect,
 				AnsweredString:    data.AnsweredString,
+				Grade:             data.Grade,
 			}
 		}
 		questions[i] = q
@@ -1671,6 +1678,882 @@ func (r *queryResolver) ActivityHistory(ctx context.Context, last int32) ([]mode
 	return activities, nil
 }
 
+// ActivityCalendar is the resolver for the activityCalendar field.
+func (r *queryResolver) ActivityCalendar(ctx context.Context, from string, to string) (*model.ActivityCalendar, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	fromDate, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+	}
+	toDate, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+	if toDate.Sub(fromDate) >= MaxActivityCalendarDays*24*time.Hour {
+		return nil, fmt.Errorf("activity calendar can't be longer than %d days", MaxActivityCalendarDays)
+	}
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	calendar, err := r.activityCalendar(ctx, *authedUser.ID, fromDate, toDate, userLocation(ctx), settings.DayRolloverHour)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity calendar: %w", err)
+	}
+	return calendar, nil
+}
+
+// DueCards is the resolver for the dueCards field.
+func (r *queryResolver) DueCards(ctx context.Context, studysetIds []string, folderID *string, limit *int32, includeNew *bool) (*model.DueCards, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if studysetIds != nil && folderID != nil {
+		return nil, fmt.Errorf("dueCards takes studysetIds or folderId, not both")
+	}
+
+	maxCards := int32(100)
+	if limit != nil {
+		maxCards = min(max(*limit, 0), MaxDueCardsLimit)
+	}
+	withNew := includeNew != nil && *includeNew
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+
+	loc := userLocation(ctx)
+	/* review cards are due for the whole study day they're due on (in the user's timezone),
+	but learning/relearning cards (with steps in minutes) are only due once their due time passes */
+	now := time.Now()
+	startOfDay, endOfDay := studyDay(now, loc, settings.DayRolloverHour)
+
+	/* review & new cards are limited by the user's daily limits (minus today's reviews/new cards),
+	and each studyset's limits (if the user overrides them), learning cards aren't limited */
+	cte := "WITH " + studyScopeCTE + `,
+today AS (
+	SELECT t.studyset_id,
+		count(*) FILTER (WHERE rl.state = 'NEW')::int AS new_cards,
+		count(*) FILTER (WHERE rl.state = 'REVIEW')::int AS reviews
+	FROM fsrs_review_logs rl
+	JOIN terms t ON t.id = rl.term_id
+	WHERE rl.user_id = $1 AND rl.review >= $6 AND rl.rating <> 'MANUAL'
+	GROUP BY t.studyset_id
+),
+studyset_limits AS (
+	SELECT scope.id AS studyset_id,
+		COALESCE(o.max_new_cards_per_day, $7::int) - COALESCE(today.new_cards, 0) AS new_left,
+		COALESCE(o.max_reviews_per_day, $8::int) - COALESCE(today.reviews, 0) AS reviews_left
+	FROM scope
+	LEFT JOIN studyset_study_settings o ON o.user_id = $1 AND o.studyset_id = scope.id
+	LEFT JOIN today ON today.studyset_id = scope.id
+),
+learning_cards AS (
+	SELECT t.id, fc.due
+	FROM fsrs_cards fc
+	JOIN terms t ON t.id = fc.term_id
+	WHERE fc.user_id = $1
+	AND fc.state IN ('LEARNING', 'RELEARNING')
+	AND fc.due <= $4
+	AND NOT fc.suspended
+	AND (fc.buried_until IS NULL OR fc.buried_until <= $4)
+	AND t.studyset_id IN (SELECT id FROM scope)
+),
+review_cards AS (
+	SELECT id, due FROM (
+		SELECT c.id, c.due, row_number() OVER (ORDER BY c.due, c.id) AS rank
+		FROM (
+			SELECT t.id, fc.due, t.studyset_id,
+				row_number() OVER (PARTITION BY t.studyset_id ORDER BY fc.due, t.id) AS studyset_rank
+			FROM fsrs_cards fc
+			JOIN terms t ON t.id = fc.term_id
+			WHERE fc.user_id = $1
+			AND fc.state = 'REVIEW'
+			AND fc.due < $5
+			AND NOT fc.suspended
+			AND (fc.buried_until IS NULL OR fc.buried_until <= $4)
+			AND t.studyset_id IN (SELECT id FROM scope)
+		) c
+		JOIN studyset_limits l ON l.studyset_id = c.studyset_id
+		WHERE c.studyset_rank <= l.reviews_left
+	) limited
+	WHERE rank <= $8::int - (SELECT COALESCE(sum(reviews), 0)::int FROM today)
+),
+new_cards AS (
+	SELECT id, studyset_id, sort_order FROM (
+		SELECT c.id, c.studyset_id, c.sort_order, row_number() OVER (ORDER BY c.studyset_id, c.sort_order) AS rank
+		FROM (
+			SELECT t.id, t.studyset_id, t.sort_order,
+				row_number() OVER (PARTITION BY t.studyset_id ORDER BY t.sort_order) AS studyset_rank
+			FROM scope
+			JOIN terms t ON t.studyset_id = scope.id
+			WHERE NOT EXISTS (
+				SELECT 1 FROM fsrs_cards fc
+				WHERE fc.term_id = t.id AND fc.user_id = $1
+				AND (fc.state <> 'NEW' OR fc.suspended OR fc.buried_until > $4)
+			)
+		) c
+		JOIN studyset_limits l ON l.studyset_id = c.studyset_id
+		WHERE c.studyset_rank <= l.new_left
+	) limited
+	WHERE rank <= $7::int - (SELECT COALESCE(sum(new_cards), 0)::int FROM today)
+)`
+
+	var dueCards model.DueCards
+	err = pgxscan.Get(
+		ctx,
+		r.DB,
+		&dueCards,
+		cte+`
+SELECT
+	(SELECT count(*) FROM review_cards)::int AS due_count,
+	(SELECT count(*) FROM learning_cards)::int AS learning_count,
+	(SELECT count(*) FROM new_cards)::int AS new_count`,
+		authedUser.ID,
+		studysetIds,
+		folderID,
+		now,
+		endOfDay,
+		startOfDay,
+		settings.MaxNewCardsPerDay,
+		settings.MaxReviewsPerDay,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count due cards: %w", err)
+	}
+
+	dueCards.Terms = []*model.Term{}
+	if maxCards == 0 {
+		return &dueCards, nil
+	}
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&dueCards.Terms,
+		cte+`
+SELECT t.id, t.studyset_id, t.term, t.def, ($11||t.term_image_key) as term_image_url, ($11||t.def_image_key) as def_image_url, t.sort_order,
+	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM (
+	SELECT id, 0 AS is_new, due, NULL::uuid AS studyset_id, 0 AS sort_order FROM review_cards
+	UNION ALL
+	SELECT id, 0 AS is_new, due, NULL::uuid AS studyset_id, 0 AS sort_order FROM learning_cards
+	UNION ALL
+	SELECT id, 1 AS is_new, NULL::timestamptz AS due, studyset_id, sort_order FROM new_cards WHERE $10
+) queue
+JOIN terms t ON t.id = queue.id
+ORDER BY queue.is_new, queue.due, queue.studyset_id, queue.sort_order
+LIMIT $9`,
+		authedUser.ID,
+		studysetIds,
+		folderID,
+		now,
+		endOfDay,
+		startOfDay,
+		settings.MaxNewCardsPerDay,
+		settings.MaxReviewsPerDay,
+		maxCards,
+		withNew,
+		r.UsercontentBaseURL,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch due cards: %w", err)
+	}
+
+	return &dueCards, nil
+}
+
+// ReviewForecast is the resolver for the reviewForecast field.
+func (r *queryResolver) ReviewForecast(ctx context.Context, days *int32, studysetIds []string, folderID *string) ([]*model.ReviewForecastDay, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if studysetIds != nil && folderID != nil {
+		return nil, fmt.Errorf("reviewForecast takes studysetIds or folderId, not both")
+	}
+
+	numDays := int32(30)
+	if days != nil {
+		numDays = min(max(*days, 1), MaxReviewForecastDays)
+	}
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	/* days are study days in the user's timezone, the first day is today (and includes overdue cards) */
+	now := time.Now()
+	startOfDay, _ := studyDay(now, userLocation(ctx), settings.DayRolloverHour)
+	dayStarts := make([]time.Time, numDays)
+	for i := range dayStarts {
+		dayStarts[i] = startOfDay.AddDate(0, 0, i)
+	}
+	end := startOfDay.AddDate(0, 0, int(numDays))
+
+	var dbCards []*dbFSRSCard
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&dbCards,
+		"WITH "+studyScopeCTE+`
+SELECT fc.difficulty, fc.due, fc.lapses, fc.last_review, fc.learning_steps, fc.reps, fc.scheduled_days, fc.stability, fc.state, fc.buried_until
+FROM fsrs_cards fc
+JOIN terms t ON t.id = fc.term_id
+WHERE fc.user_id = $1
+AND fc.state <> 'NEW'
+AND NOT fc.suspended
+AND GREATEST(fc.due, fc.buried_until) < $4
+AND t.studyset_id IN (SELECT id FROM scope)`,
+		authedUser.ID,
+		studysetIds,
+		folderID,
+		end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fsrs cards for review forecast: %w", err)
+	}
+
+	forecast := make([]*model.ReviewForecastDay, numDays)
+	for i, dayStart := range dayStarts {
+		forecast[i] = &model.ReviewForecastDay{Date: dayStart.Format("2006-01-02")}
+	}
+	/* dueCount is when cards are due now, projectedReviews also includes the reviews after those,
+	assuming cards are reviewed when they're due */
+	scheduler := r.fsrsScheduler(ctx, *authedUser.ID, settings)
+	for _, dbCard := range dbCards {
+		/* buried cards aren't shown until they're unburied */
+		if dbCard.BuriedUntil != nil && dbCard.BuriedUntil.After(dbCard.Due) {
+			dbCard.Due = *dbCard.BuriedUntil
+		}
+		if i := dayIndex(dayStarts, end, dbCard.Due); i >= 0 {
+			forecast[i].DueCount++
+		}
+		for _, review := range scheduler.ForecastReviews(dbCard.fsrsCard(), now, end) {
+			if i := dayIndex(dayStarts, end, review); i >= 0 {
+				forecast[i].ProjectedReviews++
+			}
+		}
+	}
+
+	return forecast, nil
+}
+
+// RetentionStats is the resolver for the retentionStats field.
+func (r *queryResolver) RetentionStats(ctx context.Context, studysetIds []string, folderID *string, last *int32) (*model.RetentionStats, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if studysetIds != nil && folderID != nil {
+		return nil, fmt.Errorf("retentionStats takes studysetIds or folderId, not both")
+	}
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	loc := userLocation(ctx)
+	/* only reviews in the last n study days, or all reviews if last is null */
+	var since *time.Time
+	if last != nil && *last > 0 {
+		startOfDay, _ := studyDay(time.Now(), loc, settings.DayRolloverHour)
+		s := startOfDay.AddDate(0, 0, -int(*last-1))
+		since = &s
+	}
+
+	type retentionRow struct {
+		State       model.FSRSState `db:"state"`
+		ElapsedDays *int32          `db:"elapsed_days"`
+		Reviews     int32           `db:"reviews"`
+		Recalled    int32           `db:"recalled"`
+	}
+	/* elapsed days are counted in study days (in the user's timezone),
+	and use all of a card's reviews, even if the previous review is before since */
+	var rows []retentionRow
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		"WITH "+studyScopeCTE+`,
+logs AS (
+	SELECT rl.state, rl.rating, rl.review,
+		((rl.review AT TIME ZONE $4) - make_interval(hours => $5))::date
+			- ((lag(rl.review) OVER (PARTITION BY rl.term_id ORDER BY rl.review) AT TIME ZONE $4) - make_interval(hours => $5))::date
+			AS elapsed_days
+	FROM fsrs_review_logs rl
+	JOIN terms t ON t.id = rl.term_id
+	WHERE rl.user_id = $1
+	AND rl.rating <> 'MANUAL'
+	AND t.studyset_id IN (SELECT id FROM scope)
+)
+SELECT state, elapsed_days,
+	count(*)::int AS reviews,
+	count(*) FILTER (WHERE rating <> 'AGAIN')::int AS recalled
+FROM logs
+WHERE $6::timestamptz IS NULL OR review >= $6
+GROUP BY state, elapsed_days`,
+		authedUser.ID,
+		studysetIds,
+		folderID,
+		loc.String(),
+		settings.DayRolloverHour,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch retention stats: %w", err)
+	}
+
+	stats := &model.RetentionStats{}
+	byState := map[model.FSRSState]*model.StateRetention{}
+	for _, state := range model.AllFSRSState {
+		byState[state] = &model.StateRetention{State: state}
+		stats.ByState = append(stats.ByState, byState[state])
+	}
+	for _, bucket := range retentionIntervalBuckets {
+		interval := &model.IntervalRetention{MinDays: bucket[0]}
+		if bucket[1] > 0 {
+			interval.MaxDays = &bucket[1]
+		}
+		stats.ByInterval = append(stats.ByInterval, interval)
+	}
+
+	for _, row := range rows {
+		stats.Reviews += row.Reviews
+		stats.Recalled += row.Recalled
+		if s, ok := byState[row.State]; ok {
+			s.Reviews += row.Reviews
+			s.Recalled += row.Recalled
+		}
+		/* intervals are only for reviews of cards in review, not learning steps */
+		if row.State != model.FSRSStateReview || row.ElapsedDays == nil {
+			continue
+		}
+		for _, interval := range stats.ByInterval {
+			if *row.ElapsedDays >= interval.MinDays && (interval.MaxDays == nil || *row.ElapsedDays <= *interval.MaxDays) {
+				interval.Reviews += row.Reviews
+				interval.Recalled += row.Recalled
+				break
+			}
+		}
+	}
+
+	stats.Retention = retention(stats.Reviews, stats.Recalled)
+	for _, s := range stats.ByState {
+		s.Retention = retention(s.Reviews, s.Recalled)
+	}
+	for _, interval := range stats.ByInterval {
+		interval.Retention = retention(interval.Reviews, interval.Recalled)
+	}
+	return stats, nil
+}
+
+// MyFsrsParameters is the resolver for the myFsrsParameters field.
+func (r *queryResolver) MyFsrsParameters(ctx context.Context) (*model.FSRSParameters, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	params, err := r.fsrsParameters(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fsrs parameters: %w", err)
+	}
+	return params, nil
+}
+
+// MyStudySettings is the resolver for the myStudySettings field.
+func (r *queryResolver) MyStudySettings(ctx context.Context, studysetID *string) (*model.StudySettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, studysetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	return settings, nil
+}
+
+// MyLeeches is the resolver for the myLeeches field.
+func (r *queryResolver) MyLeeches(ctx context.Context, studysetIds []string, folderID *string, first *int32) ([]*model.Term, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if studysetIds != nil && folderID != nil {
+		return nil, fmt.Errorf("myLeeches takes studysetIds or folderId, not both")
+	}
+
+	limit := int32(100)
+	if first != nil {
+		limit = min(max(*first, 0), MaxLeechesLimit)
+	}
+
+	/* most lapsed first, so the worst definitions can be rewritten first */
+	terms := []*model.Term{}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&terms,
+		"WITH "+studyScopeCTE+`
+SELECT t.id, t.studyset_id, t.term, t.def, ($5||t.term_image_key) as term_image_url, ($5||t.def_image_key) as def_image_url, t.sort_order,
+	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM fsrs_cards fc
+JOIN terms t ON t.id = fc.term_id
+WHERE fc.user_id = $1
+AND fc.leech
+AND t.studyset_id IN (SELECT id FROM scope)
+ORDER BY fc.lapses DESC, t.studyset_id, t.sort_order
+LIMIT $4`,
+		authedUser.ID,
+		studysetIds,
+		folderID,
+		limit,
+		r.UsercontentBaseURL,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch leeches: %w", err)
+	}
+
+	return terms, nil
+}
+
+// MyStudysetStudySettings is the resolver for the myStudysetStudySettings field.
+func (r *queryResolver) MyStudysetStudySettings(ctx context.Context, studysetID string) (*model.StudysetStudySettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var overrides []*model.StudysetStudySettings
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&overrides,
+		`SELECT studyset_id, desired_retention, max_new_cards_per_day, max_reviews_per_day,
+			learning_steps_minutes, relearning_steps_minutes, leech_threshold
+		FROM studyset_study_settings
+		WHERE user_id = $1 AND studyset_id = $2`,
+		authedUser.ID,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get studyset study settings: %w", err)
+	}
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+	return overrides[0], nil
+}
+
+// MyStreak is the resolver for the myStreak field.
+func (r *queryResolver) MyStreak(ctx context.Context) (*model.Streak, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	goal, err := r.dailyGoal(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily goal: %w", err)
+	}
+	loc := userLocation(ctx)
+	startOfDay, endOfDay := studyDay(time.Now(), loc, settings.DayRolloverHour)
+
+	days, err := streak.LoadStudyDays(ctx, r.DB, *authedUser.ID, loc, settings.DayRolloverHour)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get study days: %w", err)
+	}
+	today, err := r.dailyGoalProgress(ctx, *authedUser.ID, goal, startOfDay, endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily goal progress: %w", err)
+	}
+
+	s := streak.Compute(days, streak.Date(startOfDay))
+	frozenDays := make([]string, len(s.FrozenDays))
+	for i, day := range s.FrozenDays {
+		frozenDays[i] = day.Format(time.DateOnly)
+	}
+	return &model.Streak{
+		Current:      int32(s.Current),
+		Longest:      int32(s.Longest),
+		StudiedToday: today.Reviews > 0,
+		Freezes:      int32(s.Freezes),
+		MaxFreezes:   streak.MaxFreezes,
+		FrozenDays:   frozenDays,
+		Today:        today,
+	}, nil
+}
+
+// MyReminderSettings is the resolver for the myReminderSettings field.
+func (r *queryResolver) MyReminderSettings(ctx context.Context) (*model.ReminderSettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	settings, err := r.reminderSettings(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+	if settings == nil {
+		return nil, nil
+	}
+	return settings.toModel(), nil
+}
+
+// MyNotifications is the resolver for the myNotifications field.
+func (r *queryResolver) MyNotifications(ctx context.Context, first *int32, after *string, unreadOnly *bool) (*model.NotificationConnection, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 24
+	if first != nil && *first > 0 && *first < MaxNotificationsLimit {
+		l = int(*first)
+	}
+	limit := l + 1
+
+	cursorID := cursor.DecodeNotificationCursor(ptrToString(after))
+	hasPrevious := cursorID != ""
+	var cursorArg *string
+	if cursorID != "" {
+		cursorArg = &cursorID
+	}
+
+	var notifications []*model.Notification
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&notifications,
+		`SELECT n.id, n.type, n.actor_user_id, n.studyset_id, n.url,
+	n.read_at IS NOT NULL AS read,
+	to_char(n.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS created_at,
+	to_char(n.read_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS read_at
+FROM notifications n
+WHERE n.user_id = $1
+AND (NOT $2::boolean OR n.read_at IS NULL)
+AND (
+	$3::uuid IS NULL OR (n.created_at, n.id) < (
+		SELECT c.created_at, c.id FROM notifications c WHERE c.id = $3::uuid AND c.user_id = $1
+	)
+)
+ORDER BY n.created_at DESC, n.id DESC
+LIMIT $4`,
+		authedUser.ID,
+		unreadOnly != nil && *unreadOnly,
+		cursorArg,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch my notifications: %w", err)
+	}
+
+	hasNext := len(notifications) > l
+	if hasNext {
+		notifications = notifications[:l]
+	}
+	edges := make([]*model.NotificationEdge, 0, len(notifications))
+	for _, n := range notifications {
+		edges = append(edges, &model.NotificationEdge{
+			Node:   n,
+			Cursor: cursor.EncodeNotificationCursor(n.ID),
+		})
+	}
+	var startCursor, endCursor *string
+	if len(edges) > 0 {
+		startCursor = &edges[0].Cursor
+		endCursor = &edges[len(edges)-1].Cursor
+	}
+	return &model.NotificationConnection{
+		Edges: edges,
+		PageInfo: &model.PageInfo{
+			HasNextPage:     hasNext,
+			HasPreviousPage: hasPrevious,
+			StartCursor:     startCursor,
+			EndCursor:       endCursor,
+		},
+	}, nil
+}
+
+// MyUnreadNotificationCount is the resolver for the myUnreadNotificationCount field.
+func (r *queryResolver) MyUnreadNotificationCount(ctx context.Context) (int32, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return 0, fmt.Errorf("not authenticated")
+	}
+
+	var count int32
+	err := r.DB.QueryRow(
+		ctx,
+		"SELECT count(*)::int FROM notifications WHERE user_id = $1 AND read_at IS NULL",
+		authedUser.ID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MyNotificationPreferences is the resolver for the myNotificationPreferences field.
+func (r *queryResolver) MyNotificationPreferences(ctx context.Context) ([]*model.NotificationPreference, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	preferences, err := r.notificationPreferences(ctx, *authedUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// MyAchievements is the resolver for the myAchievements field.
+func (r *queryResolver) MyAchievements(ctx context.Context) ([]*model.Achievement, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var achievements []*model.Achievement
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&achievements,
+		`SELECT a.id, a.name, a.description,
+	ua.earned_at IS NOT NULL AS earned,
+	to_char(ua.earned_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS earned_at
+FROM achievements a
+LEFT JOIN user_achievements ua ON ua.achievement_id = a.id AND ua.user_id = $1
+ORDER BY ua.earned_at NULLS LAST, a.created_at, a.id`,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+	return achievements, nil
+}
+
+// LearnSession is the resolver for the learnSession field.
+func (r *queryResolver) LearnSession(ctx context.Context, id string) (*model.LearnSession, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	session, err := resumeLearnSession(ctx, tx, *authedUser.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return session, nil
+}
+
+// MyLearnSessions is the resolver for the myLearnSessions field.
+func (r *queryResolver) MyLearnSessions(ctx context.Context, includeCompleted *bool, first *int32) ([]*model.LearnSession, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	limit := int32(20)
+	if first != nil {
+		if *first < 1 || *first > MaxLearnSessionsLimit {
+			return nil, fmt.Errorf("first must be between 1 and %d", MaxLearnSessionsLimit)
+		}
+		limit = *first
+	}
+
+	var sessionIDs []string
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&sessionIDs,
+		`SELECT id FROM learn_sessions
+		WHERE user_id = $1 AND ($2 OR completed_at IS NULL)
+		ORDER BY updated_at DESC
+		LIMIT $3`,
+		authedUser.ID,
+		includeCompleted != nil && *includeCompleted,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learn sessions: %w", err)
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sessions := make([]*model.LearnSession, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		session, err := resumeLearnSession(ctx, tx, *authedUser.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		/* deleted since it was listed */
+		if session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return sessions, nil
+}
+
+// RetakeMissed is the resolver for the retakeMissed field.
+func (r *queryResolver) RetakeMissed(ctx context.Context, practiceTestID string) (*model.GeneratedPracticeTest, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var exists bool
+	err := r.DB.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM practice_tests WHERE id = $1 AND user_id = $2)",
+		practiceTestID,
+		*authedUser.ID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch practice test: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("practice test not found")
+	}
+
+	questions, err := r.retakeMissedQuestions(ctx, *authedUser.ID, practiceTestID, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch missed questions: %w", err)
+	}
+
+	studysetIDs := []string{}
+	seen := make(map[string]bool)
+	for _, question := range questions {
+		if !seen[question.Term.StudysetID] {
+			seen[question.Term.StudysetID] = true
+			studysetIDs = append(studysetIDs, question.Term.StudysetID)
+		}
+	}
+
+	generated, err := r.saveGeneratedPracticeTest(ctx, *authedUser.ID, studysetIDs, questions)
+	if err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+// GeneratePracticeTest is the resolver for the generatePracticeTest field.
+func (r *queryResolver) GeneratePracticeTest(ctx context.Context, studysetIds []string, questionTypes []model.QuestionType, count *int32, answerWith *model.AnswerWith, weighting *model.PracticeTestWeighting) (*model.GeneratedPracticeTest, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if len(studysetIds) == 0 || len(studysetIds) > MaxPracticeTestStudysets {
+		return nil, fmt.Errorf("generatePracticeTest needs 1 to %d studysetIds", MaxPracticeTestStudysets)
+	}
+	numQuestions := int32(20)
+	if count != nil {
+		if *count < 1 || *count > MaxPracticeTestQuestions {
+			return nil, fmt.Errorf("count must be between 1 and %d", MaxPracticeTestQuestions)
+		}
+		numQuestions = *count
+	}
+	testWeighting := model.PracticeTestWeightingFsrs
+	if weighting != nil {
+		testWeighting = *weighting
+	}
+
+	rows, err := r.loadPracticeTestTerms(ctx, *authedUser.ID, studysetIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms for practice test: %w", err)
+	}
+
+	var scheduler *fsrs.Scheduler
+	if testWeighting == model.PracticeTestWeightingFsrs {
+		settings, err := r.studySettings(ctx, *authedUser.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get study settings: %w", err)
+		}
+		scheduler = r.fsrsScheduler(ctx, *authedUser.ID, settings)
+	}
+	now := time.Now()
+	if testWeighting == model.PracticeTestWeightingMastery {
+		stats, err := r.termMasteryStats(ctx, *authedUser.ID, studysetIds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get term mastery: %w", err)
+		}
+		for i := range rows {
+			m := mastery.Compute(stats[rows[i].ID], now)
+			rows[i].Mastery = &m
+		}
+	}
+	terms := make([]practicetest.Term, len(rows))
+	for i := range rows {
+		terms[i] = practicetest.Term{
+			ID:         rows[i].ID,
+			StudysetID: rows[i].StudysetID,
+			Term:       rows[i].Term,
+			Def:        rows[i].Def,
+			Weight:     practiceTestWeight(&rows[i], testWeighting, answerWith, scheduler, now),
+		}
+	}
+
+	opts := practicetest.Options{Count: int(numQuestions)}
+	for _, questionType := range questionTypes {
+		opts.QuestionTypes = append(opts.QuestionTypes, practicetest.QuestionType(questionType))
+	}
+	if answerWith != nil {
+		opts.AnswerWith = practicetest.AnswerWith(*answerWith)
+	}
+	questions := practicetest.Generate(terms, opts, rand.New(rand.NewSource(now.UnixNano())))
+
+	/* only studysets the user can view */
+	visibleStudysetIDs := []string{}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if !seen[row.StudysetID] {
+			seen[row.StudysetID] = true
+			visibleStudysetIDs = append(visibleStudysetIDs, row.StudysetID)
+		}
+	}
+
+	generated, err := r.saveGeneratedPracticeTest(ctx, *authedUser.ID, visibleStudysetIDs, questions)
+	if err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
 // MatchActivity returns graph.MatchActivityResolver implementation.
 func (r *Resolver) MatchActivity() graph.MatchActivityResolver { return &matchActivityResolver{r} }
 
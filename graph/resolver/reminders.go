@@ -0,0 +1,105 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/reminders"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+/* used when users set up reminders without a time, 18:00 */
+const defaultReminderMinute = 18 * 60
+
+/* Email is the user's google email (from auth.users), reminders are never sent to addresses users type in */
+type reminderSettingsRow struct {
+	Enabled        bool                  `db:"enabled"`
+	ReminderMinute int                   `db:"reminder_minute"`
+	Timezone       string                `db:"timezone"`
+	Channel        model.ReminderChannel `db:"channel"`
+	Email          *string               `db:"email"`
+	WebhookURL     *string               `db:"webhook_url"`
+	LastSentAt     *string               `db:"last_sent_at"`
+}
+
+func (row *reminderSettingsRow) toModel() *model.ReminderSettings {
+	return &model.ReminderSettings{
+		Enabled:    row.Enabled,
+		Time:       reminders.FormatTime(row.ReminderMinute),
+		Timezone:   row.Timezone,
+		Channel:    row.Channel,
+		Email:      row.Email,
+		WebhookURL: row.WebhookURL,
+		LastSentAt: row.LastSentAt,
+	}
+}
+
+/* reminderSettings returns nil if the user hasn't set up reminders */
+func (r *Resolver) reminderSettings(ctx context.Context, userID string) (*reminderSettingsRow, error) {
+	var row reminderSettingsRow
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&row,
+		`SELECT rs.enabled, rs.reminder_minute, rs.timezone, rs.channel, u.oauth_google_email AS email, rs.webhook_url,
+	to_char(rs.last_sent_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS last_sent_at
+FROM reminder_settings rs
+JOIN auth.users u ON u.id = rs.user_id
+WHERE rs.user_id = $1`,
+		userID,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+/* applyReminderSettingsInput changes row's settings to the ones in input, an empty webhookUrl string removes it */
+func applyReminderSettingsInput(row *reminderSettingsRow, input model.ReminderSettingsInput) error {
+	if input.Enabled != nil {
+		row.Enabled = *input.Enabled
+	}
+	if input.Time != nil {
+		minute, err := reminders.ParseTime(*input.Time)
+		if err != nil {
+			return err
+		}
+		row.ReminderMinute = minute
+	}
+	if input.Timezone != nil {
+		/* "" & "Local" are valid for time.LoadLocation, but they aren't a user's timezone */
+		if *input.Timezone == "" || *input.Timezone == "Local" {
+			return errors.New("timezone must be an IANA timezone, like America/New_York")
+		}
+		if _, err := time.LoadLocation(*input.Timezone); err != nil {
+			return errors.New("timezone must be an IANA timezone, like America/New_York")
+		}
+		row.Timezone = *input.Timezone
+	}
+	if input.Channel != nil {
+		if !input.Channel.IsValid() {
+			return errors.New("invalid reminder channel")
+		}
+		row.Channel = *input.Channel
+	}
+	if input.WebhookURL != nil {
+		if *input.WebhookURL == "" {
+			row.WebhookURL = nil
+		} else {
+			u, err := url.Parse(*input.WebhookURL)
+			if err != nil || u.Scheme != "https" || u.Host == "" || len(*input.WebhookURL) > MaxReminderAddressLength {
+				return errors.New("webhookUrl must be an https url")
+			}
+			row.WebhookURL = input.WebhookURL
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ package resolver
 
 import (
 	"regexp"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,10 +18,34 @@ var validTitleRegex = regexp.MustCompile(`[\p{L}\p{M}\p{N}]`)
 
 const MaxBatchMutationSize = 9000
 const MaxFolderNameLen = 1000
+const MaxDueCardsLimit = 1000
+const MaxStudySteps = 10
+const MaxStudyDailyLimit = 9999
+const MaxReviewForecastDays = 365
+const MaxLeechesLimit = 1000
+const MaxPracticeTestQuestions = 200
+const MaxAnsweredStringLength = 2000
+const MaxPracticeTestStudysets = 100
+const MaxStudySyncItems = 1000
+const MaxDailyGoalMinutes = 1440
+const MaxMatchLeaderboardSize = 100
+
+/* match games longer than an hour are left open (not actually played), and shouldn't count as study time */
+const MaxMatchDurationMs = 60 * 60 * 1000
+const MaxActivityCalendarDays = 366
+const MaxLearnSessionStudysets = 100
+const MaxLearnRoundSize = 50
+const MaxLearnSessionsLimit = 100
+const MaxPracticeTestTrendTests = 100
+const MaxReminderAddressLength = 2048
+const MaxNotificationsLimit = 1000
+const MaxMarkNotificationsRead = 1000
 
 type Resolver struct {
 	DB                 *pgxpool.Pool
 	UsercontentBaseURL *string
+	/* how long idempotency keys can be replayed, DefaultIdempotencyKeyRetention if it's 0 */
+	IdempotencyKeyRetention time.Duration
 }
 
 func ptrToString(s *string) string {
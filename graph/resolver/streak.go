@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/streak"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/* used if users haven't set a daily goal */
+var defaultDailyGoal = model.DailyGoal{
+	Type:   model.DailyGoalTypeReviews,
+	Target: 20,
+}
+
+/* dailyGoal returns a user's daily goal, or the default if they haven't set one */
+func (r *Resolver) dailyGoal(ctx context.Context, userID string) (*model.DailyGoal, error) {
+	var goal model.DailyGoal
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&goal,
+		`SELECT
+	COALESCE(u.daily_goal_type, $2::daily_goal_type) AS type,
+	COALESCE(u.daily_goal, $3) AS target
+FROM (SELECT 1) AS one
+LEFT JOIN study_settings u ON u.user_id = $1`,
+		userID,
+		string(defaultDailyGoal.Type),
+		defaultDailyGoal.Target,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+/*
+dailyGoalProgress counts a user's reviews and study minutes between start and end (a study day).
+Study minutes are estimated from the time between reviews (see streak.StudyTime), plus match activities' durations
+(match reviews are all saved at the end of the activity, so they aren't used for the estimate).
+Learn answers are saved as a review event and an FSRS review, so their FSRS reviews are skipped
+*/
+func (r *Resolver) dailyGoalProgress(ctx context.Context, userID string, goal *model.DailyGoal, start time.Time, end time.Time) (*model.DailyGoalProgress, error) {
+	var reviews []struct {
+		ReviewedAt time.Time `db:"reviewed_at"`
+		Match      bool      `db:"match"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&reviews,
+		`SELECT "timestamp" AS reviewed_at, review_activity_type = $4 AS match
+FROM review_events
+WHERE user_id = $1 AND "timestamp" >= $2 AND "timestamp" < $3
+UNION ALL
+SELECT review AS reviewed_at, false AS match
+FROM fsrs_review_logs l
+WHERE user_id = $1 AND rating <> 'MANUAL' AND review >= $2 AND review < $3
+AND NOT EXISTS (
+	SELECT 1 FROM review_events lre
+	WHERE lre.user_id = l.user_id AND lre.term_id = l.term_id AND lre."timestamp" = l.review
+	AND lre.review_activity_type = $5
+)
+ORDER BY reviewed_at`,
+		userID,
+		start,
+		end,
+		model.ReviewActivityTypeMatch,
+		model.ReviewActivityTypeLearn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchMs int64
+	err = r.DB.QueryRow(
+		ctx,
+		`SELECT COALESCE(sum(duration_ms), 0)::bigint FROM match_activities
+		WHERE user_id = $1 AND end_timestamp >= $2 AND end_timestamp < $3`,
+		userID,
+		start,
+		end,
+	).Scan(&matchMs)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := make([]time.Time, 0, len(reviews))
+	for _, review := range reviews {
+		if !review.Match {
+			timestamps = append(timestamps, review.ReviewedAt)
+		}
+	}
+	studyTime := streak.StudyTime(timestamps) + time.Duration(matchMs)*time.Millisecond
+
+	progress := &model.DailyGoalProgress{
+		Date:    start.Format(time.DateOnly),
+		Goal:    goal,
+		Reviews: int32(len(reviews)),
+		Minutes: int32(studyTime / time.Minute),
+	}
+	done := progress.Reviews
+	if goal.Type == model.DailyGoalTypeMinutes {
+		done = progress.Minutes
+	}
+	progress.Met = done >= goal.Target
+	progress.Progress = min(float64(done)/float64(max(goal.Target, 1)), 1)
+	return progress, nil
+}
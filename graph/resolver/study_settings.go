@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/server/middleware"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/* used for settings that users (and their studyset overrides) haven't set, same as fsrs.DefaultParameters */
+var defaultStudySettings = model.StudySettings{
+	DesiredRetention:       0.9,
+	MaxNewCardsPerDay:      20,
+	MaxReviewsPerDay:       200,
+	LearningStepsMinutes:   []int32{1, 10},
+	RelearningStepsMinutes: []int32{10},
+	DayRolloverHour:        0,
+	LeechThreshold:         8,
+}
+
+/*
+studySettings returns a user's settings, with the defaults for settings they haven't set.
+If studysetID isn't nil, the user's overrides for that studyset are used too.
+*/
+func (r *Resolver) studySettings(ctx context.Context, userID string, studysetID *string) (*model.StudySettings, error) {
+	var settings model.StudySettings
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&settings,
+		`SELECT
+	COALESCE(o.desired_retention, u.desired_retention, $3) AS desired_retention,
+	COALESCE(o.max_new_cards_per_day, u.max_new_cards_per_day, $4) AS max_new_cards_per_day,
+	COALESCE(o.max_reviews_per_day, u.max_reviews_per_day, $5) AS max_reviews_per_day,
+	COALESCE(o.learning_steps_minutes, u.learning_steps_minutes, $6::int[]) AS learning_steps_minutes,
+	COALESCE(o.relearning_steps_minutes, u.relearning_steps_minutes, $7::int[]) AS relearning_steps_minutes,
+	COALESCE(u.day_rollover_hour, $8) AS day_rollover_hour,
+	COALESCE(o.leech_threshold, u.leech_threshold, $9) AS leech_threshold
+FROM (SELECT 1) AS one
+LEFT JOIN study_settings u ON u.user_id = $1
+LEFT JOIN studyset_study_settings o ON o.user_id = $1 AND o.studyset_id = $2::uuid`,
+		userID,
+		studysetID,
+		defaultStudySettings.DesiredRetention,
+		defaultStudySettings.MaxNewCardsPerDay,
+		defaultStudySettings.MaxReviewsPerDay,
+		defaultStudySettings.LearningStepsMinutes,
+		defaultStudySettings.RelearningStepsMinutes,
+		defaultStudySettings.DayRolloverHour,
+		defaultStudySettings.LeechThreshold,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func validateStudySettingsInput(input model.StudySettingsInput) error {
+	if input.DesiredRetention != nil && (*input.DesiredRetention < 0.7 || *input.DesiredRetention > 0.99) {
+		return errors.New("desiredRetention must be between 0.7 and 0.99")
+	}
+	if input.MaxNewCardsPerDay != nil && (*input.MaxNewCardsPerDay < 0 || *input.MaxNewCardsPerDay > MaxStudyDailyLimit) {
+		return fmt.Errorf("maxNewCardsPerDay must be between 0 and %d", MaxStudyDailyLimit)
+	}
+	if input.MaxReviewsPerDay != nil && (*input.MaxReviewsPerDay < 0 || *input.MaxReviewsPerDay > MaxStudyDailyLimit) {
+		return fmt.Errorf("maxReviewsPerDay must be between 0 and %d", MaxStudyDailyLimit)
+	}
+	for _, steps := range [][]int32{input.LearningStepsMinutes, input.RelearningStepsMinutes} {
+		if len(steps) > MaxStudySteps {
+			return fmt.Errorf("learning/relearning steps can't have more than %d steps", MaxStudySteps)
+		}
+		for _, step := range steps {
+			/* steps are minutes to hours, longer intervals are scheduled by fsrs */
+			if step < 1 || step > 1440 {
+				return errors.New("learning/relearning steps must be between 1 and 1440 minutes")
+			}
+		}
+	}
+	/* 0 turns off leech tagging */
+	if input.LeechThreshold != nil && (*input.LeechThreshold < 0 || *input.LeechThreshold > 99) {
+		return errors.New("leechThreshold must be between 0 and 99")
+	}
+	if input.DayRolloverHour != nil && (*input.DayRolloverHour < 0 || *input.DayRolloverHour > 23) {
+		return errors.New("dayRolloverHour must be between 0 and 23")
+	}
+	return nil
+}
+
+func stepsToDurations(minutes []int32) []time.Duration {
+	steps := make([]time.Duration, len(minutes))
+	for i, m := range minutes {
+		steps[i] = time.Duration(m) * time.Minute
+	}
+	return steps
+}
+
+/* userLocation returns the user's timezone from ctx, or UTC if it's not available */
+func userLocation(ctx context.Context) *time.Location {
+	if tzCtx := middleware.TimezoneContext(ctx); tzCtx != nil && *tzCtx != "" {
+		if loc, err := time.LoadLocation(*tzCtx); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+/*
+studyDay returns when the current study day started and ends (in loc),
+study days start at rolloverHour instead of midnight
+*/
+func studyDay(now time.Time, loc *time.Location, rolloverHour int32) (time.Time, time.Time) {
+	localNow := now.In(loc)
+	start := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), int(rolloverHour), 0, 0, 0, loc)
+	if localNow.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start, start.AddDate(0, 0, 1)
+}
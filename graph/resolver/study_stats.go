@@ -0,0 +1,32 @@
+package resolver
+
+import (
+	"sort"
+	"time"
+)
+
+/* days since the previous review, for retentionStats' byInterval (0 means no max) */
+var retentionIntervalBuckets = [][2]int32{
+	{1, 1}, {2, 3}, {4, 7}, {8, 14}, {15, 30}, {31, 90}, {91, 180}, {181, 0},
+}
+
+/* retention returns the fraction of reviews that were recalled, or nil if there weren't any */
+func retention(reviews int32, recalled int32) *float64 {
+	if reviews == 0 {
+		return nil
+	}
+	r := float64(recalled) / float64(reviews)
+	return &r
+}
+
+/*
+dayIndex returns which of days (the start of each day, in order) t is in,
+times before the first day are in the first day, and times after the last day are -1
+*/
+func dayIndex(days []time.Time, end time.Time, t time.Time) int {
+	if !t.Before(end) {
+		return -1
+	}
+	i := sort.Search(len(days), func(i int) bool { return days[i].After(t) })
+	return max(i-1, 0)
+}
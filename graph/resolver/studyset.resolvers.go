@@ -11,11 +11,18 @@ import (
 	"quizfreely/api/graph"
 	"quizfreely/api/graph/loader"
 	"quizfreely/api/graph/model"
+	"quizfreely/api/mastery"
 	"quizfreely/api/server/middleware"
+	"sort"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 )
 
+// User is the resolver for the user field.
+func (r *matchLeaderboardEntryResolver) User(ctx context.Context, obj *model.MatchLeaderboardEntry) (*model.User, error) {
+	return loader.GetUser(ctx, obj.UserID)
+}
+
 // Subject is the resolver for the subject field.
 func (r *studysetResolver) Subject(ctx context.Context, obj *model.Studyset) (*model.Subject, error) {
 	if obj.SubjectID == nil {
@@ -250,7 +257,182 @@ func (r *studysetResolver) ReviewEventStatsByDay(ctx context.Context, obj *model
 	return stats, nil
 }
 
+// MatchLeaderboard is the resolver for the matchLeaderboard field.
+func (r *studysetResolver) MatchLeaderboard(ctx context.Context, obj *model.Studyset, period *model.MatchLeaderboardPeriod, first *int32) ([]*model.MatchLeaderboardEntry, error) {
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+	limit := int32(10)
+	if first != nil {
+		if *first < 1 || *first > MaxMatchLeaderboardSize {
+			return nil, fmt.Errorf("first must be between 1 and %d", MaxMatchLeaderboardSize)
+		}
+		limit = *first
+	}
+
+	entries := []*model.MatchLeaderboardEntry{}
+	err := pgxscan.Select(ctx, r.DB, &entries, matchBestGamesSQL+`
+		SELECT rank() OVER (ORDER BY b.score_ms)::int AS rank,
+			b.user_id, b.match_activity_id, b.duration_ms, b.incorrect_pairs, b.score_ms,
+			to_char(b.end_timestamp, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS end_timestamp
+		FROM best b
+		JOIN auth.users u ON u.id = b.user_id
+		WHERE NOT u.hide_from_leaderboards
+		ORDER BY b.score_ms, b.end_timestamp
+		LIMIT $4
+	`, *obj.ID, matchLeaderboardSince(period), matchIncorrectPairPenalty.Milliseconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch match leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// MyBestMatchTime is the resolver for the myBestMatchTime field.
+func (r *studysetResolver) MyBestMatchTime(ctx context.Context, obj *model.Studyset, period *model.MatchLeaderboardPeriod) (*model.MatchLeaderboardEntry, error) {
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	/* rank is where the user's best game would be on the leaderboard, even if they're hidden from it */
+	var entry model.MatchLeaderboardEntry
+	err := pgxscan.Get(ctx, r.DB, &entry, matchBestGamesSQL+`
+		SELECT (
+				SELECT count(*) + 1 FROM best o
+				JOIN auth.users u ON u.id = o.user_id
+				WHERE NOT u.hide_from_leaderboards
+				AND o.user_id <> b.user_id
+				AND o.score_ms < b.score_ms
+			)::int AS rank,
+			b.user_id, b.match_activity_id, b.duration_ms, b.incorrect_pairs, b.score_ms,
+			to_char(b.end_timestamp, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') AS end_timestamp
+		FROM best b
+		WHERE b.user_id = $4
+	`, *obj.ID, matchLeaderboardSince(period), matchIncorrectPairPenalty.Milliseconds(), authedUser.ID)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch best match time: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// MyTermMastery is the resolver for the myTermMastery field.
+func (r *studysetResolver) MyTermMastery(ctx context.Context, obj *model.Studyset, orderBy *model.TermMasteryOrder, first *int32) ([]*model.TermMastery, error) {
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if first != nil && *first < 1 {
+		return nil, fmt.Errorf("first must be at least 1")
+	}
+
+	termMastery, _, err := r.studysetTermMastery(ctx, *authedUser.ID, *obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term mastery: %w", err)
+	}
+
+	/* new terms haven't been studied yet, so they aren't weak, they're after every studied term */
+	if orderBy != nil && *orderBy == model.TermMasteryOrderWeakest {
+		sort.SliceStable(termMastery, func(i, j int) bool {
+			iNew := termMastery[i].Level == model.MasteryLevelNew
+			jNew := termMastery[j].Level == model.MasteryLevelNew
+			if iNew != jNew {
+				return jNew
+			}
+			return termMastery[i].Score < termMastery[j].Score
+		})
+	}
+	if first != nil && int(*first) < len(termMastery) {
+		termMastery = termMastery[:*first]
+	}
+
+	return termMastery, nil
+}
+
+// MyMasteryPercent is the resolver for the myMasteryPercent field.
+func (r *studysetResolver) MyMasteryPercent(ctx context.Context, obj *model.Studyset) (*float64, error) {
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	_, masteries, err := r.studysetTermMastery(ctx, *authedUser.ID, *obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term mastery: %w", err)
+	}
+
+	percent := mastery.Percent(masteries)
+	return &percent, nil
+}
+
+// TermInsights is the resolver for the termInsights field.
+func (r *studysetResolver) TermInsights(ctx context.Context, obj *model.Studyset) (*model.TermInsights, error) {
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if obj.UserID == nil || *obj.UserID != *authedUser.ID {
+		return nil, fmt.Errorf("only the studyset's author can see term insights")
+	}
+
+	insights, err := r.termInsights(ctx, *obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term insights: %w", err)
+	}
+	return insights, nil
+}
+
+// PracticeTestTrend is the resolver for the practiceTestTrend field.
+func (r *studysetResolver) PracticeTestTrend(ctx context.Context, obj *model.Studyset, last *int32) (*model.PracticeTestTrend, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if obj == nil || obj.ID == nil {
+		return nil, fmt.Errorf("studyset not found")
+	}
+	numTests := int32(20)
+	if last != nil {
+		if *last < 1 || *last > MaxPracticeTestTrendTests {
+			return nil, fmt.Errorf("last must be between 1 and %d", MaxPracticeTestTrendTests)
+		}
+		numTests = *last
+	}
+
+	trend, err := r.practiceTestTrend(ctx, *authedUser.ID, *obj.ID, numTests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get practice test trend: %w", err)
+	}
+	return trend, nil
+}
+
+// MatchLeaderboardEntry returns graph.MatchLeaderboardEntryResolver implementation.
+func (r *Resolver) MatchLeaderboardEntry() graph.MatchLeaderboardEntryResolver {
+	return &matchLeaderboardEntryResolver{r}
+}
+
 // Studyset returns graph.StudysetResolver implementation.
 func (r *Resolver) Studyset() graph.StudysetResolver { return &studysetResolver{r} }
 
+type matchLeaderboardEntryResolver struct{ *Resolver }
 type studysetResolver struct{ *Resolver }
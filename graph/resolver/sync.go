@@ -0,0 +1,186 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"quizfreely/api/fsrs"
+	"quizfreely/api/graph/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+sync cursors are a little before the sync started, so changes from transactions that started before
+(but committed after) the sync are still pulled next time, pulling a change twice is harmless
+*/
+const syncCursorOverlap = time.Minute
+
+/* parseClientTimestamp parses an RFC3339 timestamp from a client, which can't be in the future */
+func parseClientTimestamp(timestamp string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timestamp, expected RFC3339")
+	}
+	if t.After(time.Now().Add(maxReviewClockSkew)) {
+		return time.Time{}, errors.New("timestamp can't be in the future")
+	}
+	return t, nil
+}
+
+func rejectedSyncItem(itemType model.SyncItemType, index int, err error) *model.RejectedSyncItem {
+	return &model.RejectedSyncItem{
+		Type:  itemType,
+		Index: int32(index),
+		Error: err.Error(),
+	}
+}
+
+/*
+syncReviews applies synced reviews to each term's card with replayFSRSCard.
+Each term's reviews are applied in their own transaction, so reviews for a term that can't be reviewed
+(like if its studyset was deleted or made private while the client was offline) don't reject other terms' reviews.
+*/
+func (r *Resolver) syncReviews(ctx context.Context, userID string, reviews []*model.SyncReviewInput) []*model.RejectedSyncItem {
+	var rejected []*model.RejectedSyncItem
+
+	type termReviews struct {
+		indexes []int
+		entries []fsrs.ReviewEntry
+	}
+	byTerm := make(map[string]*termReviews)
+	var termIDs []string
+	for i, review := range reviews {
+		rating, err := fsrs.ParseRating(review.Rating.String())
+		if err != nil {
+			rejected = append(rejected, rejectedSyncItem(model.SyncItemTypeReview, i, errors.New("invalid rating, reviews need AGAIN, HARD, GOOD, or EASY")))
+			continue
+		}
+		reviewedAt, err := parseClientTimestamp(review.ClientTimestamp)
+		if err != nil {
+			rejected = append(rejected, rejectedSyncItem(model.SyncItemTypeReview, i, err))
+			continue
+		}
+		t, exists := byTerm[review.TermID]
+		if !exists {
+			t = &termReviews{}
+			byTerm[review.TermID] = t
+			termIDs = append(termIDs, review.TermID)
+		}
+		t.indexes = append(t.indexes, i)
+		t.entries = append(t.entries, fsrs.ReviewEntry{Rating: rating, Review: reviewedAt})
+	}
+
+	for _, termID := range termIDs {
+		t := byTerm[termID]
+		if err := r.syncTermReviews(ctx, userID, termID, t.entries); err != nil {
+			for _, i := range t.indexes {
+				rejected = append(rejected, rejectedSyncItem(model.SyncItemTypeReview, i, err))
+			}
+		}
+	}
+	return rejected
+}
+
+func (r *Resolver) syncTermReviews(ctx context.Context, userID string, termID string, reviews []fsrs.ReviewEntry) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var studysetIDs []string
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&studysetIDs,
+		`SELECT t.studyset_id FROM terms t
+		JOIN studysets s ON s.id = t.studyset_id
+		WHERE t.id = $1
+		AND ((s.draft = false AND s.private = false) OR s.user_id = $2)`,
+		termID,
+		userID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB Error checking term in syncTermReviews")
+		return errors.New("DB Error checking term")
+	}
+	if len(studysetIDs) == 0 {
+		return errors.New("term not found")
+	}
+
+	if _, err := r.replayFSRSCard(ctx, tx, userID, termID, studysetIDs[0], reviews); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+/* syncedCards returns the user's cards changed after since, or all of their cards if since is nil */
+func (r *Resolver) syncedCards(ctx context.Context, userID string, since *time.Time) ([]*model.SyncedFSRSCard, error) {
+	var rows []*struct {
+		TermID string `db:"term_id"`
+		model.FSRSCard
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`SELECT term_id, `+fsrsCardColumns+`
+		FROM fsrs_cards
+		WHERE user_id = $1 AND ($2::timestamptz IS NULL OR updated_at > $2)
+		ORDER BY updated_at, term_id`,
+		userID,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]*model.SyncedFSRSCard, len(rows))
+	for i, row := range rows {
+		cards[i] = &model.SyncedFSRSCard{TermID: row.TermID, Card: &row.FSRSCard}
+	}
+	return cards, nil
+}
+
+/* syncedTermProgress returns the user's term progress changed after since, or all of it if since is nil */
+func (r *Resolver) syncedTermProgress(ctx context.Context, userID string, since *time.Time) ([]*model.SyncedTermProgress, error) {
+	var rows []*struct {
+		TermID string `db:"term_id"`
+		model.TermProgress
+	}
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`SELECT term_id,
+	id,
+	to_char(term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
+	to_char(term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
+	term_review_count,
+	to_char(def_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_first_reviewed_at,
+	to_char(def_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_last_reviewed_at,
+	def_review_count,
+	term_correct_count, term_incorrect_count,
+	def_correct_count, def_incorrect_count
+FROM term_progress
+WHERE user_id = $1 AND ($2::timestamptz IS NULL OR updated_at > $2)
+ORDER BY updated_at, term_id`,
+		userID,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	progress := make([]*model.SyncedTermProgress, len(rows))
+	for i, row := range rows {
+		progress[i] = &model.SyncedTermProgress{TermID: row.TermID, Progress: &row.TermProgress}
+	}
+	return progress, nil
+}
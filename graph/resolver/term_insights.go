@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"context"
+
+	"quizfreely/api/graph/loader"
+	"quizfreely/api/graph/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+/*
+term insights are only shown for terms answered by at least this many users (and common wrong answers given by this many),
+so authors can't infer an individual user's answers
+*/
+const termInsightsMinUsers = 5
+
+/* termInsights returns the term_insights rollup for each term in a studyset, in the studyset's order */
+func (r *Resolver) termInsights(ctx context.Context, studysetID string) (*model.TermInsights, error) {
+	terms, err := loader.GetTermsByStudysetID(ctx, studysetID)
+	if err != nil {
+		return nil, err
+	}
+	termsByID := make(map[string]*model.Term, len(terms))
+	for _, term := range terms {
+		if term != nil && term.ID != nil {
+			termsByID[*term.ID] = term
+		}
+	}
+
+	var rows []struct {
+		TermID            string  `db:"term_id"`
+		Users             int32   `db:"users"`
+		Attempts          int32   `db:"attempts"`
+		Incorrect         int32   `db:"incorrect"`
+		CommonWrongTermID *string `db:"common_wrong_term_id"`
+		CommonWrongString *string `db:"common_wrong_string"`
+	}
+	/* common wrong terms are only from the same studyset, other studysets could be private */
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&rows,
+		`SELECT t.id AS term_id,
+	COALESCE(ti.users, 0)::int AS users,
+	COALESCE(ti.attempts, 0)::int AS attempts,
+	COALESCE(ti.incorrect, 0)::int AS incorrect,
+	CASE WHEN ti.common_wrong_term_users >= $2 THEN wt.id END AS common_wrong_term_id,
+	CASE WHEN ti.common_wrong_string_users >= $2 THEN ti.common_wrong_string END AS common_wrong_string
+FROM terms t
+LEFT JOIN term_insights ti ON ti.term_id = t.id
+LEFT JOIN terms wt ON wt.id = ti.common_wrong_term_id AND wt.studyset_id = t.studyset_id
+WHERE t.studyset_id = $1
+ORDER BY t.sort_order`,
+		studysetID,
+		termInsightsMinUsers,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &model.TermInsights{
+		MinUsers: termInsightsMinUsers,
+		Terms:    make([]*model.TermInsight, 0, len(rows)),
+	}
+	err = r.DB.QueryRow(
+		ctx,
+		`SELECT to_char(max(refreshed_at), 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') FROM term_insights`,
+	).Scan(&insights.RefreshedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		term, exists := termsByID[row.TermID]
+		if !exists {
+			continue
+		}
+		insight := &model.TermInsight{
+			Term:       term,
+			EnoughData: row.Users >= termInsightsMinUsers,
+		}
+		if insight.EnoughData {
+			errorRate := float64(row.Incorrect) / float64(row.Attempts)
+			insight.Users = &row.Users
+			insight.Attempts = &row.Attempts
+			insight.ErrorRate = &errorRate
+			if row.CommonWrongTermID != nil {
+				insight.CommonWrongTerm = termsByID[*row.CommonWrongTermID]
+			}
+			insight.CommonWrongAnswer = row.CommonWrongString
+		}
+		insights.Terms = append(insights.Terms, insight)
+	}
+	return insights, nil
+}
@@ -15,6 +15,20 @@ import (
 	"github.com/georgysavva/scany/v2/pgxscan"
 )
 
+// HideFromLeaderboards is the resolver for the hideFromLeaderboards field.
+func (r *authedUserResolver) HideFromLeaderboards(ctx context.Context, obj *model.AuthedUser) (bool, error) {
+	if obj == nil || obj.ID == nil {
+		return false, nil
+	}
+
+	var hide bool
+	err := r.DB.QueryRow(ctx, "SELECT hide_from_leaderboards FROM auth.users WHERE id = $1", *obj.ID).Scan(&hide)
+	if err != nil {
+		return false, fmt.Errorf("failed to get leaderboard privacy: %w", err)
+	}
+	return hide, nil
+}
+
 // Studysets is the resolver for the studysets field.
 func (r *userResolver) Studysets(ctx context.Context, obj *model.User, first *int32, after *string, last *int32, before *string, includePrivate *bool) (*model.StudysetConnection, error) {
 	if obj == nil || obj.ID == nil {
@@ -164,7 +178,11 @@ func (r *userResolver) StudysetCount(ctx context.Context, obj *model.User, inclu
 	return count, nil
 }
 
+// AuthedUser returns graph.AuthedUserResolver implementation.
+func (r *Resolver) AuthedUser() graph.AuthedUserResolver { return &authedUserResolver{r} }
+
 // User returns graph.UserResolver implementation.
 func (r *Resolver) User() graph.UserResolver { return &userResolver{r} }
 
+type authedUserResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
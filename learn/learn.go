@@ -0,0 +1,124 @@
+package learn
+
+import (
+	"math/rand"
+	"slices"
+
+	"quizfreely/api/fsrs"
+	"quizfreely/api/practicetest"
+)
+
+/*
+Stage is how far a term has progressed in a learn session,
+terms are asked as MCQs until they're answered correctly, then as FRQs until they're written correctly
+*/
+type Stage string
+
+const (
+	StageMultipleChoice Stage = "MULTIPLE_CHOICE"
+	StageWritten        Stage = "WRITTEN"
+	StageLearned        Stage = "LEARNED"
+)
+
+/* rounds have up to this many terms if the session doesn't set a round size */
+const DefaultRoundSize = 7
+
+type TermState struct {
+	TermID string
+	Stage  Stage
+	/* the term's order in its studyset (and studysets' order in the session), new terms are learned in this order */
+	Position  int
+	Correct   int
+	Incorrect int
+}
+
+/* Seen reports whether the term has been answered in the session */
+func (s *TermState) Seen() bool {
+	return s.Correct+s.Incorrect > 0
+}
+
+/* QuestionType returns the type of question a term at stage is asked with */
+func QuestionType(stage Stage) practicetest.QuestionType {
+	if stage == StageWritten {
+		return practicetest.FRQ
+	}
+	return practicetest.MCQ
+}
+
+/*
+Advance returns a term's stage after answering a question for it at stage,
+correct answers move it forward, and wrong written answers move it back to MCQs
+*/
+func Advance(stage Stage, correct bool) Stage {
+	switch stage {
+	case StageMultipleChoice:
+		if correct {
+			return StageWritten
+		}
+		return StageMultipleChoice
+	case StageWritten:
+		if correct {
+			return StageLearned
+		}
+		return StageMultipleChoice
+	default:
+		return stage
+	}
+}
+
+/*
+Rating returns the FSRS rating for an answer: wrong answers are Again,
+MCQs only need the answer to be recognized so they're Hard, and written answers are Good (or Hard if they had a typo).
+reason is only used for FRQs
+*/
+func Rating(questionType practicetest.QuestionType, correct bool, reason practicetest.GradeReason) fsrs.Rating {
+	if !correct {
+		return fsrs.Again
+	}
+	if questionType != practicetest.FRQ || reason == practicetest.GradeTypo {
+		return fsrs.Hard
+	}
+	return fsrs.Good
+}
+
+/*
+NextRound picks up to size terms for the next round, in random order.
+Terms that have been seen (but aren't learned yet) are picked first, so they're finished before new terms are started,
+then new terms are picked in order of their Position
+*/
+func NextRound(states []TermState, size int, random *rand.Rand) []TermState {
+	var seen, unseen []TermState
+	for _, state := range states {
+		if state.Stage == StageLearned {
+			continue
+		}
+		if state.Seen() {
+			seen = append(seen, state)
+		} else {
+			unseen = append(unseen, state)
+		}
+	}
+	random.Shuffle(len(seen), func(i, j int) {
+		seen[i], seen[j] = seen[j], seen[i]
+	})
+	slices.SortStableFunc(unseen, func(a, b TermState) int {
+		return a.Position - b.Position
+	})
+
+	round := append(seen, unseen...)
+	round = round[:min(max(size, 1), len(round))]
+	random.Shuffle(len(round), func(i, j int) {
+		round[i], round[j] = round[j], round[i]
+	})
+	return round
+}
+
+/* Learned reports whether every term in the session is learned */
+func Learned(states []TermState) bool {
+	for _, state := range states {
+		if state.Stage != StageLearned {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package learn
+
+import (
+	"math/rand"
+	"testing"
+
+	"quizfreely/api/fsrs"
+	"quizfreely/api/practicetest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvance(t *testing.T) {
+	require.Equal(t, StageWritten, Advance(StageMultipleChoice, true))
+	require.Equal(t, StageMultipleChoice, Advance(StageMultipleChoice, false))
+	require.Equal(t, StageLearned, Advance(StageWritten, true))
+	/* wrong written answers go back to MCQs */
+	require.Equal(t, StageMultipleChoice, Advance(StageWritten, false))
+	require.Equal(t, StageLearned, Advance(StageLearned, false))
+
+	require.Equal(t, practicetest.MCQ, QuestionType(StageMultipleChoice))
+	require.Equal(t, practicetest.FRQ, QuestionType(StageWritten))
+}
+
+func TestRating(t *testing.T) {
+	require.Equal(t, fsrs.Again, Rating(practicetest.MCQ, false, ""))
+	require.Equal(t, fsrs.Hard, Rating(practicetest.MCQ, true, ""))
+	require.Equal(t, fsrs.Again, Rating(practicetest.FRQ, false, practicetest.GradeIncorrect))
+	require.Equal(t, fsrs.Hard, Rating(practicetest.FRQ, true, practicetest.GradeTypo))
+	require.Equal(t, fsrs.Good, Rating(practicetest.FRQ, true, practicetest.GradeNormalized))
+	require.Equal(t, fsrs.Good, Rating(practicetest.FRQ, true, practicetest.GradeExact))
+}
+
+func TestNextRound(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	states := []TermState{
+		{TermID: "new2", Stage: StageMultipleChoice, Position: 2},
+		{TermID: "learned", Stage: StageLearned, Position: 0, Correct: 2},
+		{TermID: "new1", Stage: StageMultipleChoice, Position: 1},
+		{TermID: "written", Stage: StageWritten, Position: 5, Correct: 1},
+		{TermID: "missed", Stage: StageMultipleChoice, Position: 4, Incorrect: 1},
+		{TermID: "new3", Stage: StageMultipleChoice, Position: 3},
+	}
+
+	/* seen terms first, then new terms in order */
+	ids := func(round []TermState) []string {
+		result := make([]string, len(round))
+		for i, state := range round {
+			result[i] = state.TermID
+		}
+		return result
+	}
+	require.ElementsMatch(t, []string{"written", "missed", "new1"}, ids(NextRound(states, 3, random)))
+	require.ElementsMatch(t, []string{"written", "missed", "new1", "new2", "new3"}, ids(NextRound(states, 10, random)))
+	require.Len(t, NextRound(states, 0, random), 1)
+
+	require.False(t, Learned(states))
+	require.Empty(t, NextRound([]TermState{{TermID: "learned", Stage: StageLearned}}, 3, random))
+	require.True(t, Learned([]TermState{{TermID: "learned", Stage: StageLearned}}))
+}
@@ -10,7 +10,11 @@ import (
 	"strconv"
 	"time"
 
+	"quizfreely/api/achievements"
 	qzfrAPIConfig "quizfreely/api/config"
+	"quizfreely/api/fsrs"
+	"quizfreely/api/graph/resolver"
+	"quizfreely/api/reminders"
 	"quizfreely/api/server"
 	"quizfreely/api/storage"
 
@@ -108,6 +112,45 @@ func main() {
 	c.AddFunc(config.TermImageCleanupCronSpec, func() {
 		termImageCleanupJob(dbPool, s3Client, config.UsercontentBucket)
 	})
+	if config.EnableWebImport && config.WebImportJobCleanupCronSpec != "" {
+		c.AddFunc(config.WebImportJobCleanupCronSpec, func() {
+			webImportJobCleanupJob(dbPool, config.WebImportCacheTTL)
+		})
+	}
+	if config.FSRSOptimizerCronSpec != "" {
+		c.AddFunc(config.FSRSOptimizerCronSpec, func() {
+			fsrsOptimizerJob(dbPool)
+		})
+	}
+	if config.IdempotencyKeyCleanupCronSpec != "" {
+		c.AddFunc(config.IdempotencyKeyCleanupCronSpec, func() {
+			idempotencyKeyCleanupJob(dbPool, config.IdempotencyKeyRetention)
+		})
+	}
+	if config.GeneratedPTCleanupCronSpec != "" {
+		c.AddFunc(config.GeneratedPTCleanupCronSpec, func() {
+			generatedPracticeTestCleanupJob(dbPool)
+		})
+	}
+	if config.AchievementsBackfillCronSpec != "" {
+		c.AddFunc(config.AchievementsBackfillCronSpec, func() {
+			achievementsBackfillJob(dbPool)
+		})
+	}
+	if config.TermInsightsRefreshCronSpec != "" {
+		c.AddFunc(config.TermInsightsRefreshCronSpec, func() {
+			termInsightsRefreshJob(dbPool)
+		})
+	}
+	if config.ReminderCronSpec != "" {
+		notifiers, err := reminders.NotifiersFromConfig(config)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid reminder config. check config.toml")
+		}
+		c.AddFunc(config.ReminderCronSpec, func() {
+			reminderJob(dbPool, notifiers, config.ReminderUnsubscribeURL)
+		})
+	}
 	c.Start()
 	/* start cron jobs BEFORE starting server because http.ListenAndServe (below) is blocking */
 
@@ -133,6 +176,191 @@ func sessionCleanupJob(dbPool *pgxpool.Pool) {
 	}
 }
 
+func webImportJobCleanupJob(dbPool *pgxpool.Pool, cacheTTLMinutes int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	log.Info().Msg("Running webImportJobCleanupJob")
+	/* keep jobs for at least a day, even if caching is disabled,
+	so clients polling a job's status can still get the result */
+	_, err := dbPool.Exec(
+		ctx,
+		`DELETE FROM web_import_jobs
+		WHERE status IN ('SUCCEEDED', 'FAILED')
+		AND finished_at < now() - make_interval(mins => greatest($1, 1440))`,
+		cacheTTLMinutes,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clean up old web import jobs")
+	}
+}
+
+func idempotencyKeyCleanupJob(dbPool *pgxpool.Pool, retentionHours int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	retention := resolver.DefaultIdempotencyKeyRetention
+	if retentionHours > 0 {
+		retention = time.Duration(retentionHours) * time.Hour
+	}
+	log.Info().Msg("Running idempotencyKeyCleanupJob")
+	_, err := dbPool.Exec(
+		ctx,
+		"DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)",
+		retention.Seconds(),
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clean up expired idempotency keys")
+	}
+}
+
+func generatedPracticeTestCleanupJob(dbPool *pgxpool.Pool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	log.Info().Msg("Running generatedPracticeTestCleanupJob")
+	/* expired tests can't be submitted anymore, and submitted ones are already saved as practice tests,
+	their questions are deleted with them (ON DELETE CASCADE) */
+	_, err := dbPool.Exec(ctx, "DELETE FROM generated_practice_tests WHERE expires_at < now()")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clean up expired generated practice tests")
+	}
+}
+
+func termInsightsRefreshJob(dbPool *pgxpool.Pool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	log.Info().Msg("Running termInsightsRefreshJob")
+	_, err := dbPool.Exec(ctx, "SELECT refresh_term_insights()")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to refresh term insights")
+	}
+}
+
+func reminderJob(dbPool *pgxpool.Pool, notifiers reminders.Notifiers, unsubscribeURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	log.Info().Msg("Running reminderJob")
+	sent, err := reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to send study reminders")
+	}
+	log.Info().Int("sent", sent).Msg("Sent study reminders")
+}
+
+/*
+achievementsBackfillJob evaluates every achievement for users who've studied and haven't earned all of them,
+for history from before achievements (or new achievements) and activity that isn't evaluated when it's recorded.
+Study days for streaks use the timezone from users' reminder settings and their day rollover hour (like requests do),
+or UTC for users without reminder settings
+*/
+func achievementsBackfillJob(dbPool *pgxpool.Pool) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	log.Info().Msg("Running achievementsBackfillJob")
+	var users []struct {
+		ID           string  `db:"id"`
+		RolloverHour int32   `db:"day_rollover_hour"`
+		Timezone     *string `db:"timezone"`
+	}
+	err := pgxscan.Select(
+		ctx,
+		dbPool,
+		&users,
+		`SELECT u.id, COALESCE(s.day_rollover_hour, 0) AS day_rollover_hour, rs.timezone
+		FROM auth.users u
+		LEFT JOIN study_settings s ON s.user_id = u.id
+		LEFT JOIN reminder_settings rs ON rs.user_id = u.id
+		WHERE (
+			EXISTS (SELECT 1 FROM term_progress tp WHERE tp.user_id = u.id)
+			OR EXISTS (SELECT 1 FROM practice_tests pt WHERE pt.user_id = u.id)
+			OR EXISTS (SELECT 1 FROM match_activities m WHERE m.user_id = u.id)
+			OR EXISTS (SELECT 1 FROM fsrs_review_logs rl WHERE rl.user_id = u.id)
+		)
+		AND EXISTS (
+			SELECT 1 FROM achievements a
+			WHERE NOT EXISTS (
+				SELECT 1 FROM user_achievements ua
+				WHERE ua.user_id = u.id AND ua.achievement_id = a.id
+			)
+		)`,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB err while getting users to backfill achievements for")
+		return
+	}
+
+	for _, user := range users {
+		loc := time.UTC
+		if user.Timezone != nil {
+			userLoc, err := time.LoadLocation(*user.Timezone)
+			if err != nil {
+				log.Warn().Str("userID", user.ID).Str("timezone", *user.Timezone).Msg("Invalid reminder timezone, backfilling achievements in UTC")
+			} else {
+				loc = userLoc
+			}
+		}
+		_, err := achievements.Evaluate(ctx, dbPool, user.ID, achievements.Options{
+			Location:     loc,
+			RolloverHour: user.RolloverHour,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to backfill achievements")
+		}
+	}
+}
+
+/* users need at least this many new reviews (since their last optimization) to be optimized again by fsrsOptimizerJob */
+const fsrsOptimizerMinNewReviews = 100
+
+func fsrsOptimizerJob(dbPool *pgxpool.Pool) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	log.Info().Msg("Running fsrsOptimizerJob")
+	var userIDs []string
+	err := pgxscan.Select(
+		ctx,
+		dbPool,
+		&userIDs,
+		`SELECT rl.user_id
+		FROM fsrs_review_logs rl
+		LEFT JOIN fsrs_parameters p ON p.user_id = rl.user_id
+		WHERE rl.user_id IS NOT NULL
+		AND rl.term_id IS NOT NULL
+		AND rl.rating <> 'MANUAL'
+		AND (p.requested_at IS NULL OR p.requested_at < now() - interval '7 days')
+		GROUP BY rl.user_id
+		HAVING count(*) >= $1
+		AND count(*) FILTER (WHERE p.optimized_at IS NULL OR rl.review > p.optimized_at) >= $2
+		LIMIT 100`,
+		fsrs.MinOptimizationReviews,
+		fsrsOptimizerMinNewReviews,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB err while getting users to optimize fsrs parameters for")
+		return
+	}
+
+	for _, userID := range userIDs {
+		requested, err := fsrs.RequestOptimization(ctx, dbPool, userID)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to request fsrs optimization")
+			continue
+		}
+		if !requested {
+			continue
+		}
+		err = fsrs.OptimizeUser(ctx, dbPool, userID)
+		if err != nil && !errors.Is(err, fsrs.ErrNotEnoughReviews) {
+			log.Error().Err(err).Msg("Failed to optimize fsrs parameters")
+		}
+	}
+}
+
 func termImageCleanupJob(dbPool *pgxpool.Pool, storage *s3.Client, usercontentBucket string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
@@ -148,7 +376,9 @@ func termImageCleanupJob(dbPool *pgxpool.Pool, storage *s3.Client, usercontentBu
 		WHERE NOT EXISTS(
 			SELECT 1 FROM terms t
 			WHERE t.term_image_key = i.object_key OR t.def_image_key = i.object_key
-		)`,
+		)
+		/* images from web imports aren't used until the user creates terms with them */
+		AND i.created_at < now() - interval '1 day'`,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("DB err while getting keys to clean up term images")
@@ -0,0 +1,103 @@
+package mastery
+
+import (
+	"math"
+	"time"
+)
+
+type Level string
+
+const (
+	/* never answered or reviewed */
+	New      Level = "NEW"
+	Learning Level = "LEARNING"
+	Familiar Level = "FAMILIAR"
+	Mastered Level = "MASTERED"
+)
+
+/* accuracy only uses each term's most recent answers, so terms that used to be hard can be mastered */
+const RecentAnswers = 10
+
+/* cards with at least this much FSRS stability (in days) count as fully stable */
+const MasteredStability = 21.0
+
+/* a term's score decays by half (down to RecencyFloor) for every RecencyHalfLife since it was last reviewed */
+const RecencyHalfLife = 14 * 24 * time.Hour
+
+/* even terms that haven't been reviewed in a long time keep this much of their score */
+const RecencyFloor = 0.5
+
+/* minimum scores for each level */
+const (
+	FamiliarScore = 0.6
+	MasteredScore = 0.8
+)
+
+type Stats struct {
+	/* recent answers (up to RecentAnswers), or all answers if recent answers aren't known */
+	Correct   int
+	Incorrect int
+	/* FSRS stability in days, nil if the term doesn't have a reviewed card */
+	Stability    *float64
+	LastReviewed *time.Time
+}
+
+type Mastery struct {
+	Level Level
+	/* 0 to 1 */
+	Score float64
+	/* correct answers out of Stats' answers, nil if there aren't any */
+	Accuracy *float64
+}
+
+/*
+Compute scores how well a term is known from its accuracy (smoothed, so a couple of answers don't count as mastered),
+FSRS stability (if it has a card), and how recently it was reviewed
+*/
+func Compute(stats Stats, now time.Time) Mastery {
+	answers := stats.Correct + stats.Incorrect
+	if answers == 0 && stats.Stability == nil {
+		return Mastery{Level: New}
+	}
+
+	var m Mastery
+	if answers > 0 {
+		accuracy := float64(stats.Correct) / float64(answers)
+		m.Accuracy = &accuracy
+	}
+
+	/* terms without answers are 50/50 */
+	strength := float64(stats.Correct+1) / float64(answers+2)
+	if stats.Stability != nil {
+		strength = (strength + math.Min(*stats.Stability/MasteredStability, 1)) / 2
+	}
+
+	recency := 1.0
+	if stats.LastReviewed != nil && now.After(*stats.LastReviewed) {
+		halfLives := float64(now.Sub(*stats.LastReviewed)) / float64(RecencyHalfLife)
+		recency = RecencyFloor + (1-RecencyFloor)*math.Pow(0.5, halfLives)
+	}
+
+	m.Score = strength * recency
+	switch {
+	case m.Score >= MasteredScore:
+		m.Level = Mastered
+	case m.Score >= FamiliarScore:
+		m.Level = Familiar
+	default:
+		m.Level = Learning
+	}
+	return m
+}
+
+/* Percent returns the average score of terms (including new terms, which score 0) as a percentage */
+func Percent(masteries []Mastery) float64 {
+	if len(masteries) == 0 {
+		return 0
+	}
+	var total float64
+	for _, m := range masteries {
+		total += m.Score
+	}
+	return total / float64(len(masteries)) * 100
+}
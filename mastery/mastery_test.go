@@ -0,0 +1,54 @@
+package mastery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func TestCompute(t *testing.T) {
+	require.Equal(t, Mastery{Level: New}, Compute(Stats{}, now))
+
+	/* a couple of correct answers aren't enough to master a term */
+	m := Compute(Stats{Correct: 1, LastReviewed: &now}, now)
+	require.Equal(t, Familiar, m.Level)
+	require.Equal(t, 1.0, *m.Accuracy)
+	m = Compute(Stats{Correct: 5, LastReviewed: &now}, now)
+	require.Equal(t, Mastered, m.Level)
+
+	m = Compute(Stats{Correct: 2, Incorrect: 3, LastReviewed: &now}, now)
+	require.Equal(t, Learning, m.Level)
+	require.Equal(t, 0.4, *m.Accuracy)
+
+	/* FSRS stability counts for half */
+	stable := 30.0
+	m = Compute(Stats{Stability: &stable, LastReviewed: &now}, now)
+	require.Nil(t, m.Accuracy)
+	require.InDelta(t, 0.75, m.Score, 0.0001)
+	unstable := 1.0
+	m = Compute(Stats{Correct: 5, Stability: &unstable, LastReviewed: &now}, now)
+	require.Equal(t, Learning, m.Level)
+}
+
+func TestComputeRecency(t *testing.T) {
+	recent := Compute(Stats{Correct: 8, LastReviewed: &now}, now)
+	require.Equal(t, Mastered, recent.Level)
+
+	halfLifeAgo := now.Add(-RecencyHalfLife)
+	m := Compute(Stats{Correct: 8, LastReviewed: &halfLifeAgo}, now)
+	require.InDelta(t, recent.Score*0.75, m.Score, 0.0001)
+	require.Equal(t, Familiar, m.Level)
+
+	/* scores don't decay below RecencyFloor */
+	longAgo := now.AddDate(-5, 0, 0)
+	m = Compute(Stats{Correct: 8, LastReviewed: &longAgo}, now)
+	require.InDelta(t, recent.Score*RecencyFloor, m.Score, 0.0001)
+}
+
+func TestPercent(t *testing.T) {
+	require.Zero(t, Percent(nil))
+	require.InDelta(t, 50, Percent([]Mastery{{Score: 1}, {Level: New}}), 0.0001)
+}
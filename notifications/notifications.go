@@ -0,0 +1,97 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Type string
+
+const (
+	/* someone saved one of the user's studysets */
+	TypeStudysetSaved Type = "STUDYSET_SAVED"
+	/* a moderator hid one of the user's studysets from search engines (unapproved its seo indexing) */
+	TypeStudysetHiddenByModerator Type = "STUDYSET_HIDDEN_BY_MODERATOR"
+)
+
+/* Types is every type, in the order preferences are listed */
+var Types = []Type{
+	TypeStudysetSaved,
+	TypeStudysetHiddenByModerator,
+}
+
+/*
+collapsed types aren't created again while the user still has an unread one for the same actor & studyset,
+so (for example) saving & unsaving a studyset over and over doesn't spam its author
+*/
+var collapsed = map[Type]bool{
+	TypeStudysetSaved: true,
+}
+
+type Notification struct {
+	UserID string
+	Type   Type
+	/* the user who caused the notification, nil if it isn't shown (like who saved a studyset) */
+	ActorUserID *string
+	StudysetID  *string
+	URL         *string
+}
+
+/*
+Create saves a notification, unless the user turned off its type (in notification_preferences),
+or it's collapsed into an unread notification like it, and returns whether it was created
+*/
+func Create(ctx context.Context, db *pgxpool.Pool, n Notification) (bool, error) {
+	tag, err := db.Exec(
+		ctx,
+		`INSERT INTO notifications (user_id, type, actor_user_id, studyset_id, url)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences
+			WHERE user_id = $1 AND type = $2 AND NOT enabled
+		)
+		AND NOT (
+			$6::boolean AND EXISTS (
+				SELECT 1 FROM notifications
+				WHERE user_id = $1 AND type = $2 AND read_at IS NULL
+				AND actor_user_id IS NOT DISTINCT FROM $3::uuid
+				AND studyset_id IS NOT DISTINCT FROM $4::uuid
+			)
+		)`,
+		n.UserID,
+		n.Type,
+		n.ActorUserID,
+		n.StudysetID,
+		n.URL,
+		collapsed[n.Type],
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+/*
+NotifyStudysetOwner creates a notification (see Create) for the owner of a studyset,
+unless they're the actor, because users don't need to be notified about their own changes
+*/
+func NotifyStudysetOwner(ctx context.Context, db *pgxpool.Pool, studysetID string, notificationType Type, actorUserID *string, showActor bool) (bool, error) {
+	var ownerID *string
+	err := db.QueryRow(ctx, "SELECT user_id FROM studysets WHERE id = $1", studysetID).Scan(&ownerID)
+	if err != nil {
+		return false, err
+	}
+	if ownerID == nil || (actorUserID != nil && *ownerID == *actorUserID) {
+		return false, nil
+	}
+	n := Notification{
+		UserID:     *ownerID,
+		Type:       notificationType,
+		StudysetID: &studysetID,
+	}
+	if showActor {
+		n.ActorUserID = actorUserID
+	}
+	return Create(ctx, db, n)
+}
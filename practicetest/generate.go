@@ -0,0 +1,237 @@
+package practicetest
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type QuestionType string
+
+const (
+	MCQ QuestionType = "MCQ"
+	TFQ QuestionType = "TFQ"
+	FRQ QuestionType = "FRQ"
+)
+
+type AnswerWith string
+
+const (
+	AnswerWithTerm AnswerWith = "TERM"
+	AnswerWithDef  AnswerWith = "DEF"
+)
+
+/* MCQs have up to this many choices (including the correct one) */
+const MCQChoices = 4
+
+type Term struct {
+	ID         string
+	StudysetID string
+	Term       string
+	Def        string
+	/* how likely the term is to be picked, relative to other terms (terms with 0 or less are never picked) */
+	Weight float64
+}
+
+/* answer returns the side of the term that's answered with */
+func (t *Term) answer(answerWith AnswerWith) string {
+	if answerWith == AnswerWithTerm {
+		return t.Term
+	}
+	return t.Def
+}
+
+type Question struct {
+	Term       Term
+	Type       QuestionType
+	AnswerWith AnswerWith
+	/*
+		MCQs' wrong choices, in order (the correct choice is inserted at CorrectChoiceIndex).
+		TFQs have 1 distractor if the pair shown is false, or none if it's true
+	*/
+	Distractors        []Term
+	CorrectChoiceIndex int
+}
+
+type Options struct {
+	Count int
+	/* question types are mixed evenly, all types are used if it's empty */
+	QuestionTypes []QuestionType
+	/* if it's empty, each question randomly answers with the term or def */
+	AnswerWith AnswerWith
+}
+
+/*
+Generate picks up to opts.Count terms (without repeats), weighted by each term's Weight,
+and makes questions for them with plausible distractors from the other terms.
+*/
+func Generate(terms []Term, opts Options, random *rand.Rand) []Question {
+	questionTypes := opts.QuestionTypes
+	if len(questionTypes) == 0 {
+		questionTypes = []QuestionType{MCQ, TFQ, FRQ}
+	}
+
+	picked := pickWeighted(terms, opts.Count, random)
+	questions := make([]Question, len(picked))
+	for i, term := range picked {
+		answerWith := opts.AnswerWith
+		if answerWith == "" {
+			answerWith = []AnswerWith{AnswerWithTerm, AnswerWithDef}[random.Intn(2)]
+		}
+		questions[i] = NewQuestion(term, questionTypes[i%len(questionTypes)], answerWith, terms, random)
+	}
+	return questions
+}
+
+/* NewQuestion makes a question for term, with plausible distractors from terms (which can include term) */
+func NewQuestion(term Term, questionType QuestionType, answerWith AnswerWith, terms []Term, random *rand.Rand) Question {
+	q := Question{
+		Term:       term,
+		Type:       questionType,
+		AnswerWith: answerWith,
+	}
+	switch q.Type {
+	case MCQ:
+		q.Distractors = distractors(term, terms, q.AnswerWith, MCQChoices-1, random)
+		q.CorrectChoiceIndex = random.Intn(len(q.Distractors) + 1)
+	case TFQ:
+		/* half of TFQs are false (if there are other terms to pair it with) */
+		if random.Intn(2) == 0 {
+			q.Distractors = distractors(term, terms, q.AnswerWith, 1, random)
+		}
+	}
+	return q
+}
+
+/* pickWeighted samples up to n terms without replacement (Efraimidis-Spirakis), in random order */
+func pickWeighted(terms []Term, n int, random *rand.Rand) []Term {
+	type keyedTerm struct {
+		key  float64
+		term Term
+	}
+	keyed := make([]keyedTerm, 0, len(terms))
+	for _, term := range terms {
+		if term.Weight <= 0 || math.IsNaN(term.Weight) {
+			continue
+		}
+		/* 1 - Float64() is never 0 */
+		keyed = append(keyed, keyedTerm{key: -math.Log(1-random.Float64()) / term.Weight, term: term})
+	}
+	slices.SortFunc(keyed, func(a, b keyedTerm) int {
+		if a.key < b.key {
+			return -1
+		}
+		if a.key > b.key {
+			return 1
+		}
+		return 0
+	})
+	picked := make([]Term, 0, min(n, len(keyed)))
+	for _, k := range keyed[:min(n, len(keyed))] {
+		picked = append(picked, k.term)
+	}
+	return picked
+}
+
+/*
+distractors returns up to n other terms to use as wrong answers, the most plausible first.
+Terms with the same answer as the correct one (or as each other) aren't used, because they'd also be correct.
+*/
+func distractors(correct Term, terms []Term, answerWith AnswerWith, n int, random *rand.Rand) []Term {
+	correctAnswer := normalizeAnswer(correct.answer(answerWith))
+	seen := map[string]bool{correctAnswer: true}
+
+	type scoredTerm struct {
+		score float64
+		term  Term
+	}
+	var candidates []scoredTerm
+	for _, term := range terms {
+		answer := normalizeAnswer(term.answer(answerWith))
+		if term.ID == correct.ID || answer == "" || seen[answer] {
+			continue
+		}
+		seen[answer] = true
+		/* a little randomness so the same distractors aren't always picked */
+		score := plausibility(correct, term, answerWith) + random.Float64()*0.5
+		candidates = append(candidates, scoredTerm{score: score, term: term})
+	}
+	slices.SortFunc(candidates, func(a, b scoredTerm) int {
+		if a.score > b.score {
+			return -1
+		}
+		if a.score < b.score {
+			return 1
+		}
+		return 0
+	})
+
+	picked := make([]Term, 0, min(n, len(candidates)))
+	for _, c := range candidates[:min(n, len(candidates))] {
+		picked = append(picked, c.term)
+	}
+	return picked
+}
+
+/*
+plausibility scores how believable term's answer is as a wrong answer for correct's,
+answers with similar lengths, shared words, and the same kind of content (like numbers) are more plausible,
+and so are terms from the same studyset
+*/
+func plausibility(correct Term, term Term, answerWith AnswerWith) float64 {
+	a := correct.answer(answerWith)
+	b := term.answer(answerWith)
+	score := 0.0
+	if correct.StudysetID == term.StudysetID {
+		score += 1
+	}
+
+	lenA, lenB := float64(len([]rune(a))), float64(len([]rune(b)))
+	score += 1 - math.Abs(lenA-lenB)/math.Max(math.Max(lenA, lenB), 1)
+
+	wordsA, wordsB := words(a), words(b)
+	if len(wordsA) > 0 && len(wordsB) > 0 {
+		shared := 0
+		for word := range wordsA {
+			if wordsB[word] {
+				shared++
+			}
+		}
+		/* jaccard similarity */
+		score += float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+	}
+
+	if isNumeric(a) == isNumeric(b) {
+		score += 0.5
+	}
+	return score
+}
+
+func normalizeAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		set[word] = true
+	}
+	return set
+}
+
+/* isNumeric reports whether s is mostly digits, like dates & numbers */
+func isNumeric(s string) bool {
+	digits, others := 0, 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits++
+		} else if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+			others++
+		}
+	}
+	return digits > others
+}
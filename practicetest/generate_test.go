@@ -0,0 +1,109 @@
+package practicetest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTerms() []Term {
+	return []Term{
+		{ID: "1", StudysetID: "a", Term: "la manzana", Def: "apple", Weight: 1},
+		{ID: "2", StudysetID: "a", Term: "la naranja", Def: "orange", Weight: 1},
+		{ID: "3", StudysetID: "a", Term: "el plátano", Def: "banana", Weight: 1},
+		{ID: "4", StudysetID: "a", Term: "la uva", Def: "grape", Weight: 1},
+		{ID: "5", StudysetID: "a", Term: "la pera", Def: "pear", Weight: 1},
+		{ID: "6", StudysetID: "b", Term: "1492", Def: "Columbus reaches the Americas", Weight: 1},
+		{ID: "7", StudysetID: "b", Term: "1776", Def: "Declaration of Independence signed", Weight: 1},
+		{ID: "8", StudysetID: "b", Term: "1789", Def: "French Revolution begins", Weight: 1},
+		{ID: "9", StudysetID: "b", Term: "1945", Def: "World War II ends", Weight: 1},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	terms := testTerms()
+
+	questions := Generate(terms, Options{Count: 6}, random)
+	require.Len(t, questions, 6)
+	seen := map[string]bool{}
+	types := map[QuestionType]int{}
+	for _, q := range questions {
+		require.False(t, seen[q.Term.ID], "terms aren't repeated")
+		seen[q.Term.ID] = true
+		types[q.Type]++
+		require.Contains(t, []AnswerWith{AnswerWithTerm, AnswerWithDef}, q.AnswerWith)
+		for _, d := range q.Distractors {
+			require.NotEqual(t, q.Term.ID, d.ID)
+		}
+		switch q.Type {
+		case MCQ:
+			require.Len(t, q.Distractors, MCQChoices-1)
+			require.GreaterOrEqual(t, q.CorrectChoiceIndex, 0)
+			require.LessOrEqual(t, q.CorrectChoiceIndex, len(q.Distractors))
+		case TFQ:
+			require.LessOrEqual(t, len(q.Distractors), 1)
+		case FRQ:
+			require.Empty(t, q.Distractors)
+		}
+	}
+	/* types are mixed evenly */
+	require.Equal(t, map[QuestionType]int{MCQ: 2, TFQ: 2, FRQ: 2}, types)
+
+	/* count is limited to the number of terms */
+	require.Len(t, Generate(terms, Options{Count: 100}, random), len(terms))
+
+	/* question types & answerWith */
+	for _, q := range Generate(terms, Options{Count: 5, QuestionTypes: []QuestionType{MCQ}, AnswerWith: AnswerWithTerm}, random) {
+		require.Equal(t, MCQ, q.Type)
+		require.Equal(t, AnswerWithTerm, q.AnswerWith)
+	}
+
+	/* a single term can't have distractors */
+	questions = Generate(terms[:1], Options{Count: 1, QuestionTypes: []QuestionType{MCQ}}, random)
+	require.Len(t, questions, 1)
+	require.Empty(t, questions[0].Distractors)
+	require.Equal(t, 0, questions[0].CorrectChoiceIndex)
+}
+
+func TestGenerateWeighting(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	terms := testTerms()
+	terms[0].Weight = 50
+	terms[1].Weight = 0
+
+	picks := map[string]int{}
+	for range 200 {
+		for _, q := range Generate(terms, Options{Count: 1}, random) {
+			picks[q.Term.ID]++
+		}
+	}
+	require.Greater(t, picks["1"], 150)
+	require.Zero(t, picks["2"], "terms with 0 weight aren't picked")
+}
+
+func TestDistractors(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	terms := testTerms()
+
+	/* years are better distractors for a year than fruit */
+	for range 20 {
+		for _, d := range distractors(terms[5], terms, AnswerWithTerm, 3, random) {
+			require.Equal(t, "b", d.StudysetID, d.Term)
+		}
+	}
+
+	/* terms with the same answer as the correct one, or each other, aren't distractors */
+	duplicates := append(testTerms()[:3],
+		Term{ID: "10", StudysetID: "a", Term: "la manzana roja", Def: " Apple ", Weight: 1},
+		Term{ID: "11", StudysetID: "a", Term: "el naranjo", Def: "orange", Weight: 1},
+	)
+	got := distractors(duplicates[0], duplicates, AnswerWithDef, 3, random)
+	answers := []string{}
+	for _, d := range got {
+		answers = append(answers, normalizeAnswer(d.Def))
+	}
+	require.ElementsMatch(t, []string{"orange", "banana"}, answers, fmt.Sprint(got))
+}
@@ -0,0 +1,165 @@
+package practicetest
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+MaxTypoLength is the most runes an answer or expected answer can have to be graded with typos,
+longer answers are only correct if they're exact (or normalized), so comparing them is cheap
+*/
+const MaxTypoLength = 256
+
+type GradeReason string
+
+const (
+	/* the answer is exactly the expected answer */
+	GradeExact GradeReason = "EXACT"
+	/* the answer only differs by case, whitespace, punctuation, or diacritics */
+	GradeNormalized GradeReason = "NORMALIZED"
+	/* the answer is close enough to be a typo */
+	GradeTypo      GradeReason = "TYPO"
+	GradeIncorrect GradeReason = "INCORRECT"
+	GradeEmpty     GradeReason = "EMPTY"
+)
+
+type Grade struct {
+	Correct bool
+	/* how similar the answer is to the closest expected answer, from 0 to 1 */
+	Score  float64
+	Reason GradeReason
+	/* the expected answer (or alternate) that the answer was closest to */
+	MatchedAnswer string
+}
+
+/*
+GradeAnswer grades a free response answer against the expected side of a term.
+Expected answers can have alternates separated by "/" or ";" (like "car / automobile"),
+and any of them (or the whole thing) is correct.
+*/
+func GradeAnswer(answer string, expected string) Grade {
+	normalizedAnswer := normalizeForGrading(answer)
+	if normalizedAnswer == "" {
+		return Grade{Reason: GradeEmpty, MatchedAnswer: strings.TrimSpace(expected)}
+	}
+
+	var best Grade
+	for i, alternate := range alternateAnswers(expected) {
+		grade := gradeAlternate(answer, normalizedAnswer, alternate)
+		if i == 0 || (grade.Correct && !best.Correct) || (grade.Correct == best.Correct && grade.Score > best.Score) {
+			best = grade
+		}
+		if best.Reason == GradeExact {
+			break
+		}
+	}
+	return best
+}
+
+func gradeAlternate(answer string, normalizedAnswer string, alternate string) Grade {
+	if strings.TrimSpace(answer) == alternate {
+		return Grade{Correct: true, Score: 1, Reason: GradeExact, MatchedAnswer: alternate}
+	}
+	normalizedAlternate := normalizeForGrading(alternate)
+	if normalizedAnswer == normalizedAlternate {
+		return Grade{Correct: true, Score: 1, Reason: GradeNormalized, MatchedAnswer: alternate}
+	}
+
+	a, b := []rune(normalizedAnswer), []rune(normalizedAlternate)
+	if len(a) > MaxTypoLength || len(b) > MaxTypoLength {
+		return Grade{Reason: GradeIncorrect, MatchedAnswer: alternate}
+	}
+	distance := editDistance(a, b)
+	score := 1 - float64(distance)/float64(max(len(a), len(b), 1))
+	grade := Grade{Score: max(score, 0), Reason: GradeIncorrect, MatchedAnswer: alternate}
+	if distance <= allowedTypos(normalizedAlternate) {
+		grade.Correct = true
+		grade.Reason = GradeTypo
+	}
+	return grade
+}
+
+/* alternateAnswers splits expected on "/" and ";", the whole answer is also an alternate */
+func alternateAnswers(expected string) []string {
+	whole := strings.TrimSpace(expected)
+	alternates := []string{whole}
+	for _, part := range strings.FieldsFunc(expected, func(r rune) bool { return r == '/' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		if part != "" && part != whole {
+			alternates = append(alternates, part)
+		}
+	}
+	return alternates
+}
+
+/*
+allowedTypos is how many edits an answer can be from expected and still be correct,
+short answers & numbers have to be exact, because one edit changes their meaning
+*/
+func allowedTypos(expected string) int {
+	if isNumeric(expected) {
+		return 0
+	}
+	length := len([]rune(expected))
+	switch {
+	case length <= 4:
+		return 0
+	case length <= 8:
+		return 1
+	case length <= 16:
+		return 2
+	default:
+		return 3
+	}
+}
+
+/* removes diacritics, like "é" to "e" */
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+/* normalizeForGrading lowercases s, removes diacritics and punctuation, and collapses whitespace */
+func normalizeForGrading(s string) string {
+	stripped, _, err := transform.String(stripMarks, s)
+	if err != nil {
+		stripped = s
+	}
+	stripped = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, stripped)
+	return strings.Join(strings.Fields(stripped), " ")
+}
+
+/*
+editDistance is the optimal string alignment distance (Levenshtein, plus swapping adjacent letters),
+it only keeps the last 3 rows, because swaps look back 2 rows
+*/
+func editDistance(a []rune, b []rune) int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, current = prev, current, prevPrev
+	}
+	return prev[len(b)]
+}
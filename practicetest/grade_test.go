@@ -0,0 +1,71 @@
+package practicetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGradeAnswer(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+		correct  bool
+		reason   GradeReason
+	}{
+		{"photosynthesis", "photosynthesis", true, GradeExact},
+		{"  Photosynthesis. ", "photosynthesis", true, GradeNormalized},
+		{"el nino", "El Niño", true, GradeNormalized},
+		{"mitochondria", "the mitochondria", false, GradeIncorrect},
+		{"photosynthesys", "photosynthesis", true, GradeTypo},
+		{"photsynthesis", "photosynthesis", true, GradeTypo},
+		{"recieve", "receive", true, GradeTypo},
+		{"respiration", "photosynthesis", false, GradeIncorrect},
+		/* short answers & numbers have to be exact */
+		{"cat", "car", false, GradeIncorrect},
+		{"1493", "1492", false, GradeIncorrect},
+		/* alternates */
+		{"automobile", "car / automobile", true, GradeExact},
+		{"Automobile!", "car; automobile", true, GradeNormalized},
+		{"car / automobile", "car / automobile", true, GradeExact},
+		{"truck", "car / automobile", false, GradeIncorrect},
+		{"", "car", false, GradeEmpty},
+		{" ?! ", "car", false, GradeEmpty},
+	}
+	for _, test := range tests {
+		grade := GradeAnswer(test.answer, test.expected)
+		require.Equal(t, test.correct, grade.Correct, "%q for %q", test.answer, test.expected)
+		require.Equal(t, test.reason, grade.Reason, "%q for %q", test.answer, test.expected)
+		require.GreaterOrEqual(t, grade.Score, 0.0)
+		require.LessOrEqual(t, grade.Score, 1.0)
+	}
+
+	/* scores are for the closest alternate */
+	grade := GradeAnswer("automobil", "car / automobile")
+	require.True(t, grade.Correct)
+	require.Equal(t, "automobile", grade.MatchedAnswer)
+	require.InDelta(t, 0.9, grade.Score, 1e-9)
+	require.Less(t, GradeAnswer("boat", "car").Score, GradeAnswer("cart", "car").Score)
+}
+
+func TestEditDistance(t *testing.T) {
+	require.Equal(t, 0, editDistance([]rune("abc"), []rune("abc")))
+	require.Equal(t, 3, editDistance([]rune(""), []rune("abc")))
+	require.Equal(t, 1, editDistance([]rune("abc"), []rune("acb")))
+	require.Equal(t, 3, editDistance([]rune("kitten"), []rune("sitting")))
+	/* optimal string alignment can't edit a swapped pair again */
+	require.Equal(t, 3, editDistance([]rune("ca"), []rune("abc")))
+	require.Equal(t, 4, editDistance([]rune("abcd"), []rune("")))
+}
+
+func TestGradeLongAnswer(t *testing.T) {
+	long := strings.Repeat("photosynthesis ", 50)
+	/* too long to be a typo, but exact & normalized answers are still correct */
+	grade := GradeAnswer(long+"x", long)
+	require.False(t, grade.Correct)
+	require.Equal(t, GradeIncorrect, grade.Reason)
+	grade = GradeAnswer(strings.ToUpper(long), long)
+	require.True(t, grade.Correct)
+	require.Equal(t, GradeNormalized, grade.Reason)
+}
@@ -0,0 +1,125 @@
+package practicetest
+
+/* QuestionTypes and AnswerWiths are in the order that scores are broken down in */
+var QuestionTypes = []QuestionType{MCQ, TFQ, FRQ}
+var AnswerWiths = []AnswerWith{AnswerWithTerm, AnswerWithDef}
+
+type Score struct {
+	Correct int
+	Total   int
+}
+
+/* Ratio is the fraction of questions that were correct, 0 if there weren't any questions */
+func (s Score) Ratio() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Total)
+}
+
+func (s *Score) add(other Score) {
+	s.Correct += other.Correct
+	s.Total += other.Total
+}
+
+/* ScoreRow is the score for one practice test's questions of one type answered with one side */
+type ScoreRow struct {
+	PracticeTestID string
+	Timestamp      string
+	Type           QuestionType
+	AnswerWith     AnswerWith
+	Score
+}
+
+type Breakdown struct {
+	Score
+	/* only question types & sides with questions are included, in the order of QuestionTypes & AnswerWiths */
+	ByQuestionType []TypeScore
+	ByAnswerWith   []AnswerWithScore
+}
+
+type TypeScore struct {
+	Type QuestionType
+	Score
+}
+
+type AnswerWithScore struct {
+	AnswerWith AnswerWith
+	Score
+}
+
+type TrendPoint struct {
+	PracticeTestID string
+	Timestamp      string
+	Breakdown
+}
+
+type Trend struct {
+	/* in the order of rows' first rows for each practice test, like oldest first */
+	Tests []TrendPoint
+	/* all of the tests together */
+	Overall Breakdown
+}
+
+/* BuildTrend groups score rows into each practice test's score, and breaks them down by question type & side */
+func BuildTrend(rows []ScoreRow) Trend {
+	var trend Trend
+	type breakdownScores struct {
+		total        Score
+		byType       map[QuestionType]Score
+		byAnswerWith map[AnswerWith]Score
+	}
+	newScores := func() *breakdownScores {
+		return &breakdownScores{byType: make(map[QuestionType]Score), byAnswerWith: make(map[AnswerWith]Score)}
+	}
+	addRow := func(scores *breakdownScores, row ScoreRow) {
+		scores.total.add(row.Score)
+		typeScore := scores.byType[row.Type]
+		typeScore.add(row.Score)
+		scores.byType[row.Type] = typeScore
+		answerWithScore := scores.byAnswerWith[row.AnswerWith]
+		answerWithScore.add(row.Score)
+		scores.byAnswerWith[row.AnswerWith] = answerWithScore
+	}
+	breakdown := func(scores *breakdownScores) Breakdown {
+		b := Breakdown{Score: scores.total, ByQuestionType: []TypeScore{}, ByAnswerWith: []AnswerWithScore{}}
+		for _, questionType := range QuestionTypes {
+			if score := scores.byType[questionType]; score.Total > 0 {
+				b.ByQuestionType = append(b.ByQuestionType, TypeScore{Type: questionType, Score: score})
+			}
+		}
+		for _, answerWith := range AnswerWiths {
+			if score := scores.byAnswerWith[answerWith]; score.Total > 0 {
+				b.ByAnswerWith = append(b.ByAnswerWith, AnswerWithScore{AnswerWith: answerWith, Score: score})
+			}
+		}
+		return b
+	}
+
+	overall := newScores()
+	var testIDs []string
+	tests := make(map[string]*breakdownScores)
+	timestamps := make(map[string]string)
+	for _, row := range rows {
+		scores, exists := tests[row.PracticeTestID]
+		if !exists {
+			scores = newScores()
+			tests[row.PracticeTestID] = scores
+			timestamps[row.PracticeTestID] = row.Timestamp
+			testIDs = append(testIDs, row.PracticeTestID)
+		}
+		addRow(scores, row)
+		addRow(overall, row)
+	}
+
+	trend.Tests = make([]TrendPoint, len(testIDs))
+	for i, id := range testIDs {
+		trend.Tests[i] = TrendPoint{
+			PracticeTestID: id,
+			Timestamp:      timestamps[id],
+			Breakdown:      breakdown(tests[id]),
+		}
+	}
+	trend.Overall = breakdown(overall)
+	return trend
+}
@@ -0,0 +1,47 @@
+package practicetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTrend(t *testing.T) {
+	trend := BuildTrend([]ScoreRow{
+		{PracticeTestID: "a", Timestamp: "2026-10-18", Type: FRQ, AnswerWith: AnswerWithDef, Score: Score{Correct: 1, Total: 4}},
+		{PracticeTestID: "a", Timestamp: "2026-10-18", Type: MCQ, AnswerWith: AnswerWithDef, Score: Score{Correct: 3, Total: 4}},
+		{PracticeTestID: "b", Timestamp: "2026-10-19", Type: MCQ, AnswerWith: AnswerWithTerm, Score: Score{Correct: 2, Total: 2}},
+		{PracticeTestID: "b", Timestamp: "2026-10-19", Type: MCQ, AnswerWith: AnswerWithDef, Score: Score{Correct: 1, Total: 2}},
+	})
+
+	require.Len(t, trend.Tests, 2)
+	a := trend.Tests[0]
+	require.Equal(t, "a", a.PracticeTestID)
+	require.Equal(t, "2026-10-18", a.Timestamp)
+	require.Equal(t, Score{Correct: 4, Total: 8}, a.Score)
+	require.Equal(t, 0.5, a.Ratio())
+	/* in the order of QuestionTypes, not the rows' order */
+	require.Equal(t, []TypeScore{
+		{Type: MCQ, Score: Score{Correct: 3, Total: 4}},
+		{Type: FRQ, Score: Score{Correct: 1, Total: 4}},
+	}, a.ByQuestionType)
+	require.Equal(t, []AnswerWithScore{{AnswerWith: AnswerWithDef, Score: Score{Correct: 4, Total: 8}}}, a.ByAnswerWith)
+
+	b := trend.Tests[1]
+	require.Equal(t, 0.75, b.Ratio())
+	require.Equal(t, []TypeScore{{Type: MCQ, Score: Score{Correct: 3, Total: 4}}}, b.ByQuestionType)
+
+	require.Equal(t, Score{Correct: 7, Total: 12}, trend.Overall.Score)
+	require.Equal(t, []TypeScore{
+		{Type: MCQ, Score: Score{Correct: 6, Total: 8}},
+		{Type: FRQ, Score: Score{Correct: 1, Total: 4}},
+	}, trend.Overall.ByQuestionType)
+	require.Equal(t, []AnswerWithScore{
+		{AnswerWith: AnswerWithTerm, Score: Score{Correct: 2, Total: 2}},
+		{AnswerWith: AnswerWithDef, Score: Score{Correct: 5, Total: 10}},
+	}, trend.Overall.ByAnswerWith)
+
+	empty := BuildTrend(nil)
+	require.Empty(t, empty.Tests)
+	require.Zero(t, empty.Overall.Ratio())
+}
@@ -0,0 +1,204 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"quizfreely/api/streak"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+type settingsRow struct {
+	UserID           string     `db:"user_id"`
+	DisplayName      string     `db:"display_name"`
+	ReminderMinute   int        `db:"reminder_minute"`
+	Timezone         string     `db:"timezone"`
+	Channel          Channel    `db:"channel"`
+	Email            *string    `db:"email"`
+	WebhookURL       *string    `db:"webhook_url"`
+	UnsubscribeToken string     `db:"unsubscribe_token"`
+	LastRemindedOn   *time.Time `db:"last_reminded_on"`
+	RolloverHour     int32      `db:"day_rollover_hour"`
+}
+
+/* how many reminders are sent at once, so slow webhooks don't delay everyone else's reminders */
+const sendWorkers = 8
+
+/* each reminder gets this long to send, so a hanging webhook or smtp server can't use up all of Send's ctx */
+const notifyTimeout = 30 * time.Second
+
+/*
+Send sends the reminders that are scheduled now (see Scheduled) to users with cards due, and returns how many were sent.
+Each user's reminder is only checked once per local date, even if nothing was due,
+but reminders that fail to send are retried by the next Send (until MaxLateness).
+unsubscribeURL is where unsubscribe tokens are sent (as the token query param), reminders don't have unsubscribe links if it's empty
+*/
+func Send(ctx context.Context, db *pgxpool.Pool, notifiers Notifiers, unsubscribeURL string, now time.Time) (int, error) {
+	var rows []settingsRow
+	err := pgxscan.Select(
+		ctx,
+		db,
+		&rows,
+		`SELECT rs.user_id, u.display_name, rs.reminder_minute, rs.timezone, rs.channel,
+	u.oauth_google_email AS email, rs.webhook_url, rs.unsubscribe_token,
+	rs.last_reminded_on, COALESCE(ss.day_rollover_hour, 0) AS day_rollover_hour
+FROM reminder_settings rs
+JOIN auth.users u ON u.id = rs.user_id
+LEFT JOIN study_settings ss ON ss.user_id = rs.user_id
+WHERE rs.enabled`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var mu sync.Mutex
+	sent := 0
+	var errs []error
+	queue := make(chan settingsRow)
+	var wg sync.WaitGroup
+	for range min(sendWorkers, len(rows)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range queue {
+				ok, err := sendReminder(ctx, db, notifiers, unsubscribeURL, now, row)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("user %s: %w", row.UserID, err))
+				}
+				if ok {
+					sent++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+queueRows:
+	for _, row := range rows {
+		select {
+		case queue <- row:
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+			break queueRows
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return sent, errors.Join(errs...)
+}
+
+/* sendReminder sends row's reminder if it's scheduled now and they have cards due, and returns whether it was sent */
+func sendReminder(ctx context.Context, db *pgxpool.Pool, notifiers Notifiers, unsubscribeURL string, now time.Time, row settingsRow) (bool, error) {
+	loc, err := time.LoadLocation(row.Timezone)
+	if err != nil {
+		log.Warn().Str("userID", row.UserID).Str("timezone", row.Timezone).Msg("Invalid reminder timezone")
+		return false, nil
+	}
+	date, ok := Scheduled(now, loc, row.ReminderMinute)
+	if !ok || (row.LastRemindedOn != nil && !row.LastRemindedOn.Before(date)) {
+		return false, nil
+	}
+	notifier := notifiers[row.Channel]
+	if notifier == nil {
+		return false, nil
+	}
+	reminder := Reminder{
+		UserID:      row.UserID,
+		DisplayName: row.DisplayName,
+		Channel:     row.Channel,
+		Date:        date.Format(time.DateOnly),
+	}
+	switch row.Channel {
+	case ChannelEmail:
+		if row.Email != nil {
+			reminder.Address = *row.Email
+		}
+	case ChannelWebhook:
+		if row.WebhookURL != nil {
+			reminder.Address = *row.WebhookURL
+		}
+	}
+	if reminder.Address == "" {
+		return false, nil
+	}
+	if unsubscribeURL != "" {
+		reminder.UnsubscribeURL = unsubscribeURL + "?" + url.Values{"token": {row.UnsubscribeToken}}.Encode()
+	}
+
+	/* claimed before sending, so reminders aren't sent twice if Send runs twice at once (like on multiple servers) */
+	tag, err := db.Exec(
+		ctx,
+		`UPDATE reminder_settings SET last_reminded_on = $2
+		WHERE user_id = $1 AND enabled
+		AND (last_reminded_on IS NULL OR last_reminded_on < $2)`,
+		row.UserID,
+		date,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	/* after it's claimed, ctx might be done (or time out while sending),
+	but the reminder still has to be unclaimed or marked as sent */
+	claimedCtx := context.WithoutCancel(ctx)
+	/* unclaimed, so it's retried */
+	unclaim := func() error {
+		_, err := db.Exec(
+			claimedCtx,
+			"UPDATE reminder_settings SET last_reminded_on = $2 WHERE user_id = $1",
+			row.UserID,
+			row.LastRemindedOn,
+		)
+		return err
+	}
+
+	studyDate := streak.StudyDate(now, loc, row.RolloverHour)
+	endOfDay := time.Date(studyDate.Year(), studyDate.Month(), studyDate.Day()+1, int(row.RolloverHour), 0, 0, 0, loc)
+	err = db.QueryRow(
+		ctx,
+		`SELECT count(*) FROM fsrs_cards fc
+		JOIN terms t ON t.id = fc.term_id
+		JOIN studysets s ON s.id = t.studyset_id
+		WHERE fc.user_id = $1
+		AND fc.state <> 'NEW'
+		AND fc.due < $2
+		AND NOT fc.suspended
+		AND (fc.buried_until IS NULL OR fc.buried_until <= $3)
+		AND ((s.private = false AND s.draft = false) OR s.user_id = $1)`,
+		row.UserID,
+		endOfDay,
+		now,
+	).Scan(&reminder.DueCount)
+	if err != nil {
+		return false, errors.Join(err, unclaim())
+	}
+	if reminder.DueCount == 0 {
+		return false, nil
+	}
+
+	notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	err = notifier.Notify(notifyCtx, reminder)
+	if err != nil {
+		log.Error().Err(err).Str("userID", row.UserID).Str("channel", string(row.Channel)).Msg("Failed to send study reminder")
+		return false, unclaim()
+	}
+
+	_, err = db.Exec(
+		claimedCtx,
+		"UPDATE reminder_settings SET last_sent_at = $2 WHERE user_id = $1",
+		row.UserID,
+		now,
+	)
+	return true, err
+}
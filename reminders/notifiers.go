@@ -0,0 +1,204 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	qzfrAPIConfig "quizfreely/api/config"
+	"quizfreely/api/safedial"
+
+	"github.com/rs/zerolog/log"
+)
+
+/* LogNotifier only logs reminders, for development (reminder_notifier = "log" in config.toml) */
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, reminder Reminder) error {
+	log.Info().
+		Str("userID", reminder.UserID).
+		Str("channel", string(reminder.Channel)).
+		Str("address", reminder.Address).
+		Str("date", reminder.Date).
+		Int("dueCount", reminder.DueCount).
+		Str("unsubscribeURL", reminder.UnsubscribeURL).
+		Msg("Study reminder")
+	return nil
+}
+
+/* SMTPNotifier sends reminders as plain text emails */
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, reminder Reminder) error {
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", n.From},
+		{"To", reminder.Address},
+		{"Subject", reminder.Subject()},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+	}
+	if reminder.UnsubscribeURL != "" {
+		headers = append(headers,
+			[2]string{"List-Unsubscribe", "<" + reminder.UnsubscribeURL + ">"},
+			[2]string{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		)
+	}
+	for _, header := range headers {
+		msg.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(reminder.Body(), "\n", "\r\n"))
+
+	/* smtp_from can have a name, like "Quizfreely <reminders@quizfreely.org>" */
+	from, err := mail.ParseAddress(n.From)
+	if err != nil {
+		return fmt.Errorf("invalid smtp_from: %w", err)
+	}
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+	/* net/smtp doesn't take a context, so ctx is only checked before sending */
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(
+		net.JoinHostPort(n.Host, strconv.Itoa(n.Port)),
+		auth,
+		from.Address,
+		[]string{reminder.Address},
+		msg.Bytes(),
+	)
+}
+
+/* WebhookPayload is the JSON body of reminder webhooks */
+type WebhookPayload struct {
+	Type           string `json:"type"`
+	UserID         string `json:"userId"`
+	Date           string `json:"date"`
+	DueCount       int    `json:"dueCount"`
+	UnsubscribeURL string `json:"unsubscribeUrl,omitempty"`
+}
+
+/*
+WebhookNotifier POSTs reminders as JSON to users' webhook urls.
+If Secret is set, the body's HMAC-SHA256 (in hex) is sent in the X-Quizfreely-Signature header, like "sha256=abc123"
+*/
+type WebhookNotifier struct {
+	Client *http.Client
+	Secret string
+}
+
+/*
+NewWebhookNotifier's client only connects to public addresses and doesn't follow redirects,
+because webhook urls are set by users
+*/
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	dialer := safedial.NewDialer(10 * time.Second)
+	return &WebhookNotifier{
+		Client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 10 * time.Second,
+			},
+			Timeout: 20 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Secret: secret,
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder Reminder) error {
+	body, err := json.Marshal(WebhookPayload{
+		Type:           "study_reminder",
+		UserID:         reminder.UserID,
+		Date:           reminder.Date,
+		DueCount:       reminder.DueCount,
+		UnsubscribeURL: reminder.UnsubscribeURL,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reminder.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "QuizfreelyReminders/1.0")
+	if n.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Quizfreely-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+/*
+NotifiersFromConfig picks notifiers with reminder_notifier in config.toml,
+"log" logs every reminder (for development), and "send" (the default) sends them,
+but emails are only sent if smtp_host is set
+*/
+func NotifiersFromConfig(config qzfrAPIConfig.Config) (Notifiers, error) {
+	switch strings.ToLower(strings.TrimSpace(config.ReminderNotifier)) {
+	case "log":
+		return Notifiers{
+			ChannelEmail:   LogNotifier{},
+			ChannelWebhook: LogNotifier{},
+		}, nil
+	case "", "send":
+		notifiers := Notifiers{
+			ChannelWebhook: NewWebhookNotifier(config.ReminderWebhookSecret),
+		}
+		if config.SMTPHost != "" {
+			if config.SMTPFrom == "" {
+				return nil, errors.New("smtp_from is required when smtp_host is set")
+			}
+			port := config.SMTPPort
+			if port == 0 {
+				port = 587
+			}
+			notifiers[ChannelEmail] = &SMTPNotifier{
+				Host:     config.SMTPHost,
+				Port:     port,
+				Username: config.SMTPUsername,
+				Password: config.SMTPPassword,
+				From:     config.SMTPFrom,
+			}
+		}
+		return notifiers, nil
+	default:
+		return nil, fmt.Errorf("unknown reminder_notifier %q, it should be \"send\" or \"log\"", config.ReminderNotifier)
+	}
+}
@@ -0,0 +1,96 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/* reminders sent more than this long after the user's reminder time (like if the server was down) are skipped */
+const MaxLateness = time.Hour
+
+type Channel string
+
+const (
+	ChannelEmail   Channel = "EMAIL"
+	ChannelWebhook Channel = "WEBHOOK"
+)
+
+/* Reminder is one user's reminder for one (local) day */
+type Reminder struct {
+	UserID      string
+	DisplayName string
+	Channel     Channel
+	/* the email address or webhook url, depending on Channel */
+	Address string
+	/* the local date the reminder is for, YYYY-MM-DD */
+	Date string
+	/* FSRS cards (not counting new cards) due by the end of the user's study day */
+	DueCount int
+	/* empty if reminder_unsubscribe_url isn't set in config.toml */
+	UnsubscribeURL string
+}
+
+/* Notifier sends reminders through one channel (or logs them, see LogNotifier) */
+type Notifier interface {
+	Notify(ctx context.Context, reminder Reminder) error
+}
+
+/* Notifiers picks the notifier for each channel, reminders for channels without one aren't sent */
+type Notifiers map[Channel]Notifier
+
+/*
+Scheduled returns the local date of the reminder that should be sent now (at minute, minutes after midnight in loc),
+or false if it's not time for one yet, or it's too late (more than MaxLateness after the reminder time).
+Reminders just before midnight are still sent for that date if they're checked a little after midnight.
+*/
+func Scheduled(now time.Time, loc *time.Location, minute int) (time.Time, bool) {
+	localNow := now.In(loc)
+	scheduled := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), minute/60, minute%60, 0, 0, loc)
+	if localNow.Before(scheduled) {
+		scheduled = time.Date(localNow.Year(), localNow.Month(), localNow.Day()-1, minute/60, minute%60, 0, 0, loc)
+	}
+	if now.Sub(scheduled) > MaxLateness {
+		return time.Time{}, false
+	}
+	return time.Date(scheduled.Year(), scheduled.Month(), scheduled.Day(), 0, 0, 0, 0, time.UTC), true
+}
+
+/* ParseTime parses a local reminder time like "18:30" into minutes after midnight */
+func ParseTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("reminder time must be HH:MM (24-hour)")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+/* FormatTime formats minutes after midnight like "18:30" */
+func FormatTime(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+/* Subject is the email subject for a reminder */
+func (r Reminder) Subject() string {
+	if r.DueCount == 1 {
+		return "1 card is due on Quizfreely"
+	}
+	return fmt.Sprintf("%d cards are due on Quizfreely", r.DueCount)
+}
+
+/* Body is the plain text email body for a reminder */
+func (r Reminder) Body() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", r.DisplayName)
+	if r.DueCount == 1 {
+		b.WriteString("You have 1 card due for review today.\n")
+	} else {
+		fmt.Fprintf(&b, "You have %d cards due for review today.\n", r.DueCount)
+	}
+	b.WriteString("Reviewing them now keeps your streak going and helps you remember them longer.\n")
+	if r.UnsubscribeURL != "" {
+		fmt.Fprintf(&b, "\nTo stop getting study reminders, unsubscribe: %s\n", r.UnsubscribeURL)
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package reminders
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qzfrAPIConfig "quizfreely/api/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestScheduled(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, ny)
+	}
+	eightThirty := 8*60 + 30
+
+	/* not time yet */
+	_, ok := Scheduled(at(8, 29), ny, eightThirty)
+	require.False(t, ok)
+	day, ok := Scheduled(at(8, 30), ny, eightThirty)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 19), day)
+	day, ok = Scheduled(at(9, 30), ny, eightThirty)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 19), day)
+	/* too late */
+	_, ok = Scheduled(at(9, 31), ny, eightThirty)
+	require.False(t, ok)
+
+	/* the reminder time is in the user's timezone, not the server's */
+	day, ok = Scheduled(time.Date(2026, 10, 19, 12, 35, 0, 0, time.UTC), ny, eightThirty)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 19), day)
+
+	/* reminders just before midnight are for the day before if they're checked after midnight */
+	day, ok = Scheduled(at(0, 10), ny, 23*60+50)
+	require.True(t, ok)
+	require.Equal(t, date(2026, 10, 18), day)
+}
+
+func TestParseTime(t *testing.T) {
+	minute, err := ParseTime("18:30")
+	require.NoError(t, err)
+	require.Equal(t, 18*60+30, minute)
+	require.Equal(t, "18:30", FormatTime(minute))
+	minute, err = ParseTime("00:05")
+	require.NoError(t, err)
+	require.Equal(t, "00:05", FormatTime(minute))
+
+	for _, invalid := range []string{"24:00", "6pm", "18:60", ""} {
+		_, err := ParseTime(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestReminderText(t *testing.T) {
+	reminder := Reminder{DisplayName: "Ada", DueCount: 1}
+	require.Equal(t, "1 card is due on Quizfreely", reminder.Subject())
+	require.Contains(t, reminder.Body(), "You have 1 card due")
+	require.NotContains(t, reminder.Body(), "unsubscribe")
+
+	reminder.DueCount = 12
+	reminder.UnsubscribeURL = "https://api.quizfreely.org/reminders/unsubscribe?token=abc"
+	require.Equal(t, "12 cards are due on Quizfreely", reminder.Subject())
+	require.Contains(t, reminder.Body(), "You have 12 cards due")
+	require.Contains(t, reminder.Body(), reminder.UnsubscribeURL)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got WebhookPayload
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &got))
+		signature = r.Header.Get("X-Quizfreely-Signature")
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	}))
+	defer server.Close()
+
+	/* the test server is on localhost, so it uses the test server's client instead of NewWebhookNotifier's */
+	notifier := &WebhookNotifier{Client: server.Client(), Secret: "secret"}
+	err := notifier.Notify(context.Background(), Reminder{
+		UserID:   "user1",
+		Channel:  ChannelWebhook,
+		Address:  server.URL,
+		Date:     "2026-10-19",
+		DueCount: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookPayload{Type: "study_reminder", UserID: "user1", Date: "2026-10-19", DueCount: 3}, got)
+	require.NotEmpty(t, signature)
+
+	/* NewWebhookNotifier's client can't be used for private addresses */
+	err = NewWebhookNotifier("").Notify(context.Background(), Reminder{Address: server.URL})
+	require.Error(t, err)
+}
+
+func TestNotifiersFromConfig(t *testing.T) {
+	notifiers, err := NotifiersFromConfig(qzfrAPIConfig.Config{ReminderNotifier: "log"})
+	require.NoError(t, err)
+	require.Equal(t, LogNotifier{}, notifiers[ChannelEmail])
+	require.Equal(t, LogNotifier{}, notifiers[ChannelWebhook])
+
+	/* emails aren't sent without smtp */
+	notifiers, err = NotifiersFromConfig(qzfrAPIConfig.Config{})
+	require.NoError(t, err)
+	require.Nil(t, notifiers[ChannelEmail])
+	require.NotNil(t, notifiers[ChannelWebhook])
+
+	notifiers, err = NotifiersFromConfig(qzfrAPIConfig.Config{SMTPHost: "smtp.example.org", SMTPFrom: "Quizfreely <reminders@example.org>"})
+	require.NoError(t, err)
+	require.Equal(t, &SMTPNotifier{Host: "smtp.example.org", Port: 587, From: "Quizfreely <reminders@example.org>"}, notifiers[ChannelEmail])
+
+	_, err = NotifiersFromConfig(qzfrAPIConfig.Config{SMTPHost: "smtp.example.org"})
+	require.Error(t, err)
+	_, err = NotifiersFromConfig(qzfrAPIConfig.Config{ReminderNotifier: "pigeon"})
+	require.Error(t, err)
+}
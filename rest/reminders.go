@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+/*
+UnsubscribeReminders turns off study reminders for the user with the unsubscribe token in the token query param.
+It's linked in reminders (and emails' List-Unsubscribe header), so it doesn't need the user to be signed in,
+and it handles POST too for one-click unsubscribing (RFC 8058)
+*/
+func (rh *RESTHandler) UnsubscribeReminders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]any{
+			"error": "Missing token",
+		})
+		return
+	}
+
+	tag, err := rh.DB.Exec(
+		ctx,
+		`UPDATE reminder_settings SET enabled = false, updated_at = now()
+		WHERE unsubscribe_token = $1`,
+		token,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("DB error unsubscribing from reminders in UnsubscribeReminders")
+		render.Status(r, 500)
+		render.JSON(w, r, map[string]any{
+			"error": "DB error unsubscribing from reminders",
+		})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		render.Status(r, 404)
+		render.JSON(w, r, map[string]any{
+			"error": "Invalid unsubscribe token",
+		})
+		return
+	}
+
+	render.JSON(w, r, map[string]any{
+		"error": false,
+		"data": map[string]any{
+			"unsubscribed": true,
+		},
+	})
+}
@@ -3,18 +3,18 @@ package rest
 import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"net/http"
+	"quizfreely/api/webimport"
+	"time"
 )
 
 type RESTHandler struct {
-	DB                     *pgxpool.Pool
-	Storage                *s3.Client
-	UsercontentBucket      *string
-	UsercontentBaseURL     *string
-	HTTPClient             *http.Client
-	UseCrawlbase           bool
-	CrawlbaseAPIKey        string
-	UseZyte                bool
-	ZyteAPIKey             string
-	TryZyteBeforeCrawlbase bool
+	DB                 *pgxpool.Pool
+	Storage            *s3.Client
+	UsercontentBucket  *string
+	UsercontentBaseURL *string
+	PDFFontPath        string
+	WebImporters       *webimport.Registry
+	WebImportFetchers  *webimport.Chain
+	WebImportCacheTTL  time.Duration
+	WebImportImages    *webimport.ImageDownloader
 }
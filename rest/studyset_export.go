@@ -0,0 +1,575 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"quizfreely/api/auth"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+/* in mm, exported pdfs are letter size */
+const (
+	exportPageMargin      = 10.0
+	exportCardCols        = 2
+	exportCardRows        = 4
+	exportCardPadding     = 4.0
+	exportMaxFontSize     = 16.0
+	exportMinFontSize     = 7.0
+	exportQuizImageHeight = 30.0
+)
+
+type exportStudyset struct {
+	ID        string  `json:"id" db:"id"`
+	Title     string  `json:"title" db:"title"`
+	Private   bool    `json:"private" db:"private"`
+	Draft     bool    `json:"draft" db:"draft"`
+	SubjectID *string `json:"subjectId" db:"subject_id"`
+	CreatedAt *string `json:"createdAt" db:"created_at"`
+	UpdatedAt *string `json:"updatedAt" db:"updated_at"`
+}
+
+type exportTerm struct {
+	ID           string  `json:"id" db:"id"`
+	Term         *string `json:"term" db:"term"`
+	Def          *string `json:"def" db:"def"`
+	TermImageKey *string `json:"-" db:"term_image_key"`
+	DefImageKey  *string `json:"-" db:"def_image_key"`
+	TermImageURL *string `json:"termImageUrl" db:"term_image_url"`
+	DefImageURL  *string `json:"defImageUrl" db:"def_image_url"`
+	SortOrder    int32   `json:"sortOrder" db:"sort_order"`
+}
+
+var exportFilenameRegex = regexp.MustCompile(`[^\p{L}\p{N} _.-]+`)
+
+/*
+ExportStudyset handles GET /v0/studysets/{studysetID}/export?format=csv|json|pdf
+
+pdf also takes layout=flashcards|quiz (flashcards by default).
+Visibility is the same as the studyset loader:
+public non-draft studysets, or any studyset owned by the authed user.
+*/
+func (rh *RESTHandler) ExportStudyset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	studysetID := chi.URLParam(r, "studysetID")
+	if studysetID == "" {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]any{
+			"error": "Missing studysetID in URL",
+		})
+		return
+	}
+	/* invalid ids can't be studysets, so they're not found instead of a DB error */
+	if _, err := uuid.Parse(studysetID); err != nil {
+		render.Status(r, 404)
+		render.JSON(w, r, map[string]any{
+			"error": "studyset not found",
+		})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" && format != "pdf" {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]any{
+			"error": "Invalid format, expected csv, json, or pdf",
+		})
+		return
+	}
+	layout := r.URL.Query().Get("layout")
+	if layout == "" {
+		layout = "flashcards"
+	}
+	if format == "pdf" && layout != "flashcards" && layout != "quiz" {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]any{
+			"error": "Invalid layout, expected flashcards or quiz",
+		})
+		return
+	}
+
+	var authedUserID *string
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser != nil {
+		authedUserID = authedUser.ID
+	}
+
+	var studyset exportStudyset
+	err := pgxscan.Get(
+		ctx,
+		rh.DB,
+		&studyset,
+		`SELECT s.id, s.title, s.private, s.draft, s.subject_id,
+			to_char(s.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+			to_char(s.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM studysets s
+		WHERE s.id = $1
+		AND ((s.private = false AND s.draft = false) OR s.user_id = $2)`,
+		studysetID,
+		authedUserID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			render.Status(r, 404)
+			render.JSON(w, r, map[string]any{
+				"error": "studyset not found",
+			})
+			return
+		}
+		log.Error().Err(err).Msg("error getting studyset in ExportStudyset")
+		render.Status(r, 500)
+		render.JSON(w, r, map[string]any{
+			"error": "DB error getting studyset",
+		})
+		return
+	}
+
+	var terms []exportTerm
+	err = pgxscan.Select(
+		ctx,
+		rh.DB,
+		&terms,
+		`SELECT t.id, t.term, t.def, t.term_image_key, t.def_image_key,
+			($2||t.term_image_key) as term_image_url, ($2||t.def_image_key) as def_image_url,
+			t.sort_order
+		FROM terms t
+		WHERE t.studyset_id = $1
+		ORDER BY t.sort_order ASC`,
+		studysetID,
+		rh.UsercontentBaseURL,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("error getting terms in ExportStudyset")
+		render.Status(r, 500)
+		render.JSON(w, r, map[string]any{
+			"error": "DB error getting terms",
+		})
+		return
+	}
+
+	filename := strings.TrimSpace(exportFilenameRegex.ReplaceAllString(studyset.Title, ""))
+	if filename == "" {
+		filename = "studyset"
+	}
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", contentDisposition(filename+".csv"))
+		/* the response has already started, so write errors (like the client disconnecting) are only logged */
+		cw := csv.NewWriter(w)
+		err = cw.Write([]string{"term", "def", "term_image_url", "def_image_url"})
+		for _, t := range terms {
+			if err != nil {
+				break
+			}
+			err = cw.Write([]string{
+				derefString(t.Term),
+				derefString(t.Def),
+				derefString(t.TermImageURL),
+				derefString(t.DefImageURL),
+			})
+		}
+		if err == nil {
+			cw.Flush()
+			err = cw.Error()
+		}
+		if err != nil {
+			log.Error().Err(err).Str("studysetID", studysetID).Msg("error writing csv in ExportStudyset")
+		}
+	case "json":
+		w.Header().Set("Content-Disposition", contentDisposition(filename+".json"))
+		render.JSON(w, r, map[string]any{
+			"studyset": studyset,
+			"terms":    terms,
+		})
+	case "pdf":
+		e := rh.newPDFExporter(ctx)
+		if layout == "quiz" {
+			e.quiz(studyset.Title, terms)
+		} else {
+			e.flashcards(terms)
+		}
+		var buf bytes.Buffer
+		if err := e.pdf.Output(&buf); err != nil {
+			log.Error().Err(err).Msg("error generating pdf in ExportStudyset")
+			render.Status(r, 500)
+			render.JSON(w, r, map[string]any{
+				"error": "error generating pdf",
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", contentDisposition(filename+".pdf"))
+		w.Write(buf.Bytes())
+	}
+}
+
+func contentDisposition(filename string) string {
+	return fmt.Sprintf(`attachment; filename="%s"`, strings.ReplaceAll(filename, `"`, ""))
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+type pdfExporter struct {
+	ctx     context.Context
+	rh      *RESTHandler
+	pdf     *fpdf.Fpdf
+	font    string
+	utf8    bool
+	tr      func(string) string
+	images  map[string]bool
+	imgInfo map[string][2]float64
+}
+
+func (rh *RESTHandler) newPDFExporter(ctx context.Context) *pdfExporter {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(exportPageMargin, exportPageMargin, exportPageMargin)
+	pdf.SetAutoPageBreak(false, exportPageMargin)
+
+	e := &pdfExporter{
+		ctx:     ctx,
+		rh:      rh,
+		pdf:     pdf,
+		font:    "Helvetica",
+		images:  map[string]bool{},
+		imgInfo: map[string][2]float64{},
+	}
+	if rh.PDFFontPath != "" {
+		/* core fonts only cover cp1252,
+		so a TTF font is needed for other alphabets */
+		pdf.AddUTF8Font("export", "", rh.PDFFontPath)
+		if pdf.Err() {
+			log.Error().Err(pdf.Error()).Msg("error loading pdf_font_path, falling back to Helvetica")
+			pdf.ClearError()
+		} else {
+			e.font = "export"
+			e.utf8 = true
+		}
+	}
+	if e.utf8 {
+		e.tr = func(s string) string { return s }
+	} else {
+		e.tr = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+	pdf.SetFont(e.font, "", exportMaxFontSize)
+	return e
+}
+
+/*
+loadImage loads an image from the usercontent bucket and registers it with the pdf,
+returning false if it can't be used (storage not configured, missing object, etc)
+*/
+func (e *pdfExporter) loadImage(key *string) (string, float64, float64, bool) {
+	if key == nil || *key == "" || e.rh.Storage == nil {
+		return "", 0, 0, false
+	}
+	if ok, done := e.images[*key]; done {
+		info := e.imgInfo[*key]
+		return *key, info[0], info[1], ok
+	}
+	e.images[*key] = false
+
+	obj, err := e.rh.Storage.GetObject(e.ctx, &s3.GetObjectInput{
+		Bucket: e.rh.UsercontentBucket,
+		Key:    key,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("key", *key).Msg("failed to get term image for pdf export")
+		return "", 0, 0, false
+	}
+	defer obj.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(obj.Body, maxSizeBefore))
+	if err != nil {
+		log.Warn().Err(err).Str("key", *key).Msg("failed to read term image for pdf export")
+		return "", 0, 0, false
+	}
+	/* stored images are webp, which fpdf doesn't support, so convert to png */
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		log.Warn().Err(err).Str("key", *key).Msg("failed to decode term image for pdf export")
+		return "", 0, 0, false
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		log.Warn().Err(err).Str("key", *key).Msg("failed to encode term image for pdf export")
+		return "", 0, 0, false
+	}
+
+	info := e.pdf.RegisterImageOptionsReader(*key, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	if e.pdf.Err() || info == nil {
+		log.Warn().Err(e.pdf.Error()).Str("key", *key).Msg("failed to register term image for pdf export")
+		e.pdf.ClearError()
+		return "", 0, 0, false
+	}
+	e.images[*key] = true
+	e.imgInfo[*key] = [2]float64{info.Width(), info.Height()}
+	return *key, info.Width(), info.Height(), true
+}
+
+/* drawImage fits an image inside the box, centered horizontally */
+func (e *pdfExporter) drawImage(name string, imgW, imgH, x, y, w, h float64) float64 {
+	scale := min(w/imgW, h/imgH)
+	dw, dh := imgW*scale, imgH*scale
+	e.pdf.ImageOptions(name, x+(w-dw)/2, y, dw, dh, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	return dh
+}
+
+/*
+wrap splits already-translated text into lines that fit in w,
+breaking words that are wider than a whole line
+*/
+func (e *pdfExporter) wrap(txt string, w float64) []string {
+	var lines []string
+	for _, para := range strings.Split(txt, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if e.pdf.GetStringWidth(candidate) <= w {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			for e.pdf.GetStringWidth(word) > w {
+				cut := e.fit(word, w)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+/* fit returns the byte index of the longest prefix of s that fits in w (at least 1 char) */
+func (e *pdfExporter) fit(s string, w float64) int {
+	var cuts []int
+	if e.utf8 {
+		for i := range s {
+			if i > 0 {
+				cuts = append(cuts, i)
+			}
+		}
+	} else {
+		/* translated cp1252 strings are single-byte, not valid utf-8 */
+		for i := 1; i < len(s); i++ {
+			cuts = append(cuts, i)
+		}
+	}
+	cuts = append(cuts, len(s))
+	best := cuts[0]
+	for _, cut := range cuts {
+		if e.pdf.GetStringWidth(s[:cut]) > w {
+			break
+		}
+		best = cut
+	}
+	return best
+}
+
+/*
+drawBoxText draws text centered inside a box,
+shrinking the font size until it fits (and cutting it off at the minimum size)
+*/
+func (e *pdfExporter) drawBoxText(txt string, x, y, w, h float64) {
+	txt = e.tr(txt)
+	size := exportMaxFontSize
+	var lines []string
+	var lineH float64
+	for {
+		e.pdf.SetFontSize(size)
+		_, unit := e.pdf.GetFontSize()
+		lineH = unit * 1.25
+		lines = e.wrap(txt, w)
+		if float64(len(lines))*lineH <= h || size <= exportMinFontSize {
+			break
+		}
+		size--
+	}
+	if maxLines := int(h / lineH); len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	top := y + (h-float64(len(lines))*lineH)/2
+	for i, line := range lines {
+		e.pdf.SetXY(x, top+float64(i)*lineH)
+		e.pdf.CellFormat(w, lineH, line, "", 0, "C", false, 0, "")
+	}
+	e.pdf.SetFontSize(exportMaxFontSize)
+}
+
+func (e *pdfExporter) drawCard(txt *string, imageKey *string, x, y, w, h float64) {
+	e.pdf.SetDrawColor(160, 160, 160)
+	e.pdf.SetDashPattern([]float64{2, 2}, 0)
+	e.pdf.Rect(x, y, w, h, "D")
+	e.pdf.SetDashPattern([]float64{}, 0)
+
+	x += exportCardPadding
+	y += exportCardPadding
+	w -= 2 * exportCardPadding
+	h -= 2 * exportCardPadding
+	if name, imgW, imgH, ok := e.loadImage(imageKey); ok {
+		textH := h / 2
+		if txt == nil || strings.TrimSpace(*txt) == "" {
+			textH = 0
+		}
+		dh := e.drawImage(name, imgW, imgH, x, y, w, h-textH-1)
+		y += dh + 1
+		h -= dh + 1
+	}
+	if txt != nil {
+		e.drawBoxText(*txt, x, y, w, h)
+	}
+}
+
+/*
+flashcards lays out cards in a grid, with fronts (terms) on one page
+and backs (defs) on the next, mirrored horizontally
+so that they line up when printed double-sided (flip on long edge)
+*/
+func (e *pdfExporter) flashcards(terms []exportTerm) {
+	pageW, pageH := e.pdf.GetPageSize()
+	cardW := (pageW - 2*exportPageMargin) / exportCardCols
+	cardH := (pageH - 2*exportPageMargin) / exportCardRows
+	perPage := exportCardCols * exportCardRows
+
+	if len(terms) == 0 {
+		e.pdf.AddPage()
+		return
+	}
+	for start := 0; start < len(terms); start += perPage {
+		end := min(start+perPage, len(terms))
+
+		e.pdf.AddPage()
+		for i, t := range terms[start:end] {
+			col, row := i%exportCardCols, i/exportCardCols
+			e.drawCard(
+				t.Term, t.TermImageKey,
+				exportPageMargin+float64(col)*cardW,
+				exportPageMargin+float64(row)*cardH,
+				cardW, cardH,
+			)
+		}
+
+		e.pdf.AddPage()
+		for i, t := range terms[start:end] {
+			col, row := exportCardCols-1-i%exportCardCols, i/exportCardCols
+			e.drawCard(
+				t.Def, t.DefImageKey,
+				exportPageMargin+float64(col)*cardW,
+				exportPageMargin+float64(row)*cardH,
+				cardW, cardH,
+			)
+		}
+	}
+}
+
+/*
+quiz lays out a printable quiz: each term with blank lines to write the def,
+followed by an answer key on a new page
+*/
+func (e *pdfExporter) quiz(title string, terms []exportTerm) {
+	pageW, pageH := e.pdf.GetPageSize()
+	contentW := pageW - 2*exportPageMargin
+	bottom := pageH - exportPageMargin
+
+	e.pdf.AddPage()
+	e.pdf.SetFontSize(20)
+	_, unit := e.pdf.GetFontSize()
+	for _, line := range e.wrap(e.tr(title), contentW) {
+		e.pdf.CellFormat(contentW, unit*1.3, line, "", 1, "L", false, 0, "")
+	}
+	e.pdf.SetFontSize(11)
+	_, unit = e.pdf.GetFontSize()
+	lineH := unit * 1.6
+	e.pdf.CellFormat(contentW, lineH*1.5, e.tr("Name: ______________________________    Date: ______________"), "", 1, "L", false, 0, "")
+	e.pdf.Ln(lineH / 2)
+
+	ensureSpace := func(h float64) {
+		if e.pdf.GetY()+h > bottom {
+			e.pdf.AddPage()
+		}
+	}
+
+	numW := 12.0
+	for i, t := range terms {
+		lines := e.wrap(e.tr(derefString(t.Term)), contentW-numW)
+		name, imgW, imgH, hasImage := e.loadImage(t.TermImageKey)
+		h := float64(len(lines))*lineH + 3*lineH
+		if hasImage {
+			h += exportQuizImageHeight + 2
+		}
+		ensureSpace(h)
+
+		y := e.pdf.GetY()
+		e.pdf.SetXY(exportPageMargin, y)
+		e.pdf.CellFormat(numW, lineH, fmt.Sprintf("%d.", i+1), "", 0, "L", false, 0, "")
+		for j, line := range lines {
+			e.pdf.SetXY(exportPageMargin+numW, y+float64(j)*lineH)
+			e.pdf.CellFormat(contentW-numW, lineH, line, "", 0, "L", false, 0, "")
+		}
+		y += float64(len(lines)) * lineH
+		if hasImage {
+			scale := min((contentW-numW)/imgW, exportQuizImageHeight/imgH)
+			e.pdf.ImageOptions(name, exportPageMargin+numW, y+1, imgW*scale, imgH*scale, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+			y += exportQuizImageHeight + 2
+		}
+		e.pdf.SetDrawColor(120, 120, 120)
+		for j := 1; j <= 2; j++ {
+			lineY := y + float64(j)*lineH
+			e.pdf.Line(exportPageMargin+numW, lineY, exportPageMargin+contentW, lineY)
+		}
+		e.pdf.SetXY(exportPageMargin, y+3*lineH)
+	}
+
+	e.pdf.AddPage()
+	e.pdf.SetFontSize(16)
+	_, unit = e.pdf.GetFontSize()
+	e.pdf.CellFormat(contentW, unit*1.5, e.tr("Answer Key"), "", 1, "L", false, 0, "")
+	e.pdf.SetFontSize(11)
+	for i, t := range terms {
+		lines := e.wrap(e.tr(derefString(t.Def)), contentW-numW)
+		ensureSpace(float64(len(lines))*lineH + lineH/2)
+		y := e.pdf.GetY()
+		e.pdf.SetXY(exportPageMargin, y)
+		e.pdf.CellFormat(numW, lineH, fmt.Sprintf("%d.", i+1), "", 0, "L", false, 0, "")
+		for j, line := range lines {
+			e.pdf.SetXY(exportPageMargin+numW, y+float64(j)*lineH)
+			e.pdf.CellFormat(contentW-numW, lineH, line, "", 0, "L", false, 0, "")
+		}
+		e.pdf.SetXY(exportPageMargin, y+float64(len(lines))*lineH+lineH/2)
+	}
+}
@@ -2,8 +2,10 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"image"
 	"io"
 	"net/http"
@@ -106,56 +108,82 @@ func (rh *RESTHandler) UploadTermImage(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	if len(raw) == 0 {
+
+	webpImage, err := processTermImage(raw)
+	if err != nil {
 		render.Status(r, 400)
 		render.JSON(w, r, map[string]any{
-			"error": "empty file",
+			"error": err.Error(),
 		})
 		return
 	}
 
-	/* detect actual MIME type, ignoring user-specified Content-Type */
-	mime := http.DetectContentType(raw[:512])
-	if !isMIMEAllowed(mime) {
-		render.Status(r, 400)
+	objectKey, errMsg := rh.storeTermImage(ctx, webpImage, authedUser.ID)
+	if errMsg != "" {
+		render.Status(r, 500)
 		render.JSON(w, r, map[string]any{
-			"error": "unsupported image type",
+			"error": errMsg,
 		})
 		return
 	}
 
-	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	sql := "UPDATE terms SET term_image_key = $1 WHERE id = $2"
+	if side == "def" {
+		sql = "UPDATE terms SET def_image_key = $1 WHERE id = $2"
+	}
+	_, err = rh.DB.Exec(
+		ctx,
+		sql,
+		objectKey,
+		termID,
+	)
 	if err != nil {
-		render.Status(r, 400)
+		log.Error().Err(err).Msg("failed to update term/def image key in DB")
+		render.Status(r, 500)
 		render.JSON(w, r, map[string]any{
-			"error": "invalid image",
+			"error": "failed to update term/def image key in DB",
 		})
 		return
 	}
 
-	if cfg.Width <= 0 || cfg.Height <= 0 {
-		render.Status(r, 400)
-		render.JSON(w, r, map[string]any{
-			"error": "invalid image dimensions",
-		})
-		return
+	render.JSON(w, r, map[string]interface{}{
+		"error": false,
+		"data": map[string]interface{}{
+			"imageUrl": *rh.UsercontentBaseURL + objectKey,
+		},
+	})
+}
+
+/*
+processTermImage validates an uploaded/downloaded image (jpeg, png, or webp),
+then resizes it and encodes it as webp.
+Errors are safe to show to users.
+*/
+func processTermImage(raw []byte) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("empty file")
 	}
 
+	/* detect actual MIME type, ignoring user-specified Content-Type */
+	mime := http.DetectContentType(raw[:min(len(raw), 512)])
+	if !isMIMEAllowed(mime) {
+		return nil, errors.New("unsupported image type")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("invalid image dimensions")
+	}
 	if cfg.Width*cfg.Height > maxPixelsBefore {
-		render.Status(r, 400)
-		render.JSON(w, r, map[string]any{
-			"error": "image too large",
-		})
-		return
+		return nil, errors.New("image too large")
 	}
 
 	img, _, err := image.Decode(bytes.NewReader(raw))
 	if err != nil {
-		render.Status(r, 400)
-		render.JSON(w, r, map[string]any{
-			"error": "failed to decode image",
-		})
-		return
+		return nil, errors.New("failed to decode image")
 	}
 
 	img = imaging.Fit(img, maxWidthHeightAfter, maxWidthHeightAfter, imaging.Lanczos)
@@ -165,73 +193,48 @@ func (rh *RESTHandler) UploadTermImage(w http.ResponseWriter, r *http.Request) {
 		Quality:  webpQualityAfter,
 	})
 	if err != nil {
-		render.Status(r, 400)
-		render.JSON(w, r, map[string]any{
-			"error": "failed to encode webp",
-		})
-		return
+		return nil, errors.New("failed to encode webp")
 	}
+	return buf.Bytes(), nil
+}
 
-	hash := sha256.Sum256(buf.Bytes())
+/*
+storeTermImage uploads a processed webp image to usercontent storage
+and adds it to the images table, returning its object key.
+Object keys are based on the image's hash, so the same image is only stored once.
+userID is the user who uploaded or imported it (the latest one, for images stored more than once).
+If it fails, errMsg is an error message for the response (the actual error is logged).
+*/
+func (rh *RESTHandler) storeTermImage(ctx context.Context, webpImage []byte, userID *string) (objectKey string, errMsg string) {
+	hash := sha256.Sum256(webpImage)
 	hashStr := hex.EncodeToString(hash[:])[:32]
-	objectKey := "images/" + hashStr[:2] + "/" + hashStr[2:4] + "/" + hashStr + ".webp"
+	objectKey = "images/" + hashStr[:2] + "/" + hashStr[2:4] + "/" + hashStr + ".webp"
 
-	_, err = rh.Storage.PutObject(ctx, &s3.PutObjectInput{
+	_, err := rh.Storage.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:       rh.UsercontentBucket,
 		Key:          aws.String(objectKey),
-		Body:         bytes.NewReader(buf.Bytes()),
+		Body:         bytes.NewReader(webpImage),
 		ContentType:  aws.String("image/webp"),
 		CacheControl: aws.String("public, max-age=31536000, immutable"),
 	})
-
 	if err != nil {
 		log.Error().Err(err).Msg("failed to upload to s3")
-		render.Status(r, 500)
-		render.JSON(w, r, map[string]any{
-			"error": "failed to upload image to storage",
-		})
-		return
+		return "", "failed to upload image to storage"
 	}
 
+	/* created_at is bumped for existing images, so termImageCleanupJob doesn't delete them before they're used */
 	_, err = rh.DB.Exec(
 		ctx,
-		"INSERT INTO images (object_key) VALUES ($1) ON CONFLICT DO NOTHING",
+		`INSERT INTO images (object_key, user_id) VALUES ($1, $2)
+		ON CONFLICT (object_key) DO UPDATE SET created_at = now(), user_id = excluded.user_id`,
 		objectKey,
+		userID,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to insert image object key in DB")
-		render.Status(r, 500)
-		render.JSON(w, r, map[string]any{
-			"error": "failed to insert image object key in DB",
-		})
-		return
-	}
-
-	sql := "UPDATE terms SET term_image_key = $1 WHERE id = $2"
-	if side == "def" {
-		sql = "UPDATE terms SET def_image_key = $1 WHERE id = $2"
+		return "", "failed to insert image object key in DB"
 	}
-	_, err = rh.DB.Exec(
-		ctx,
-		sql,
-		objectKey,
-		termID,
-	)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to update term/def image key in DB")
-		render.Status(r, 500)
-		render.JSON(w, r, map[string]any{
-			"error": "failed to update term/def image key in DB",
-		})
-		return
-	}
-
-	render.JSON(w, r, map[string]interface{}{
-		"error": false,
-		"data": map[string]interface{}{
-			"imageUrl": *rh.UsercontentBaseURL + objectKey,
-		},
-	})
+	return objectKey, ""
 }
 
 func isMIMEAllowed(m string) bool {
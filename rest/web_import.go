@@ -3,269 +3,511 @@ package rest
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"github.com/PuerkitoBio/goquery"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog/log"
-	"io"
 	"net/http"
-	"net/url"
+	"quizfreely/api/auth"
+	"quizfreely/api/webimport"
+	"runtime/debug"
 	"time"
+	// "io"
 	// "os"
 	// "path/filepath"
 	"strings"
+	"sync"
 )
 
+/* pending/running jobs older than this are from a restarted/crashed server, so they're marked as failed */
+const webImportStaleJobAge = 15 * time.Minute
+
+/* how often WebImport checks if the job it's waiting for is done */
+const webImportPollInterval = 500 * time.Millisecond
+
+/* how many images are downloaded & processed at once for a job */
+const webImportImageWorkers = 4
+
+var errInvalidJobID = errors.New("invalid web import job id")
+
+type webImportJob struct {
+	ID             string            `json:"id" db:"id"`
+	URL            string            `json:"url" db:"url"`
+	Source         string            `json:"source" db:"source"`
+	Status         string            `json:"status" db:"status"`
+	DownloadImages bool              `json:"downloadImages" db:"download_images"`
+	Result         *webimport.Result `json:"result" db:"result"`
+	Error          *string           `json:"error" db:"error"`
+	Cached         bool              `json:"cached" db:"cached"`
+	CreatedAt      *string           `json:"createdAt" db:"created_at"`
+	FinishedAt     *string           `json:"finishedAt" db:"finished_at"`
+	Inserted       bool              `json:"-" db:"inserted"`
+}
+
+const webImportJobCols = `id, url, source, status, download_images, result, error,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(finished_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as finished_at`
+
+/*
+WebImport imports a studyset synchronously (the request stays open until it's done).
+It uses the same jobs as CreateWebImportJob, so it shares cached results
+and concurrent imports of the same url.
+*/
 func (rh *RESTHandler) WebImport(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var reqBody struct {
-		URL string `json:"url"`
-	}
-	err := json.NewDecoder(r.Body).Decode(&reqBody)
-	if err != nil {
-		render.Status(r, 400)
+	job, status, errMsg := rh.startWebImportJob(r)
+	if job == nil {
+		render.Status(r, status)
 		render.JSON(w, r, map[string]any{
-			"error": "invalid request body",
+			"error": errMsg,
 		})
 		return
 	}
 
-	if !strings.HasPrefix(reqBody.URL, "https://quizlet.com") {
-		render.Status(r, 400)
+	ticker := time.NewTicker(webImportPollInterval)
+	defer ticker.Stop()
+	for job.Status == "PENDING" || job.Status == "RUNNING" {
+		select {
+		case <-ctx.Done():
+			/* the job keeps running in the background, so it'll still be cached */
+			return
+		case <-ticker.C:
+		}
+		var err error
+		job, err = rh.getWebImportJob(ctx, job.ID)
+		if err != nil {
+			log.Error().Err(err).Msg("DB error getting web import job in WebImport")
+			render.Status(r, 500)
+			render.JSON(w, r, map[string]any{
+				"error": "DB error getting web import job",
+			})
+			return
+		}
+	}
+
+	if job.Status != "SUCCEEDED" || job.Result == nil {
+		render.Status(r, 500)
 		render.JSON(w, r, map[string]any{
-			"error": "url must be a quizlet link, inputted url doesn't start with \"https://quizlet.com\"",
+			"error": derefString(job.Error),
 		})
 		return
 	}
 
-	var reader io.Reader
-	if rh.UseZyte && (rh.TryZyteBeforeCrawlbase || !rh.UseCrawlbase) {
-		reader, err = rh.zyteReq(reqBody.URL, ctx)
-	} else if rh.UseCrawlbase {
-		reader, err = rh.crawlbaseReq(reqBody.URL, ctx)
-	} else {
-		log.Error().Err(err).Msg("web import unavailable. use_crawlbase and use_zyte are both disabled/false. check config.toml")
-		render.Status(r, 503)
+	terms := make([][]string, 0, len(job.Result.Terms))
+	for _, term := range job.Result.Terms {
+		terms = append(terms, []string{term.Term, term.Def})
+	}
+	render.JSON(w, r, map[string]any{
+		"source": job.Source,
+		"title":  job.Result.Title,
+		"terms":  terms,
+		/* terms with rich text, images, and extra sides */
+		"result": job.Result,
+	})
+}
+
+/*
+CreateWebImportJob starts importing a studyset in the background,
+responding with the job right away (poll GetWebImportJob for the result).
+If the url was imported recently, the cached job is returned (already SUCCEEDED),
+and if the url is already being imported, that job is returned instead of starting another one.
+*/
+func (rh *RESTHandler) CreateWebImportJob(w http.ResponseWriter, r *http.Request) {
+	job, status, errMsg := rh.startWebImportJob(r)
+	if job == nil {
+		render.Status(r, status)
 		render.JSON(w, r, map[string]any{
-			"error": "web import unavailable because use_crawlbase and use_zyte are both disabled/false",
+			"error": errMsg,
 		})
 		return
 	}
 
-	if err != nil && rh.UseZyte && rh.TryZyteBeforeCrawlbase && rh.UseCrawlbase {
-		log.Error().Err(err).Msg("web import zyte err on first try")
-		reader, err = rh.crawlbaseReq(reqBody.URL, ctx)
-	} else if err != nil && rh.UseCrawlbase && rh.UseZyte {
-		log.Error().Err(err).Msg("web import crawlbase err on first try")
-		reader, err = rh.zyteReq(reqBody.URL, ctx)
+	if job.Status == "PENDING" || job.Status == "RUNNING" {
+		render.Status(r, 202)
 	}
+	render.JSON(w, r, map[string]any{
+		"error": false,
+		"data":  job,
+	})
+}
 
-	if err != nil {
-		log.Error().Err(err).Msg("web import err w crawlbase/zyte req")
-		render.Status(r, 500)
+func (rh *RESTHandler) GetWebImportJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		render.Status(r, 400)
 		render.JSON(w, r, map[string]any{
-			"error": "error with crawlbase/zyte request",
+			"error": "Missing jobID in URL",
 		})
 		return
 	}
 
-	terms, err := parse(reader)
+	job, err := rh.getWebImportJob(ctx, jobID)
 	if err != nil {
-
-		/* for debugging ONLY */
-		// tmpPath, tmpErr := saveToTempFile(reader)
-		// if tmpErr == nil {
-		// 	log.Error().Err(err).Msg("err parsing. saved tmp file to inspect: " + tmpPath)
-		// }
-
-		log.Error().Err(err).Msg("web import parsing err")
+		/* invalid ids can't be jobs, so they're not found instead of a DB error */
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, errInvalidJobID) {
+			render.Status(r, 404)
+			render.JSON(w, r, map[string]any{
+				"error": "web import job not found",
+			})
+			return
+		}
+		log.Error().Err(err).Msg("DB error getting web import job in GetWebImportJob")
 		render.Status(r, 500)
 		render.JSON(w, r, map[string]any{
-			"error": "error parsing terms",
+			"error": "DB error getting web import job",
 		})
 		return
 	}
 
 	render.JSON(w, r, map[string]any{
-		"terms": terms,
+		"error": false,
+		"data":  job,
 	})
 }
 
-func (rh *RESTHandler) crawlbaseReq(targetURL string, reqCtx context.Context) (io.Reader, error) {
-	log.Trace().Msg("crawlbase attempted")
-	ctx, cancel := context.WithTimeout(reqCtx, 90*time.Second)
-	defer cancel()
-
-	params := url.Values{}
-	params.Add("token", rh.CrawlbaseAPIKey)
-	params.Add("url", targetURL)
-	req, err := http.NewRequestWithContext(
+func (rh *RESTHandler) getWebImportJob(ctx context.Context, jobID string) (*webImportJob, error) {
+	if _, err := uuid.Parse(jobID); err != nil {
+		return nil, errInvalidJobID
+	}
+	var job webImportJob
+	err := pgxscan.Get(
 		ctx,
-		http.MethodGet,
-		"https://api.crawlbase.com/?"+params.Encode(),
-		nil,
+		rh.DB,
+		&job,
+		`SELECT `+webImportJobCols+`, false as cached
+		FROM web_import_jobs
+		WHERE id = $1`,
+		jobID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	return &job, nil
+}
 
-	resp, err := rh.HTTPClient.Do(req)
+/*
+startWebImportJob reads the url (and downloadImages option) from the request body, then returns a cached job,
+an already pending/running job for the same url, or a new job (which starts running in the background).
+If job is nil, it also returns an http status and error message for the response.
+*/
+func (rh *RESTHandler) startWebImportJob(r *http.Request) (*webImportJob, int, string) {
+	ctx := r.Context()
+
+	var reqBody struct {
+		URL string `json:"url"`
+		/* download images to usercontent storage, so they can be used with createTerms */
+		DownloadImages bool `json:"downloadImages"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
 	if err != nil {
-		return nil, err
+		return nil, 400, "invalid request body"
 	}
-	defer resp.Body.Close()
 
-	buf := new(bytes.Buffer)
-	if _, err := io.Copy(buf, resp.Body); err != nil {
-		return nil, err
+	importer, fetchURL, err := rh.WebImporters.Lookup(reqBody.URL)
+	if err != nil {
+		return nil, 400, "unsupported url, web import supports https links from " + strings.Join(rh.WebImporters.Hosts(), ", ")
+	}
+	normalizedURL, err := webimport.NormalizeURL(fetchURL)
+	if err != nil {
+		return nil, 400, "invalid url"
 	}
 
-	return buf, nil
-}
-
-type zyteReqBody struct {
-	URL              string `json:"url"`
-	HTTPResponseBody bool   `json:"httpResponseBody"`
-}
-type zyteRespBody struct {
-	HTTPResponseBody string `json:"httpResponseBody"`
-}
+	/* anyone can import, but downloading images writes to usercontent storage, so it needs an account */
+	authedUser := auth.AuthedUserContext(ctx)
+	var userID *string
+	if authedUser != nil && authedUser.ID != nil && *authedUser.ID != "" {
+		userID = authedUser.ID
+	}
+	if reqBody.DownloadImages && userID == nil {
+		return nil, 401, "not authenticated, sign in to download images with web import"
+	}
 
-func (rh *RESTHandler) zyteReq(targetURL string, reqCtx context.Context) (io.Reader, error) {
-	log.Trace().Msg("zyte attempted")
-	ctx, cancel := context.WithTimeout(reqCtx, 90*time.Second)
-	defer cancel()
+	if reqBody.DownloadImages && rh.Storage == nil {
+		return nil, 503, "downloading images unavailable because storage is not enabled/configured"
+	}
 
-	reqBodyJSON, err := json.Marshal(
-		zyteReqBody{
-			URL:              targetURL,
-			HTTPResponseBody: true,
-		},
-	)
-	if err != nil {
-		return nil, err
+	if rh.WebImportFetchers.Len() == 0 {
+		log.Error().Msg("web import unavailable. no fetchers configured. check web_import_fetchers in config.toml")
+		return nil, 503, "web import unavailable because no fetchers are configured"
 	}
 
-	req, err := http.NewRequestWithContext(
+	/* mark stale jobs for this url as failed, so they don't block new jobs forever */
+	_, err = rh.DB.Exec(
 		ctx,
-		http.MethodPost,
-		"https://api.zyte.com/v1/extract",
-		bytes.NewBuffer(reqBodyJSON),
+		`UPDATE web_import_jobs
+		SET status = 'FAILED', error = 'web import job timed out', finished_at = now()
+		WHERE normalized_url = $1
+		AND status IN ('PENDING', 'RUNNING')
+		AND created_at < now() - make_interval(secs => $2)`,
+		normalizedURL,
+		webImportStaleJobAge.Seconds(),
 	)
 	if err != nil {
-		return nil, err
+		log.Error().Err(err).Msg("DB error marking stale web import jobs")
+		return nil, 500, "DB error creating web import job"
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(rh.ZyteAPIKey, "")
+	/* cached result */
+	if rh.WebImportCacheTTL > 0 {
+		var cachedJobs []*webImportJob
+		err = pgxscan.Select(
+			ctx,
+			rh.DB,
+			&cachedJobs,
+			`SELECT `+webImportJobCols+`, true as cached
+			FROM web_import_jobs
+			WHERE normalized_url = $1
+			AND download_images = $2
+			AND status = 'SUCCEEDED'
+			AND finished_at > now() - make_interval(secs => $3)
+			ORDER BY finished_at DESC
+			LIMIT 1`,
+			normalizedURL,
+			reqBody.DownloadImages,
+			rh.WebImportCacheTTL.Seconds(),
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("DB error getting cached web import job")
+			return nil, 500, "DB error creating web import job"
+		}
+		if len(cachedJobs) > 0 {
+			return cachedJobs[0], 200, ""
+		}
+	}
 
-	resp, err := rh.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
+	/* new job, or the job that's already pending/running for this url (and downloadImages option).
+	rows from the CTE's insert aren't visible to the other select (same snapshot),
+	so one row comes back: the inserted job or the existing one.
+	no rows come back if the existing job finished (or was committed by another request)
+	after this statement's snapshot, so that's retried */
+	for range 3 {
+		var jobs []*webImportJob
+		err = pgxscan.Select(
+			ctx,
+			rh.DB,
+			&jobs,
+			`WITH inserted AS (
+				INSERT INTO web_import_jobs (url, normalized_url, source, download_images, user_id)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (normalized_url, download_images) WHERE status IN ('PENDING', 'RUNNING') DO NOTHING
+				RETURNING *
+			)
+			SELECT `+webImportJobCols+`, false as cached, true as inserted FROM inserted
+			UNION ALL
+			SELECT `+webImportJobCols+`, false as cached, false as inserted FROM web_import_jobs
+			WHERE normalized_url = $2 AND download_images = $4 AND status IN ('PENDING', 'RUNNING')`,
+			reqBody.URL,
+			normalizedURL,
+			importer.Name(),
+			reqBody.DownloadImages,
+			userID,
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("DB error creating web import job")
+			return nil, 500, "DB error creating web import job"
+		}
+		if len(jobs) == 0 {
+			continue
+		}
+
+		job := jobs[0]
+		if job.Inserted {
+			go rh.runWebImportJob(job.ID, importer, fetchURL, job.DownloadImages, userID)
+		}
+		return job, 202, ""
+	}
+	return nil, 409, "web import job for this url just changed, try again"
+}
+
+/*
+runWebImportJob fetches & parses the page, then saves the result or error in the job.
+It runs in its own goroutine, so it doesn't use the request's context
+(the job keeps going even if the client disconnects).
+userID is the user who started the job (nil for anonymous imports, which can't download images).
+*/
+func (rh *RESTHandler) runWebImportJob(jobID string, importer webimport.Importer, fetchURL string, downloadImages bool, userID *string) {
+	timeout := rh.WebImportFetchers.Timeout() + time.Minute
+	if downloadImages {
+		timeout += 5 * time.Minute
 	}
-	defer resp.Body.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	body, err := io.ReadAll(resp.Body)
+	/* ctx might be past its deadline when the fetch or image downloads time out,
+	but the job's status still needs to be saved, so it doesn't stay RUNNING */
+	statusCtx := context.WithoutCancel(ctx)
+
+	failJob := func(errMsg string) {
+		_, err := rh.DB.Exec(
+			statusCtx,
+			`UPDATE web_import_jobs
+			SET status = 'FAILED', error = $2, finished_at = now()
+			WHERE id = $1`,
+			jobID,
+			errMsg,
+		)
+		if err != nil {
+			log.Error().Err(err).Str("job", jobID).Msg("DB error saving failed web import job")
+		}
+	}
+	/* jobs run outside of the router (and importers parse untrusted html), so a panic would crash the whole api */
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error().Str("job", jobID).Str("importer", importer.Name()).Interface("panic", p).Bytes("stack", debug.Stack()).Msg("web import job panicked")
+			failJob("error importing page")
+		}
+	}()
+
+	_, err := rh.DB.Exec(
+		ctx,
+		"UPDATE web_import_jobs SET status = 'RUNNING', started_at = now() WHERE id = $1",
+		jobID,
+	)
 	if err != nil {
-		return nil, err
+		log.Error().Err(err).Str("job", jobID).Msg("DB error starting web import job")
 	}
 
-	var respBody zyteRespBody
-	err = json.Unmarshal(body, &respBody)
+	page, fetcherName, err := rh.WebImportFetchers.Fetch(ctx, fetchURL)
 	if err != nil {
-		return nil, err
+		log.Error().Err(err).Str("job", jobID).Msg("web import err, every fetcher failed")
+		if errors.Is(err, webimport.ErrPageTooLarge) {
+			failJob("page too large")
+		} else {
+			failJob("error fetching page")
+		}
+		return
 	}
 
-	decodedBody, err := base64.StdEncoding.DecodeString(respBody.HTTPResponseBody)
+	result, err := importer.Parse(bytes.NewReader(page))
 	if err != nil {
-		return nil, err
+
+		/* for debugging ONLY */
+		// tmpPath, tmpErr := saveToTempFile(bytes.NewReader(page))
+		// if tmpErr == nil {
+		// 	log.Error().Err(err).Msg("err parsing. saved tmp file to inspect: " + tmpPath)
+		// }
+
+		log.Error().Err(err).Str("job", jobID).Str("importer", importer.Name()).Str("fetcher", fetcherName).Msg("web import parsing err")
+		failJob("error parsing terms")
+		return
 	}
 
-	return bytes.NewReader(decodedBody), nil
-}
+	if downloadImages {
+		rh.downloadWebImportImages(ctx, jobID, result, userID)
+	}
 
-func parse(reader io.Reader) ([][]string, error) {
-	doc, err := goquery.NewDocumentFromReader(reader)
+	_, err = rh.DB.Exec(
+		statusCtx,
+		`UPDATE web_import_jobs
+		SET status = 'SUCCEEDED', fetcher = $2, result = $3, finished_at = now()
+		WHERE id = $1`,
+		jobID,
+		fetcherName,
+		result,
+	)
 	if err != nil {
-		return nil, err
+		log.Error().Err(err).Str("job", jobID).Msg("DB error saving web import job result")
+		failJob("DB error saving result")
 	}
+}
 
-	rawNextData := doc.Find(`script#__NEXT_DATA__`).Text()
-	if rawNextData == "" {
-		// TODO: hmm
-		return nil, errors.New("no __NEXT_DATA__ :(")
-	}
-
-	var nextData struct {
-		Props struct {
-			PageProps struct {
-				DehydratedReduxStateKey json.RawMessage `json:"dehydratedReduxStateKey"`
-			} `json:"pageProps"`
-		} `json:"props"`
-	}
-	var dhrsk struct {
-		StudyModesCommon struct {
-			StudiableData struct {
-				StudiableItems []struct {
-					IsDeleted bool `json:"isDeleted"`
-					CardSides []struct {
-						SideID int `json:"sideId"`
-						Media  []struct {
-							PlainText string  `json:"plainText"`
-							RichText  *string `json:"richText"`
-						} `json:"media"`
-					} `json:"cardSides"`
-				} `json:"studiableItems"`
-			} `json:"studiableData"`
-		} `json:"StudyModesCommon"`
-	}
-	if err := json.Unmarshal([]byte(rawNextData), &nextData); err != nil {
-		return nil, err
+/*
+downloadWebImportImages downloads every image in the result,
+then processes & stores them like UploadTermImage does.
+Images that fail are logged and skipped (they keep the site's image url),
+so one broken image doesn't fail the whole import.
+*/
+func (rh *RESTHandler) downloadWebImportImages(ctx context.Context, jobID string, result *webimport.Result, userID *string) {
+	imageURLs := result.ImageURLs()
+	if len(imageURLs) == 0 {
+		return
 	}
-	trimmedRawDHRSK := bytes.TrimSpace(nextData.Props.PageProps.DehydratedReduxStateKey)
-	log.Trace().Str("first char", string(trimmedRawDHRSK[0])).Msg("hmm")
-	if len(trimmedRawDHRSK) == 0 {
-		// TODO: hmm
-		return nil, errors.New("empty dehydratedReduxStateKey in __NEXT_DATA__")
+
+	var mu sync.Mutex
+	keys := make(map[string]string, len(imageURLs))
+	urls := make(chan string)
+	var wg sync.WaitGroup
+	for range min(webImportImageWorkers, len(imageURLs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for imageURL := range urls {
+				objectKey, ok := rh.downloadWebImportImage(ctx, jobID, imageURL, userID)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				keys[imageURL] = objectKey
+				mu.Unlock()
+			}
+		}()
 	}
-	if trimmedRawDHRSK[0] == '"' {
-		var dhrskString string
-		if err := json.Unmarshal(nextData.Props.PageProps.DehydratedReduxStateKey, &dhrskString); err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal([]byte(dhrskString), &dhrsk); err != nil {
-			return nil, err
-		}
-	} else if trimmedRawDHRSK[0] == '{' {
-		if err := json.Unmarshal(nextData.Props.PageProps.DehydratedReduxStateKey, &dhrsk); err != nil {
-			return nil, err
-		}
-	} else {
-		return nil, errors.New("unexpected JSON token in dehydratedReduxStateKey in __NEXT_DATA__")
+	for _, imageURL := range imageURLs {
+		urls <- imageURL
 	}
+	close(urls)
+	wg.Wait()
 
-	var termDefPairs [][]string
-	for _, item := range dhrsk.StudyModesCommon.StudiableData.StudiableItems {
-		sides := len(item.CardSides)
-		if sides < 1 {
-			continue
-		}
+	result.SetImageKeys(keys, *rh.UsercontentBaseURL)
+	log.Info().Str("job", jobID).Int("images", len(imageURLs)).Int("downloaded", len(keys)).Msg("web import images downloaded")
+}
 
-		term := ""
-		def := ""
-		if sides >= 1 {
-			term = item.CardSides[0].Media[0].PlainText
+/*
+downloadWebImportImage downloads, processes & stores one image for downloadWebImportImages.
+If it fails (or panics), it's logged and ok is false.
+*/
+func (rh *RESTHandler) downloadWebImportImage(ctx context.Context, jobID string, imageURL string, userID *string) (objectKey string, ok bool) {
+	/* workers are separate goroutines from runWebImportJob (so its recover doesn't cover them),
+	and image decoders get untrusted bytes, so a panic only fails this image */
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error().Str("job", jobID).Str("imageUrl", imageURL).Interface("panic", p).Bytes("stack", debug.Stack()).Msg("web import image panicked")
+			objectKey, ok = "", false
 		}
-		if sides >= 2 {
-			def = item.CardSides[1].Media[0].PlainText
-		}
-		termDefPairs = append(termDefPairs, []string{term, def})
+	}()
+
+	raw, err := rh.WebImportImages.Download(ctx, imageURL)
+	if err != nil {
+		log.Warn().Err(err).Str("job", jobID).Str("imageUrl", imageURL).Msg("web import image download failed")
+		return "", false
+	}
+	webpImage, err := processTermImage(raw)
+	if err != nil {
+		log.Warn().Err(err).Str("job", jobID).Str("imageUrl", imageURL).Msg("web import image processing failed")
+		return "", false
+	}
+	objectKey, errMsg := rh.storeTermImage(ctx, webpImage, userID)
+	if errMsg != "" {
+		return "", false
 	}
+	return objectKey, true
+}
 
-	return termDefPairs, nil
+/* WebImportMetrics returns per-fetcher metrics, only for moderators */
+func (rh *RESTHandler) WebImportMetrics(w http.ResponseWriter, r *http.Request) {
+	authedUser := auth.AuthedUserContext(r.Context())
+	if authedUser == nil || authedUser.ModPerms == nil || !*authedUser.ModPerms {
+		render.Status(r, 403)
+		render.JSON(w, r, map[string]any{
+			"error": "only moderators can view web import metrics",
+		})
+		return
+	}
+
+	render.JSON(w, r, map[string]any{
+		"error": false,
+		"data": map[string]any{
+			"fetchers": rh.WebImportFetchers.Names(),
+			"metrics":  rh.WebImportFetchers.Metrics(),
+		},
+	})
 }
 
 // saveToTempFile dumps the raw bytes into a temporary file on the server (for debugging only)
@@ -0,0 +1,49 @@
+/*
+Package safedial makes dialers for requests to untrusted urls (like imported images & users' reminder webhooks),
+which can't connect to private, loopback, or link-local addresses
+*/
+package safedial
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+/* 100.64.0.0/10, carrier-grade NAT, which IsPrivate doesn't include */
+var carrierGradeNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!carrierGradeNAT.Contains(addr)
+}
+
+/*
+NewDialer's connections to non-public addresses (see IsPublicAddr) fail,
+they're checked after DNS resolution, so hostnames that resolve to private addresses are blocked too
+*/
+func NewDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("connection to non-public address %s blocked", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
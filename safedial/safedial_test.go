@@ -0,0 +1,37 @@
+package safedial
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"::ffff:93.184.216.34":   true,
+	}
+	for addr, public := range cases {
+		require.Equal(t, public, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestNewDialer(t *testing.T) {
+	_, err := NewDialer(time.Second).Dial("tcp", "127.0.0.1:1")
+	require.ErrorContains(t, err, "non-public address")
+}
@@ -9,6 +9,7 @@ import (
 	"quizfreely/api/graph/resolver"
 	"quizfreely/api/rest"
 	"quizfreely/api/server/middleware"
+	"quizfreely/api/webimport"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -27,21 +28,15 @@ import (
 func NewRouter(config qzfrAPIConfig.Config, dbPool *pgxpool.Pool, s3Client *s3.Client) http.Handler {
 	router := chi.NewRouter()
 
-	sharedClient := &http.Client{
-		Timeout: 90 * time.Second, // prevents server from hanging forever. it's long & overridden later with context
-	}
 	authHandler := &auth.AuthHandler{DB: dbPool}
 	restHandler := &rest.RESTHandler{
-		DB:                     dbPool,
-		Storage:                s3Client,
-		UsercontentBucket:      &config.UsercontentBucket,
-		UsercontentBaseURL:     &config.UsercontentBaseURL,
-		HTTPClient:             sharedClient,
-		UseCrawlbase:           config.UseCrawlbase,
-		CrawlbaseAPIKey:        config.CrawlbaseAPIKey,
-		UseZyte:                config.UseZyte,
-		ZyteAPIKey:             config.ZyteAPIKey,
-		TryZyteBeforeCrawlbase: config.TryZyteBeforeCrawlbase,
+		DB:                 dbPool,
+		Storage:            s3Client,
+		UsercontentBucket:  &config.UsercontentBucket,
+		UsercontentBaseURL: &config.UsercontentBaseURL,
+		PDFFontPath:        config.PDFFontPath,
+		WebImporters:       webimport.DefaultRegistry(),
+		WebImportFetchers:  webimport.NewChain(),
 	}
 
 	router.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +61,15 @@ func NewRouter(config qzfrAPIConfig.Config, dbPool *pgxpool.Pool, s3Client *s3.C
 		"/v0/auth/delete-account",
 		authHandler.DeleteAccount,
 	)
+	/* reminders link here, so it uses the unsubscribe token instead of auth */
+	router.Get(
+		"/reminders/unsubscribe",
+		restHandler.UnsubscribeReminders,
+	)
+	router.Post(
+		"/reminders/unsubscribe",
+		restHandler.UnsubscribeReminders,
+	)
 
 	if config.EnableOAuthGoogle {
 		/* init oauth config here,
@@ -95,16 +99,48 @@ func NewRouter(config qzfrAPIConfig.Config, dbPool *pgxpool.Pool, s3Client *s3.C
 			log.Warn().Msg("web_import_rate_limit_dur is not >= 1. defaulting to 10 (s) instead. check config.toml")
 		}
 
+		webImportFetchers, err := webimport.ChainFromConfig(config)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid web import fetcher config. check config.toml")
+		}
+		log.Info().Strs("fetchers", webImportFetchers.Names()).Msg("web import fetchers")
+		restHandler.WebImportFetchers = webImportFetchers
+		restHandler.WebImportImages = webimport.NewImageDownloader(config.WebImportUserAgent)
+
+		if config.WebImportCacheTTL > 0 {
+			restHandler.WebImportCacheTTL = time.Duration(config.WebImportCacheTTL) * time.Minute
+		}
+
+		/* one limiter for both endpoints, so sync & async imports share the same limit */
+		webImportRateLimit := httprate.Limit(
+			rateLimitReq,
+			time.Duration(rateLimitDur)*time.Second,
+			httprate.WithKeyFuncs(httprate.KeyByIP),
+		)
 		router.With(
-			httprate.Limit(
-				rateLimitReq,
-				time.Duration(rateLimitDur)*time.Second,
-				httprate.WithKeyFuncs(httprate.KeyByIP, httprate.KeyByEndpoint),
-			),
+			webImportRateLimit,
+			authHandler.AuthMiddleware,
 		).Post(
 			"/web-import",
 			restHandler.WebImport,
 		)
+		router.With(
+			webImportRateLimit,
+			authHandler.AuthMiddleware,
+		).Post(
+			"/web-import/jobs",
+			restHandler.CreateWebImportJob,
+		)
+		router.Get(
+			"/web-import/jobs/{jobID}",
+			restHandler.GetWebImportJob,
+		)
+		router.With(
+			authHandler.AuthMiddleware,
+		).Get(
+			"/web-import/metrics",
+			restHandler.WebImportMetrics,
+		)
 	}
 
 	basePath := config.BasePath
@@ -118,8 +154,9 @@ func NewRouter(config qzfrAPIConfig.Config, dbPool *pgxpool.Pool, s3Client *s3.C
 		r.Use(middleware.TimezoneMiddleware)
 
 		h := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &resolver.Resolver{
-			DB:                 dbPool,
-			UsercontentBaseURL: &config.UsercontentBaseURL,
+			DB:                      dbPool,
+			UsercontentBaseURL:      &config.UsercontentBaseURL,
+			IdempotencyKeyRetention: time.Duration(config.IdempotencyKeyRetention) * time.Hour,
 		}}))
 
 		h.AddTransport(transport.Options{})
@@ -150,6 +187,10 @@ func NewRouter(config qzfrAPIConfig.Config, dbPool *pgxpool.Pool, s3Client *s3.C
 			"/v0/search-queries",
 			restHandler.GetSearchQueries,
 		)
+		r.Get(
+			"/v0/studysets/{studysetID}/export",
+			restHandler.ExportStudyset,
+		)
 
 		if s3Client != nil {
 			r.Put(
@@ -0,0 +1,155 @@
+package streak
+
+import (
+	"sort"
+	"time"
+)
+
+type Review struct {
+	ReviewedAt time.Time
+	TermID     string
+	StudysetID string
+	/*
+		match reviews are all saved at the end of the activity,
+		so they aren't used for study time (the match's duration is used instead)
+	*/
+	Match bool
+}
+
+type MatchActivity struct {
+	EndedAt  time.Time
+	Duration time.Duration
+}
+
+type Period struct {
+	/* first day of the period, at midnight UTC like Date */
+	Start      time.Time
+	Reviews    int
+	StudyTime  time.Duration
+	Studysets  int
+	Terms      int
+	ActiveDays int
+}
+
+type Calendar struct {
+	/* one for every day from `from` to `to`, including days without reviews */
+	Days []Period
+	/* weeks start on Monday, weeks & months only count days from `from` to `to` */
+	Weeks  []Period
+	Months []Period
+}
+
+/* StudyDate returns the date of the study day t is in (days start at rolloverHour in loc), like Date */
+func StudyDate(t time.Time, loc *time.Location, rolloverHour int32) time.Time {
+	return Date(t.In(loc).Add(-time.Duration(rolloverHour) * time.Hour))
+}
+
+/*
+BuildCalendar groups reviews and match activities into study days (see StudyDate) from `from` to `to` (dates like Date),
+and rolls the days up into weeks and months.
+Study time is estimated like StudyTime for each day's reviews, plus match activities' durations
+*/
+func BuildCalendar(reviews []Review, matches []MatchActivity, from time.Time, to time.Time, loc *time.Location, rolloverHour int32) Calendar {
+	type periodSets struct {
+		period     *Period
+		studysets  map[string]bool
+		terms      map[string]bool
+		timestamps []time.Time
+	}
+	newPeriodSets := func(start time.Time) *periodSets {
+		return &periodSets{
+			period:    &Period{Start: start},
+			studysets: make(map[string]bool),
+			terms:     make(map[string]bool),
+		}
+	}
+
+	var days []*periodSets
+	dayIndex := make(map[time.Time]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayIndex[day] = len(days)
+		days = append(days, newPeriodSets(day))
+	}
+
+	sorted := make([]Review, len(reviews))
+	copy(sorted, reviews)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ReviewedAt.Before(sorted[j].ReviewedAt)
+	})
+	for _, review := range sorted {
+		i, exists := dayIndex[StudyDate(review.ReviewedAt, loc, rolloverHour)]
+		if !exists {
+			continue
+		}
+		day := days[i]
+		day.period.Reviews++
+		if review.StudysetID != "" {
+			day.studysets[review.StudysetID] = true
+		}
+		if review.TermID != "" {
+			day.terms[review.TermID] = true
+		}
+		if !review.Match {
+			day.timestamps = append(day.timestamps, review.ReviewedAt)
+		}
+	}
+	for _, match := range matches {
+		if i, exists := dayIndex[StudyDate(match.EndedAt, loc, rolloverHour)]; exists {
+			days[i].period.StudyTime += match.Duration
+		}
+	}
+
+	var calendar Calendar
+	var week, month *periodSets
+	rollUp := func(rollup *periodSets, day *periodSets) {
+		rollup.period.Reviews += day.period.Reviews
+		rollup.period.StudyTime += day.period.StudyTime
+		if day.period.Reviews > 0 || day.period.StudyTime > 0 {
+			rollup.period.ActiveDays++
+		}
+		for id := range day.studysets {
+			rollup.studysets[id] = true
+		}
+		for id := range day.terms {
+			rollup.terms[id] = true
+		}
+	}
+	finish := func(p *periodSets) Period {
+		p.period.Studysets = len(p.studysets)
+		p.period.Terms = len(p.terms)
+		return *p.period
+	}
+	for _, day := range days {
+		day.period.StudyTime += StudyTime(day.timestamps)
+		if day.period.Reviews > 0 || day.period.StudyTime > 0 {
+			day.period.ActiveDays = 1
+		}
+
+		weekStart := day.period.Start.AddDate(0, 0, -((int(day.period.Start.Weekday()) + 6) % 7))
+		if week == nil || !week.period.Start.Equal(weekStart) {
+			if week != nil {
+				calendar.Weeks = append(calendar.Weeks, finish(week))
+			}
+			week = newPeriodSets(weekStart)
+		}
+		rollUp(week, day)
+
+		monthStart := time.Date(day.period.Start.Year(), day.period.Start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if month == nil || !month.period.Start.Equal(monthStart) {
+			if month != nil {
+				calendar.Months = append(calendar.Months, finish(month))
+			}
+			month = newPeriodSets(monthStart)
+		}
+		rollUp(month, day)
+
+		calendar.Days = append(calendar.Days, finish(day))
+	}
+	if week != nil {
+		calendar.Weeks = append(calendar.Weeks, finish(week))
+	}
+	if month != nil {
+		calendar.Months = append(calendar.Months, finish(month))
+	}
+	return calendar
+}
@@ -0,0 +1,90 @@
+package streak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	reviews := []Review{
+		{ReviewedAt: at(10, 15, 14, 1), TermID: "b", StudysetID: "s1"},
+		{ReviewedAt: at(10, 15, 14, 0), TermID: "a", StudysetID: "s1"},
+		/* more than MaxReviewGap later, only MaxReviewGap is counted */
+		{ReviewedAt: at(10, 15, 14, 10), TermID: "a", StudysetID: "s1"},
+		/* 3am in New York, before the rollover hour, so it's still Oct 15's study day */
+		{ReviewedAt: at(10, 16, 7, 0), TermID: "c", StudysetID: "s2"},
+		/* match reviews don't count for study time, the match's duration does */
+		{ReviewedAt: at(10, 20, 12, 0), TermID: "a", StudysetID: "s1", Match: true},
+		{ReviewedAt: at(11, 1, 12, 0), TermID: "d", StudysetID: "s3"},
+		/* before from */
+		{ReviewedAt: at(10, 10, 12, 0), TermID: "a", StudysetID: "s1"},
+	}
+	matches := []MatchActivity{
+		{EndedAt: at(10, 20, 12, 0), Duration: 45 * time.Second},
+	}
+	from := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	calendar := BuildCalendar(reviews, matches, from, to, loc, 4)
+
+	require.Len(t, calendar.Days, 19)
+	require.Equal(t, from, calendar.Days[0].Start)
+	require.Equal(t, to, calendar.Days[18].Start)
+	require.Equal(t, Period{
+		Start:      from,
+		Reviews:    4,
+		StudyTime:  5 * time.Minute,
+		Studysets:  2,
+		Terms:      3,
+		ActiveDays: 1,
+	}, calendar.Days[0])
+	require.Equal(t, Period{Start: from.AddDate(0, 0, 1)}, calendar.Days[1])
+	require.Equal(t, Period{
+		Start:      from.AddDate(0, 0, 5),
+		Reviews:    1,
+		StudyTime:  45 * time.Second,
+		Studysets:  1,
+		Terms:      1,
+		ActiveDays: 1,
+	}, calendar.Days[5])
+
+	/* weeks start on Monday, even if from isn't a Monday */
+	require.Len(t, calendar.Weeks, 4)
+	require.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), calendar.Weeks[0].Start)
+	require.Equal(t, 4, calendar.Weeks[0].Reviews)
+	require.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), calendar.Weeks[1].Start)
+	require.Equal(t, 45*time.Second, calendar.Weeks[1].StudyTime)
+	require.Equal(t, Period{Start: to}, calendar.Weeks[3])
+
+	require.Equal(t, []Period{
+		{
+			Start:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Reviews:    5,
+			StudyTime:  5*time.Minute + 45*time.Second,
+			Studysets:  2,
+			Terms:      3,
+			ActiveDays: 2,
+		},
+		{
+			Start:      time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			Reviews:    1,
+			Studysets:  1,
+			Terms:      1,
+			ActiveDays: 1,
+		},
+	}, calendar.Months)
+}
+
+func TestStudyDate(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	/* 2am Oct 20 in Tokyo, before a 4am rollover */
+	require.Equal(t, today, StudyDate(time.Date(2026, 10, 19, 17, 0, 0, 0, time.UTC), loc, 4))
+	require.Equal(t, today.AddDate(0, 0, 1), StudyDate(time.Date(2026, 10, 19, 17, 0, 0, 0, time.UTC), loc, 0))
+}
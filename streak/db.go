@@ -0,0 +1,36 @@
+package streak
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+LoadStudyDays returns the days that a user reviewed anything on (in loc, with days starting at rolloverHour),
+oldest first, as dates at midnight UTC like Compute takes
+*/
+func LoadStudyDays(ctx context.Context, db *pgxpool.Pool, userID string, loc *time.Location, rolloverHour int32) ([]time.Time, error) {
+	var days []time.Time
+	err := pgxscan.Select(
+		ctx,
+		db,
+		&days,
+		`SELECT DISTINCT ((reviewed_at AT TIME ZONE $2) - make_interval(hours => $3))::date AS day
+FROM (
+	SELECT "timestamp" AS reviewed_at FROM review_events WHERE user_id = $1
+	UNION ALL
+	SELECT review FROM fsrs_review_logs WHERE user_id = $1 AND rating <> 'MANUAL'
+) reviews
+ORDER BY day`,
+		userID,
+		loc.String(),
+		rolloverHour,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
@@ -0,0 +1,123 @@
+package streak
+
+import (
+	"time"
+)
+
+/* users can have up to this many streak freezes at once */
+const MaxFreezes = 2
+
+/* a streak freeze is earned for every this many days studied in a streak */
+const FreezeEarnDays = 7
+
+/*
+reviews more than this long after the previous review are the start of a new study session,
+so the time between them isn't counted as study time
+*/
+const MaxReviewGap = 2 * time.Minute
+
+type Streak struct {
+	/* study days in the current streak, not counting days covered by freezes */
+	Current int
+	Longest int
+	/* unused streak freezes */
+	Freezes int
+	/* days in the current streak that were missed, but covered by freezes */
+	FrozenDays []time.Time
+}
+
+/*
+Compute returns the streak for days the user studied (dates, at midnight UTC, sorted oldest first without repeats).
+Today isn't over yet, so not studying today doesn't break the streak.
+Missed days are covered by freezes (if there are enough to cover every missed day in a row),
+and freezes are earned while studying, like FreezeEarnDays.
+*/
+func Compute(studyDays []time.Time, today time.Time) Streak {
+	return compute(studyDays, today, nil)
+}
+
+/*
+FirstReached returns the first study day (from studyDays like Compute takes) that a streak reached days long,
+ok is false if no streak has been that long
+*/
+func FirstReached(studyDays []time.Time, today time.Time, days int) (day time.Time, ok bool) {
+	compute(studyDays, today, func(studyDay time.Time, current int) {
+		if !ok && current >= days {
+			day = studyDay
+			ok = true
+		}
+	})
+	return day, ok
+}
+
+/* compute is Compute, calling onStudyDay (if it isn't nil) with each study day and the streak on that day */
+func compute(studyDays []time.Time, today time.Time, onStudyDay func(day time.Time, current int)) Streak {
+	var s Streak
+	earnedDays := 0
+	/* missed days in a row before a study day (or today) are covered by freezes, or break the streak */
+	missed := func(from time.Time, to time.Time) {
+		days := daysBetween(from, to) - 1
+		if days <= 0 {
+			return
+		}
+		if days <= s.Freezes {
+			s.Freezes -= days
+			for i := 1; i <= days; i++ {
+				s.FrozenDays = append(s.FrozenDays, from.AddDate(0, 0, i))
+			}
+			return
+		}
+		s.Current = 0
+		s.FrozenDays = nil
+		earnedDays = 0
+	}
+
+	var last *time.Time
+	for _, day := range studyDays {
+		if day.After(today) {
+			break
+		}
+		if last != nil {
+			missed(*last, day)
+		}
+		s.Current++
+		s.Longest = max(s.Longest, s.Current)
+		if onStudyDay != nil {
+			onStudyDay(day, s.Current)
+		}
+		earnedDays++
+		if earnedDays == FreezeEarnDays {
+			earnedDays = 0
+			s.Freezes = min(s.Freezes+1, MaxFreezes)
+		}
+		last = &day
+	}
+	if last != nil {
+		missed(*last, today)
+	}
+	if s.FrozenDays == nil {
+		s.FrozenDays = []time.Time{}
+	}
+	return s
+}
+
+/*
+StudyTime estimates how long reviews (sorted oldest first) took from the time between them,
+not counting breaks longer than MaxReviewGap
+*/
+func StudyTime(reviews []time.Time) time.Duration {
+	var total time.Duration
+	for i := 1; i < len(reviews); i++ {
+		total += min(reviews[i].Sub(reviews[i-1]), MaxReviewGap)
+	}
+	return total
+}
+
+/* Date returns t's date (in t's location) at midnight UTC, like the dates Compute takes */
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,107 @@
+package streak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var today = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+/* daysAgo returns dates for each number of days before today, oldest first if n is sorted descending */
+func daysAgo(n ...int) []time.Time {
+	days := make([]time.Time, len(n))
+	for i, d := range n {
+		days[i] = today.AddDate(0, 0, -d)
+	}
+	return days
+}
+
+func TestCompute(t *testing.T) {
+	require.Equal(t, Streak{FrozenDays: []time.Time{}}, Compute(nil, today))
+
+	/* today isn't over, so the streak continues from yesterday */
+	s := Compute(daysAgo(3, 2, 1), today)
+	require.Equal(t, 3, s.Current)
+	require.Equal(t, 3, s.Longest)
+	s = Compute(daysAgo(3, 2, 1, 0), today)
+	require.Equal(t, 4, s.Current)
+
+	/* missing a day (without freezes) breaks the streak */
+	s = Compute(daysAgo(6, 5, 4, 3, 1, 0), today)
+	require.Equal(t, 2, s.Current)
+	require.Equal(t, 4, s.Longest)
+	s = Compute(daysAgo(4, 3, 2), today)
+	require.Equal(t, 0, s.Current)
+	require.Equal(t, 3, s.Longest)
+
+	/* days after today are ignored */
+	s = Compute(append(daysAgo(1, 0), today.AddDate(0, 0, 1)), today)
+	require.Equal(t, 2, s.Current)
+}
+
+func TestComputeFreezes(t *testing.T) {
+	/* 7 days in a row earns a freeze, which covers a missed day */
+	s := Compute(daysAgo(10, 9, 8, 7, 6, 5, 4, 2, 1), today)
+	require.Equal(t, 9, s.Current)
+	require.Equal(t, 0, s.Freezes)
+	require.Equal(t, daysAgo(3), s.FrozenDays)
+
+	/* freezes also cover missed days before today */
+	s = Compute(daysAgo(8, 7, 6, 5, 4, 3, 2), today)
+	require.Equal(t, 7, s.Current)
+	require.Equal(t, 0, s.Freezes)
+	require.Equal(t, daysAgo(1), s.FrozenDays)
+	s = Compute(daysAgo(7, 6, 5, 4, 3, 2, 1), today)
+	require.Equal(t, 7, s.Current)
+	require.Equal(t, 1, s.Freezes)
+	require.Empty(t, s.FrozenDays)
+
+	/* freezes only cover gaps if there are enough for every missed day, otherwise they're kept */
+	s = Compute(daysAgo(12, 11, 10, 9, 8, 7, 6, 2, 1), today)
+	require.Equal(t, 2, s.Current)
+	require.Equal(t, 7, s.Longest)
+	require.Equal(t, 1, s.Freezes)
+
+	/* up to MaxFreezes are kept */
+	days := []time.Time{}
+	for d := 30; d >= 0; d-- {
+		days = append(days, today.AddDate(0, 0, -d))
+	}
+	s = Compute(days, today)
+	require.Equal(t, 31, s.Current)
+	require.Equal(t, MaxFreezes, s.Freezes)
+}
+
+func TestFirstReached(t *testing.T) {
+	_, ok := FirstReached(daysAgo(3, 2, 1), today, 4)
+	require.False(t, ok)
+
+	/* the first streak that was long enough, not the current one */
+	day, ok := FirstReached(daysAgo(10, 9, 8, 6, 5, 4, 3, 2, 1), today, 3)
+	require.True(t, ok)
+	require.Equal(t, today.AddDate(0, 0, -8), day)
+	day, ok = FirstReached(daysAgo(10, 9, 8, 6, 5, 4, 3, 2, 1), today, 4)
+	require.True(t, ok)
+	require.Equal(t, today.AddDate(0, 0, -3), day)
+}
+
+func TestStudyTime(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	require.Zero(t, StudyTime(nil))
+	require.Zero(t, StudyTime([]time.Time{start}))
+	require.Equal(t, 90*time.Second+MaxReviewGap, StudyTime([]time.Time{
+		start,
+		start.Add(30 * time.Second),
+		start.Add(time.Minute),
+		/* a break isn't counted past MaxReviewGap */
+		start.Add(time.Hour),
+		start.Add(time.Hour + 30*time.Second),
+	}))
+}
+
+func TestDate(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	require.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), Date(time.Date(2026, 10, 18, 23, 0, 0, 0, loc)))
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAchievements(t *testing.T) {
+	// 1. Setup: a new user (without other tests' activity) creates a studyset with 20 terms
+	_, token := createTestUser(t, "achievementsUser")
+	terms := make([][2]string, 20)
+	for i := range terms {
+		terms[i] = [2]string{fmt.Sprintf("term %d", i), fmt.Sprintf("def %d", i)}
+	}
+	_, termIDs := createStudysetWithTerms(t, token, "Achievements Set", true, terms...)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	myAchievements := func() map[string]map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, `query {
+			myAchievements { id name description earned earnedAt }
+		}`, nil)
+		require.Nil(t, result["errors"])
+		byID := make(map[string]map[string]interface{})
+		for _, a := range getNested(result, "data", "myAchievements").([]interface{}) {
+			achievement := a.(map[string]interface{})
+			byID[achievement["id"].(string)] = achievement
+		}
+		return byID
+	}
+	requireEarnedAt := func(achievement map[string]interface{}, expected time.Time) {
+		t.Helper()
+		require.Equal(t, true, achievement["earned"])
+		earnedAt, err := time.Parse(time.RFC3339, achievement["earnedAt"].(string))
+		require.Nil(t, err)
+		require.True(t, earnedAt.Equal(expected), "earned at %s, expected %s", earnedAt, expected)
+	}
+	recordPracticeTest := func(timestamp time.Time, correct int) {
+		t.Helper()
+		questions := make([]interface{}, len(termIDs))
+		for i, termID := range termIDs {
+			answer := terms[i][1]
+			if i >= correct {
+				answer = "wrong"
+			}
+			questions[i] = map[string]interface{}{"frq": map[string]interface{}{
+				"term":           map[string]interface{}{"id": termID, "term": terms[i][0], "def": terms[i][1]},
+				"answerWith":     "DEF",
+				"answeredString": answer,
+			}}
+		}
+		result := graphqlRequest(t, token, `mutation Record($input: PracticeTestInput!) {
+			recordPracticeTest(input: $input) { id }
+		}`, map[string]interface{}{"input": map[string]interface{}{
+			"timestamp": timestamp.Format(time.RFC3339),
+			"questions": questions,
+		}})
+		require.Nil(t, result["errors"])
+	}
+	recordMatch := func(durationMs int) {
+		t.Helper()
+		result := graphqlRequest(t, token, `mutation Record($input: MatchActivityInput!) {
+			recordMatchActivity(input: $input) { id }
+		}`, map[string]interface{}{"input": map[string]interface{}{
+			"durationMs":       durationMs,
+			"termIds":          termIDs[:6],
+			"incorrectPairIds": []interface{}{},
+		}})
+		require.Nil(t, result["errors"])
+	}
+
+	// 2. Not Earned: every achievement is listed, without earnedAt
+	achievements := myAchievements()
+	for _, id := range []string{"first_practice_test", "perfect_practice_test", "fast_match", "reviews_1000", "streak_30"} {
+		require.Contains(t, achievements, id)
+		require.Equal(t, false, achievements[id]["earned"])
+		require.Nil(t, achievements[id]["earnedAt"])
+		require.NotEmpty(t, achievements[id]["name"])
+	}
+
+	// 3. Practice Tests: the first test is earned when it's recorded, a perfect score needs 20 correct questions
+	firstTestAt := now.Add(-2 * time.Hour)
+	recordPracticeTest(firstTestAt, 19)
+	achievements = myAchievements()
+	requireEarnedAt(achievements["first_practice_test"], firstTestAt)
+	require.Equal(t, false, achievements["perfect_practice_test"]["earned"])
+	perfectTestAt := now.Add(-time.Hour)
+	recordPracticeTest(perfectTestAt, 20)
+	achievements = myAchievements()
+	requireEarnedAt(achievements["first_practice_test"], firstTestAt)
+	requireEarnedAt(achievements["perfect_practice_test"], perfectTestAt)
+
+	// 4. Match: slow games don't count, a game under 30 seconds does
+	recordMatch(45000)
+	require.Equal(t, false, myAchievements()["fast_match"]["earned"])
+	recordMatch(12000)
+	require.Equal(t, true, myAchievements()["fast_match"]["earned"])
+
+	// 5. Reviews: term progress answers count toward 1000 reviews
+	result := graphqlRequest(t, token, `mutation Progress($termProgress: [TermProgressInput!]!) {
+		updateTermProgress(termProgress: $termProgress) { id }
+	}`, map[string]interface{}{"termProgress": []interface{}{map[string]interface{}{
+		"termId":              termIDs[0],
+		"termReviewedAt":      now.Format(time.RFC3339),
+		"termCorrectIncrease": 1000,
+	}}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, true, myAchievements()["reviews_1000"]["earned"])
+
+	// 6. Streak: FSRS reviews aren't evaluated when they're recorded, but the next evaluated event finds the streak
+	for d := 31; d >= 2; d-- {
+		result := graphqlRequest(t, token, `mutation Review($termId: ID!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: GOOD, reviewedAt: $reviewedAt) { state }
+		}`, map[string]interface{}{"termId": termIDs[1], "reviewedAt": now.AddDate(0, 0, -d).Format(time.RFC3339)})
+		require.Nil(t, result["errors"])
+	}
+	require.Equal(t, false, myAchievements()["streak_30"]["earned"])
+	recordPracticeTest(now, 0)
+	reachedDay := now.AddDate(0, 0, -2)
+	requireEarnedAt(myAchievements()["streak_30"], time.Date(reachedDay.Year(), reachedDay.Month(), reachedDay.Day(), 0, 0, 0, 0, time.UTC))
+
+	// 7. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", `query { myAchievements { id } }`, nil)
+	require.NotNil(t, result["errors"])
+}
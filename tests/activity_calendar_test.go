@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityCalendar(t *testing.T) {
+	// 1. Setup: a new user (without other tests' reviews) creates a studyset
+	_, token := createTestUser(t, "calendarUser")
+	_, termIDs := createStudysetWithTerms(t, token, "Calendar Set", true,
+		[2]string{"lundi", "monday"},
+		[2]string{"mardi", "tuesday"},
+	)
+	now := time.Now().UTC()
+	noon := func(daysAgo int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day()-daysAgo, 12, 0, 0, 0, time.UTC)
+	}
+	review := func(termID string, reviewedAt time.Time) {
+		t.Helper()
+		result := graphqlRequest(t, token, `mutation Review($termId: ID!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: GOOD, reviewedAt: $reviewedAt) { state }
+		}`, map[string]interface{}{"termId": termID, "reviewedAt": reviewedAt.Format(time.RFC3339)})
+		require.Nil(t, result["errors"])
+	}
+	calendarQuery := `query Calendar($from: String!, $to: String!) {
+		activityCalendar(from: $from, to: $to) {
+			days { date reviews minutes studysets terms activeDays }
+			weeks { date reviews minutes studysets terms activeDays }
+			months { date reviews minutes studysets terms activeDays }
+		}
+	}`
+	calendarVars := map[string]interface{}{
+		"from": noon(3).Format(time.DateOnly),
+		"to":   now.Format(time.DateOnly),
+	}
+
+	// 2. Study: reviews 2 days ago, and a match game today
+	review(termIDs[0], noon(2))
+	review(termIDs[1], noon(2).Add(90*time.Second))
+	result := graphqlRequest(t, token, `mutation Record($input: MatchActivityInput!) {
+		recordMatchActivity(input: $input) { id }
+	}`, map[string]interface{}{"input": map[string]interface{}{
+		"durationMs":       150000,
+		"termIds":          termIDs,
+		"incorrectPairIds": []interface{}{},
+	}})
+	require.Nil(t, result["errors"])
+
+	// 3. Days: one entry per day, including days without reviews
+	result = graphqlRequest(t, token, calendarQuery, calendarVars)
+	require.Nil(t, result["errors"])
+	days := getNested(result, "data", "activityCalendar", "days").([]interface{})
+	require.Len(t, days, 4)
+	require.Equal(t, map[string]interface{}{
+		"date": noon(3).Format(time.DateOnly), "reviews": float64(0), "minutes": float64(0),
+		"studysets": float64(0), "terms": float64(0), "activeDays": float64(0),
+	}, days[0])
+	/* 90 seconds between reviews */
+	require.Equal(t, map[string]interface{}{
+		"date": noon(2).Format(time.DateOnly), "reviews": float64(2), "minutes": float64(1),
+		"studysets": float64(1), "terms": float64(2), "activeDays": float64(1),
+	}, days[1])
+	/* the match's duration, not the time between its reviews */
+	require.Equal(t, map[string]interface{}{
+		"date": now.Format(time.DateOnly), "reviews": float64(2), "minutes": float64(2),
+		"studysets": float64(1), "terms": float64(2), "activeDays": float64(1),
+	}, days[3])
+
+	// 4. Rollups: weeks start on Monday, and weeks & months add up to the days
+	for _, rollup := range []string{"weeks", "months"} {
+		periods := getNested(result, "data", "activityCalendar", rollup).([]interface{})
+		require.NotEmpty(t, periods)
+		reviews, activeDays := 0.0, 0.0
+		for _, p := range periods {
+			period := p.(map[string]interface{})
+			reviews += period["reviews"].(float64)
+			activeDays += period["activeDays"].(float64)
+			require.LessOrEqual(t, period["terms"], float64(2))
+			if rollup == "weeks" {
+				start, err := time.Parse(time.DateOnly, period["date"].(string))
+				require.NoError(t, err)
+				require.Equal(t, time.Monday, start.Weekday())
+			}
+		}
+		require.Equal(t, float64(4), reviews)
+		require.Equal(t, float64(2), activeDays)
+	}
+
+	// 5. Invalid Ranges: bad dates, reversed ranges, and ranges that are too long are rejected
+	for _, vars := range []map[string]interface{}{
+		{"from": "yesterday", "to": now.Format(time.DateOnly)},
+		{"from": now.Format(time.DateOnly), "to": noon(3).Format(time.DateOnly)},
+		{"from": noon(400).Format(time.DateOnly), "to": now.Format(time.DateOnly)},
+	} {
+		result = graphqlRequest(t, token, calendarQuery, vars)
+		require.NotNil(t, result["errors"])
+	}
+
+	// 6. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", calendarQuery, calendarVars)
+	require.NotNil(t, result["errors"])
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardFlags(t *testing.T) {
+	// 1. Setup: user1 creates a studyset, and reviews 2 of its 3 terms so they're due
+	studysetID, termIDs := createStudysetWithTerms(t, user1Token, "Card Flags Set", true,
+		[2]string{"perro", "dog"},
+		[2]string{"gato", "cat"},
+		[2]string{"pez", "fish"},
+	)
+	review := func(termID string, rating string, reviewedAt time.Time) map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, user1Token, `mutation Review($termId: ID!, $rating: FSRSRating!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: $rating, reviewedAt: $reviewedAt) { state lapses leech }
+		}`, map[string]interface{}{
+			"termId":     termID,
+			"rating":     rating,
+			"reviewedAt": reviewedAt.Format(time.RFC3339),
+		})
+		require.Nil(t, result["errors"])
+		return result
+	}
+	now := time.Now().UTC()
+	review(termIDs[0], "EASY", now.AddDate(0, 0, -30))
+	review(termIDs[1], "EASY", now.AddDate(0, 0, -30))
+
+	dueQuery := `query Due($ids: [ID!]) {
+		dueCards(studysetIds: $ids, includeNew: true) { dueCount newCount terms { id } }
+	}`
+	dueTermIDs := func() []string {
+		t.Helper()
+		result := graphqlRequest(t, user1Token, dueQuery, map[string]interface{}{"ids": []string{studysetID}})
+		require.Nil(t, result["errors"])
+		ids := []string{}
+		for _, term := range getNested(result, "data", "dueCards", "terms").([]interface{}) {
+			ids = append(ids, term.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+	require.ElementsMatch(t, termIDs, dueTermIDs())
+
+	// 2. Suspend: suspended cards (reviewed or new) aren't in the due queue
+	suspendQuery := `mutation Suspend($termId: ID!) { suspendTerm(termId: $termId) { suspended state } }`
+	result := graphqlRequest(t, user1Token, suspendQuery, map[string]interface{}{"termId": termIDs[0]})
+	require.Nil(t, result["errors"])
+	require.Equal(t, true, getNested(result, "data", "suspendTerm", "suspended"))
+	require.Equal(t, "REVIEW", getNested(result, "data", "suspendTerm", "state"))
+	result = graphqlRequest(t, user1Token, suspendQuery, map[string]interface{}{"termId": termIDs[2]})
+	require.Nil(t, result["errors"])
+	require.Equal(t, "NEW", getNested(result, "data", "suspendTerm", "state"))
+	require.ElementsMatch(t, []string{termIDs[1]}, dueTermIDs())
+
+	// 3. Unsuspend: cards are due again
+	unsuspendQuery := `mutation Unsuspend($termId: ID!) { unsuspendTerm(termId: $termId) { suspended } }`
+	for _, termID := range []string{termIDs[0], termIDs[2]} {
+		result = graphqlRequest(t, user1Token, unsuspendQuery, map[string]interface{}{"termId": termID})
+		require.Nil(t, result["errors"])
+		require.Equal(t, false, getNested(result, "data", "unsuspendTerm", "suspended"))
+	}
+	require.ElementsMatch(t, termIDs, dueTermIDs())
+
+	// 4. Bury: buried cards aren't due until buriedUntil, and null unburies them
+	buryQuery := `mutation Bury($termId: ID!, $until: String) { buryTermUntil(termId: $termId, until: $until) { buriedUntil } }`
+	result = graphqlRequest(t, user1Token, buryQuery, map[string]interface{}{
+		"termId": termIDs[1],
+		"until":  now.AddDate(0, 0, 2).Format(time.RFC3339),
+	})
+	require.Nil(t, result["errors"])
+	require.NotNil(t, getNested(result, "data", "buryTermUntil", "buriedUntil"))
+	require.ElementsMatch(t, []string{termIDs[0], termIDs[2]}, dueTermIDs())
+	result = graphqlRequest(t, user1Token, buryQuery, map[string]interface{}{"termId": termIDs[1], "until": nil})
+	require.Nil(t, result["errors"])
+	require.Nil(t, getNested(result, "data", "buryTermUntil", "buriedUntil"))
+	require.ElementsMatch(t, termIDs, dueTermIDs())
+	result = graphqlRequest(t, user1Token, buryQuery, map[string]interface{}{"termId": termIDs[1], "until": "tomorrow"})
+	require.NotNil(t, result["errors"])
+
+	// 5. Leech Tagging: with a leechThreshold of 1, forgetting a review card makes it a leech
+	result = graphqlRequest(t, user1Token, `mutation Settings($id: ID!) {
+		updateStudysetStudySettings(studysetId: $id, settings: { leechThreshold: 1 }) { leechThreshold }
+	}`, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = review(termIDs[0], "AGAIN", now)
+	require.Equal(t, float64(1), getNested(result, "data", "reviewTerm", "lapses"))
+	require.Equal(t, true, getNested(result, "data", "reviewTerm", "leech"))
+	result = review(termIDs[1], "GOOD", now)
+	require.Equal(t, false, getNested(result, "data", "reviewTerm", "leech"))
+
+	// 6. My Leeches: myLeeches returns leeches, and clearLeech removes the tag
+	leechesQuery := `query Leeches($ids: [ID!]) { myLeeches(studysetIds: $ids) { id term def fsrsCard { leech lapses } } }`
+	result = graphqlRequest(t, user1Token, leechesQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	leeches := getNested(result, "data", "myLeeches").([]interface{})
+	require.Len(t, leeches, 1)
+	require.Equal(t, termIDs[0], getNested(result, "data", "myLeeches", 0, "id"))
+	require.Equal(t, "dog", getNested(result, "data", "myLeeches", 0, "def"))
+	require.Equal(t, true, getNested(result, "data", "myLeeches", 0, "fsrsCard", "leech"))
+
+	result = graphqlRequest(t, user1Token, `mutation Clear($termId: ID!) { clearLeech(termId: $termId) { leech } }`,
+		map[string]interface{}{"termId": termIDs[0]})
+	require.Nil(t, result["errors"])
+	require.Equal(t, false, getNested(result, "data", "clearLeech", "leech"))
+	result = graphqlRequest(t, user1Token, leechesQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Len(t, getNested(result, "data", "myLeeches").([]interface{}), 0)
+
+	// 7. Private Set Security: user2 can't suspend terms in user1's private studyset, or see their leeches
+	result = graphqlRequest(t, user2Token, suspendQuery, map[string]interface{}{"termId": termIDs[1]})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user2Token, leechesQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Len(t, getNested(result, "data", "myLeeches").([]interface{}), 0)
+
+	// 8. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", suspendQuery, map[string]interface{}{"termId": termIDs[1]})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", leechesQuery, nil)
+	require.NotNil(t, result["errors"])
+}
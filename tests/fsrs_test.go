@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewTerm(t *testing.T) {
+	// 1. Setup: user1 creates a public studyset with a term
+	_, termIDs := createStudysetWithTerms(t, user1Token, "FSRS Review Set", false, [2]string{"hola", "hello"})
+	termID := termIDs[0]
+
+	reviewQuery := `mutation Review($termId: ID!, $rating: FSRSRating!, $reviewedAt: String) {
+		reviewTerm(termId: $termId, rating: $rating, reviewedAt: $reviewedAt) {
+			state
+			due
+			lastReview
+			reps
+			lapses
+			learningSteps
+			scheduledDays
+			stability
+			difficulty
+		}
+	}`
+
+	// 2. New Card: the first GOOD review starts learning, with the next step in 10 minutes
+	firstReview := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	result := graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId":     termID,
+		"rating":     "GOOD",
+		"reviewedAt": firstReview.Format(time.RFC3339),
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, "LEARNING", getNested(result, "data", "reviewTerm", "state"))
+	require.Equal(t, float64(1), getNested(result, "data", "reviewTerm", "reps"))
+	require.Equal(t, float64(1), getNested(result, "data", "reviewTerm", "learningSteps"))
+	due, err := time.Parse("2006-01-02T15:04:05.000Z07:00", getNested(result, "data", "reviewTerm", "due").(string))
+	require.NoError(t, err)
+	require.True(t, due.Equal(firstReview.Add(10*time.Minute)), due)
+
+	// 3. Graduate: GOOD on the last learning step schedules it for days later
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId":     termID,
+		"rating":     "GOOD",
+		"reviewedAt": firstReview.Add(10 * time.Minute).Format(time.RFC3339),
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, "REVIEW", getNested(result, "data", "reviewTerm", "state"))
+	require.GreaterOrEqual(t, getNested(result, "data", "reviewTerm", "scheduledDays").(float64), float64(1))
+
+	// 4. Server State: the card & both review logs are saved (logs have the card's state before each review)
+	termResult := graphqlRequest(t, user2Token, `query Term($id: ID!) {
+		term(id: $id) {
+			fsrsCard { state reps }
+			fsrsReviewLogs { rating state }
+		}
+	}`, map[string]interface{}{"id": termID})
+	require.Nil(t, termResult["errors"])
+	require.Equal(t, "REVIEW", getNested(termResult, "data", "term", "fsrsCard", "state"))
+	require.Equal(t, float64(2), getNested(termResult, "data", "term", "fsrsCard", "reps"))
+	logs := getNested(termResult, "data", "term", "fsrsReviewLogs").([]interface{})
+	require.Len(t, logs, 2)
+	require.Equal(t, "LEARNING", logs[0].(map[string]interface{})["state"])
+	require.Equal(t, "NEW", logs[1].(map[string]interface{})["state"])
+
+	// 5. User Isolation: user1 has no card for the term
+	termResult = graphqlRequest(t, user1Token, `query Term($id: ID!) {
+		term(id: $id) { fsrsCard { state } }
+	}`, map[string]interface{}{"id": termID})
+	require.Nil(t, getNested(termResult, "data", "term", "fsrsCard"))
+
+	// 6. Invalid Reviews: MANUAL, reviews before the last review, future reviews, and no auth
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId": termID,
+		"rating": "MANUAL",
+	})
+	require.NotNil(t, result["errors"])
+
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId":     termID,
+		"rating":     "GOOD",
+		"reviewedAt": firstReview.Add(-time.Hour).Format(time.RFC3339),
+	})
+	require.NotNil(t, result["errors"])
+
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId":     termID,
+		"rating":     "GOOD",
+		"reviewedAt": time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	require.NotNil(t, result["errors"])
+
+	result = graphqlRequest(t, "", reviewQuery, map[string]interface{}{
+		"termId": termID,
+		"rating": "GOOD",
+	})
+	require.NotNil(t, result["errors"])
+
+	// 7. Private Set Security: user2 can't review terms in user1's private studyset
+	_, privateTermIDs := createStudysetWithTerms(t, user1Token, "FSRS Private Set", true, [2]string{"adios", "goodbye"})
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{
+		"termId": privateTermIDs[0],
+		"rating": "GOOD",
+	})
+	require.NotNil(t, result["errors"])
+}
+
+func TestDueCards(t *testing.T) {
+	// 1. Setup: user1 creates a studyset, and reviews 2 of its 3 terms
+	studysetID, termIDs := createStudysetWithTerms(t, user1Token, "Due Cards Set", true,
+		[2]string{"review", "due weeks ago"},
+		[2]string{"learning", "due hours ago"},
+		[2]string{"new", "never reviewed"},
+	)
+	reviewQuery := `mutation Review($termId: ID!, $rating: FSRSRating!, $reviewedAt: String) {
+		reviewTerm(termId: $termId, rating: $rating, reviewedAt: $reviewedAt) { state }
+	}`
+	/* EASY on a new card schedules it days later, so reviewing a month ago makes it overdue */
+	result := graphqlRequest(t, user1Token, reviewQuery, map[string]interface{}{
+		"termId":     termIDs[0],
+		"rating":     "EASY",
+		"reviewedAt": time.Now().AddDate(0, 0, -30).Format(time.RFC3339),
+	})
+	require.Equal(t, "REVIEW", getNested(result, "data", "reviewTerm", "state"))
+	/* AGAIN on a new card is due a minute later */
+	result = graphqlRequest(t, user1Token, reviewQuery, map[string]interface{}{
+		"termId":     termIDs[1],
+		"rating":     "AGAIN",
+		"reviewedAt": time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+	})
+	require.Equal(t, "LEARNING", getNested(result, "data", "reviewTerm", "state"))
+
+	dueCardsQuery := `query DueCards($ids: [ID!], $folderId: ID, $limit: Int, $includeNew: Boolean) {
+		dueCards(studysetIds: $ids, folderId: $folderId, limit: $limit, includeNew: $includeNew) {
+			terms { id fsrsCard { state } }
+			dueCount
+			learningCount
+			newCount
+		}
+	}`
+	termIDsOf := func(result map[string]interface{}) []string {
+		var ids []string
+		for _, term := range getNested(result, "data", "dueCards", "terms").([]interface{}) {
+			ids = append(ids, term.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+
+	// 2. Due Cards: overdue cards are returned in due order, with counts
+	result = graphqlRequest(t, user1Token, dueCardsQuery, map[string]interface{}{
+		"ids": []string{studysetID},
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, []string{termIDs[0], termIDs[1]}, termIDsOf(result))
+	require.Equal(t, float64(1), getNested(result, "data", "dueCards", "dueCount"))
+	require.Equal(t, float64(1), getNested(result, "data", "dueCards", "learningCount"))
+	require.Equal(t, float64(1), getNested(result, "data", "dueCards", "newCount"))
+
+	// 3. Include New: new cards come after due cards, and limit applies to both
+	result = graphqlRequest(t, user1Token, dueCardsQuery, map[string]interface{}{
+		"ids":        []string{studysetID},
+		"includeNew": true,
+	})
+	require.Equal(t, []string{termIDs[0], termIDs[1], termIDs[2]}, termIDsOf(result))
+	require.Nil(t, getNested(result, "data", "dueCards", "terms", 2, "fsrsCard"))
+
+	result = graphqlRequest(t, user1Token, dueCardsQuery, map[string]interface{}{
+		"ids":        []string{studysetID},
+		"includeNew": true,
+		"limit":      1,
+	})
+	require.Equal(t, []string{termIDs[0]}, termIDsOf(result))
+
+	// 4. Private Set Security: user2 gets nothing from user1's private studyset
+	result = graphqlRequest(t, user2Token, dueCardsQuery, map[string]interface{}{
+		"ids":        []string{studysetID},
+		"includeNew": true,
+	})
+	require.Nil(t, result["errors"])
+	require.Empty(t, termIDsOf(result))
+	require.Equal(t, float64(0), getNested(result, "data", "dueCards", "newCount"))
+
+	// 5. Invalid Input: no auth, and both studysetIds and folderId
+	result = graphqlRequest(t, "", dueCardsQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, dueCardsQuery, map[string]interface{}{
+		"ids":      []string{studysetID},
+		"folderId": "00000000-0000-0000-0000-000000000000",
+	})
+	require.NotNil(t, result["errors"])
+}
+
+func TestFSRSParameters(t *testing.T) {
+	paramsQuery := `query {
+		myFsrsParameters { weights usingDefaults logLoss reviewCount status error requestedAt }
+	}`
+	optimizeMutation := `mutation {
+		optimizeFsrsParameters { weights usingDefaults status error requestedAt }
+	}`
+
+	// 1. Defaults: users who never optimized get the default weights, with no status
+	result := graphqlRequest(t, user2Token, paramsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, true, getNested(result, "data", "myFsrsParameters", "usingDefaults"))
+	require.Len(t, getNested(result, "data", "myFsrsParameters", "weights"), 21)
+	require.Nil(t, getNested(result, "data", "myFsrsParameters", "status"))
+
+	// 2. Optimize: optimizing runs in the background, and fails without enough reviews
+	result = graphqlRequest(t, user2Token, optimizeMutation, nil)
+	require.Nil(t, result["errors"])
+	requestedAt := getNested(result, "data", "optimizeFsrsParameters", "requestedAt")
+	require.NotNil(t, requestedAt)
+
+	for range 50 {
+		result = graphqlRequest(t, user2Token, paramsQuery, nil)
+		status := getNested(result, "data", "myFsrsParameters", "status")
+		if status != "PENDING" && status != "RUNNING" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.Equal(t, "FAILED", getNested(result, "data", "myFsrsParameters", "status"))
+	require.Contains(t, getNested(result, "data", "myFsrsParameters", "error"), "not enough reviews")
+	require.Equal(t, true, getNested(result, "data", "myFsrsParameters", "usingDefaults"))
+
+	// 3. Rate Limit: optimizing again right away doesn't start another optimization
+	result = graphqlRequest(t, user2Token, optimizeMutation, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, "FAILED", getNested(result, "data", "optimizeFsrsParameters", "status"))
+	require.Equal(t, requestedAt, getNested(result, "data", "optimizeFsrsParameters", "requestedAt"))
+
+	// 4. Scheduling: reviews still work with the default weights
+	_, termIDs := createStudysetWithTerms(t, user1Token, "FSRS Parameters Set", false, [2]string{"gato", "cat"})
+	result = graphqlRequest(t, user2Token, `mutation Review($termId: ID!) {
+		reviewTerm(termId: $termId, rating: EASY) { state }
+	}`, map[string]interface{}{"termId": termIDs[0]})
+	require.Nil(t, result["errors"])
+	require.Equal(t, "REVIEW", getNested(result, "data", "reviewTerm", "state"))
+
+	// 5. Auth: both need authentication
+	result = graphqlRequest(t, "", paramsQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", optimizeMutation, nil)
+	require.NotNil(t, result["errors"])
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	// 1. Setup: user1 creates a public studyset
+	_, termIDs := createStudysetWithTerms(t, user1Token, "Idempotency Set", false,
+		[2]string{"uno", "one"},
+		[2]string{"dos", "two"},
+	)
+
+	// 2. Term Progress: retrying with the same key doesn't count the increments again
+	termProgressQuery := `mutation Progress($progress: [TermProgressInput!]!, $key: String) {
+		updateTermProgress(termProgress: $progress, idempotencyKey: $key) { id termCorrectCount }
+	}`
+	progressVars := func(key interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"progress": []map[string]interface{}{{
+				"termId":              termIDs[0],
+				"termReviewedAt":      time.Now().UTC().Format(time.RFC3339),
+				"termCorrectIncrease": 1,
+			}},
+			"key": key,
+		}
+	}
+	first := graphqlRequest(t, user1Token, termProgressQuery, progressVars("progress-1"))
+	require.Nil(t, first["errors"])
+	require.Equal(t, float64(1), getNested(first, "data", "updateTermProgress", 0, "termCorrectCount"))
+	retry := graphqlRequest(t, user1Token, termProgressQuery, progressVars("progress-1"))
+	require.Nil(t, retry["errors"])
+	require.Equal(t, first["data"], retry["data"])
+	result := graphqlRequest(t, user1Token, termProgressQuery, progressVars(nil))
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "updateTermProgress", 0, "termCorrectCount"))
+
+	// 3. Practice Test: retrying returns the original practice test
+	practiceTestQuery := `mutation Record($input: PracticeTestInput!, $key: String) {
+		recordPracticeTest(input: $input, idempotencyKey: $key) { id questionsCorrect questionsTotal }
+	}`
+	practiceTestVars := map[string]interface{}{
+		"input": map[string]interface{}{"questions": []interface{}{
+			map[string]interface{}{"frq": map[string]interface{}{
+				"term":           map[string]interface{}{"id": termIDs[1], "term": "dos", "def": "two"},
+				"answerWith":     "DEF",
+				"answeredString": "two",
+			}},
+		}},
+		"key": "practice-test-1",
+	}
+	first = graphqlRequest(t, user1Token, practiceTestQuery, practiceTestVars)
+	require.Nil(t, first["errors"])
+	retry = graphqlRequest(t, user1Token, practiceTestQuery, practiceTestVars)
+	require.Nil(t, retry["errors"])
+	require.Equal(t, first["data"], retry["data"])
+	result = graphqlRequest(t, user1Token, `query PT($id: ID!) { practiceTest(id: $id) { questions { id } } }`,
+		map[string]interface{}{"id": getNested(first, "data", "recordPracticeTest", "id")})
+	require.Nil(t, result["errors"])
+	require.Len(t, getNested(result, "data", "practiceTest", "questions").([]interface{}), 1)
+
+	// 4. Match Activity: retrying returns the original match activity
+	matchQuery := `mutation Match($input: MatchActivityInput!, $key: String) {
+		recordMatchActivity(input: $input, idempotencyKey: $key) { id durationMs }
+	}`
+	matchVars := map[string]interface{}{
+		"input": map[string]interface{}{
+			"durationMs":       12000,
+			"termIds":          termIDs,
+			"incorrectPairIds": []interface{}{},
+		},
+		"key": "match-1",
+	}
+	first = graphqlRequest(t, user1Token, matchQuery, matchVars)
+	require.Nil(t, first["errors"])
+	retry = graphqlRequest(t, user1Token, matchQuery, matchVars)
+	require.Nil(t, retry["errors"])
+	require.Equal(t, first["data"], retry["data"])
+
+	// 5. Keys are per user: user2 can use the same key
+	result = graphqlRequest(t, user2Token, matchQuery, matchVars)
+	require.Nil(t, result["errors"])
+	require.NotEqual(t, getNested(first, "data", "recordMatchActivity", "id"), getNested(result, "data", "recordMatchActivity", "id"))
+
+	// 6. Invalid Keys: keys used for a different mutation, and empty keys, are rejected
+	result = graphqlRequest(t, user1Token, termProgressQuery, progressVars("match-1"))
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, termProgressQuery, progressVars(""))
+	require.NotNil(t, result["errors"])
+}
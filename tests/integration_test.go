@@ -178,10 +178,31 @@ VALUES ($1) RETURNING token`,
 			panic(err)
 		}
 
+		/* web import reads the fixture pages in webimport/testdata with the "file" fetcher instead of the internet */
+		webImportDir, err := os.MkdirTemp("", "quizfreely-tests-web-import-*")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(webImportDir)
+		quizletFixture, err := os.ReadFile("../webimport/testdata/quizlet.html")
+		if err != nil {
+			panic(err)
+		}
+		err = os.WriteFile(webImportDir+"/quizlet.com_900_cell-organelles-flash-cards.html", quizletFixture, 0o644)
+		if err != nil {
+			panic(err)
+		}
+
 		router := server.NewRouter(
 			config.Config{
-				BasePath:          "/",
-				EnableOAuthGoogle: false,
+				BasePath:              "/",
+				EnableOAuthGoogle:     false,
+				EnableWebImport:       true,
+				WebImportRateLimitReq: 1000,
+				WebImportRateLimitDur: 1,
+				WebImportCacheTTL:     60,
+				WebImportFetchers:     []string{"file"},
+				WebImportFileDir:      webImportDir,
 			},
 			dbPool,
 			nil,
@@ -202,6 +223,85 @@ func marshal(v any) *bytes.Reader {
 	return bytes.NewReader(b)
 }
 
+/* graphqlRequest sends a graphql request (token can be empty for no auth) and returns the decoded response */
+func graphqlRequest(t *testing.T, token string, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/graphql", marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+/* createStudysetWithTerms creates a (non-draft) studyset with terms, returning the studyset id and term ids */
+func createStudysetWithTerms(t *testing.T, token string, title string, private bool, terms ...[2]string) (string, []string) {
+	t.Helper()
+	createSSResult := graphqlRequest(t, token, `mutation CreateSS($title: String!, $private: Boolean!) {
+		createStudyset(studyset: {title: $title, private: $private}, draft: false) { id }
+	}`, map[string]interface{}{
+		"title":   title,
+		"private": private,
+	})
+	require.Nil(t, createSSResult["errors"])
+	studysetID := getNested(createSSResult, "data", "createStudyset", "id").(string)
+
+	newTerms := make([]map[string]interface{}, 0, len(terms))
+	for i, term := range terms {
+		newTerms = append(newTerms, map[string]interface{}{"term": term[0], "def": term[1], "sortOrder": i})
+	}
+	createTermsResult := graphqlRequest(t, token, `mutation CreateTerms($sid: ID!, $terms: [NewTermInput!]!) {
+		createTerms(studysetId: $sid, terms: $terms) { id }
+	}`, map[string]interface{}{
+		"sid":   studysetID,
+		"terms": newTerms,
+	})
+	require.Nil(t, createTermsResult["errors"])
+	var termIDs []string
+	for _, term := range getNested(createTermsResult, "data", "createTerms").([]interface{}) {
+		termIDs = append(termIDs, term.(map[string]interface{})["id"].(string))
+	}
+	return studysetID, termIDs
+}
+
+/*
+createTestUser creates a user with a session, returning its id and token,
+for tests that need a user without other tests' activity (like streaks)
+*/
+func createTestUser(t *testing.T, username string) (string, string) {
+	t.Helper()
+	ctx := context.Background()
+	var userID, token string
+	err := pgxscan.Get(
+		ctx,
+		dbPool,
+		&userID,
+		`INSERT INTO auth.users (username, encrypted_password, display_name, auth_type)
+VALUES ($1, crypt($1, gen_salt('bf')), $1, 'USERNAME_PASSWORD')
+RETURNING id`,
+		username,
+	)
+	require.NoError(t, err)
+	err = pgxscan.Get(
+		ctx,
+		dbPool,
+		&token,
+		`INSERT INTO auth.sessions (user_id)
+VALUES ($1) RETURNING token`,
+		userID,
+	)
+	require.NoError(t, err)
+	return userID, token
+}
+
 func getNested(m map[string]interface{}, keys ...interface{}) interface{} {
 	var current interface{} = m
 	for i, key := range keys {
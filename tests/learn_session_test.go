@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLearnSessions(t *testing.T) {
+	// 1. Setup: a new user creates a studyset with 3 terms
+	userID, token := createTestUser(t, "learnUser")
+	studysetID, termIDs := createStudysetWithTerms(t, token, "Learn Set", true,
+		[2]string{"rojo", "red"},
+		[2]string{"verde", "green"},
+		[2]string{"azul", "blue"},
+	)
+	sessionFields := `id round roundSize answerWith completed completedAt
+		progress { total multipleChoice written learned }
+		questions {
+			id
+			mcq { term { id term def } answerWith correctChoiceIndex distractors { id } }
+			frq { term { id term def } answerWith }
+		}`
+	answerQuery := `mutation Answer($questionId: ID!, $answer: LearnAnswerInput!, $key: String) {
+		answerLearnQuestion(questionId: $questionId, answer: $answer, idempotencyKey: $key) {
+			correct correctChoiceIndex stage
+			grade { correct reason }
+			fsrsCard { reps }
+			session { ` + sessionFields + ` }
+		}
+	}`
+	answer := func(questionID string, answer map[string]interface{}, key interface{}) map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, answerQuery, map[string]interface{}{
+			"questionId": questionID,
+			"answer":     answer,
+			"key":        key,
+		})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "answerLearnQuestion").(map[string]interface{})
+	}
+	/* correctAnswer answers a question correctly, questions answer with the def */
+	correctAnswer := func(question map[string]interface{}) map[string]interface{} {
+		if mcq, ok := question["mcq"].(map[string]interface{}); ok {
+			return map[string]interface{}{"answeredIndex": mcq["correctChoiceIndex"]}
+		}
+		return map[string]interface{}{"answeredString": getNested(question, "frq", "term", "def")}
+	}
+	questions := func(session map[string]interface{}) []interface{} {
+		return session["questions"].([]interface{})
+	}
+
+	// 2. Start: the first round has roundSize MCQs, for the first terms in the studyset
+	result := graphqlRequest(t, token, `mutation Start($studysetIds: [ID!]!) {
+		startLearnSession(studysetIds: $studysetIds, options: {roundSize: 2, answerWith: DEF}) { `+sessionFields+` }
+	}`, map[string]interface{}{"studysetIds": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	session := getNested(result, "data", "startLearnSession").(map[string]interface{})
+	sessionID := session["id"].(string)
+	require.Equal(t, float64(1), session["round"])
+	require.Equal(t, "DEF", session["answerWith"])
+	require.Equal(t, false, session["completed"])
+	require.Equal(t, map[string]interface{}{
+		"total": float64(3), "multipleChoice": float64(3), "written": float64(0), "learned": float64(0),
+	}, session["progress"])
+	round := questions(session)
+	require.Len(t, round, 2)
+	roundTermIDs := []interface{}{}
+	for _, q := range round {
+		question := q.(map[string]interface{})
+		require.NotNil(t, question["mcq"])
+		require.Nil(t, question["frq"])
+		require.Len(t, getNested(question, "mcq", "distractors").([]interface{}), 2)
+		roundTermIDs = append(roundTermIDs, getNested(question, "mcq", "term", "id"))
+	}
+	require.ElementsMatch(t, []interface{}{termIDs[0], termIDs[1]}, roundTermIDs)
+
+	// 3. Resume: the session (with the same questions) can be loaded from another device
+	result = graphqlRequest(t, token, `query Session($id: ID!) { learnSession(id: $id) { `+sessionFields+` } }`,
+		map[string]interface{}{"id": sessionID})
+	require.Nil(t, result["errors"])
+	require.Equal(t, session, getNested(result, "data", "learnSession"))
+	result = graphqlRequest(t, token, `query { myLearnSessions { id } }`, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, []interface{}{map[string]interface{}{"id": sessionID}}, getNested(result, "data", "myLearnSessions"))
+
+	// 4. Answer: a wrong MCQ stays at MULTIPLE_CHOICE, a correct one moves to WRITTEN, and both are FSRS reviews
+	missed := round[0].(map[string]interface{})
+	wrongIndex := float64((int(getNested(missed, "mcq", "correctChoiceIndex").(float64)) + 1) % 3)
+	answered := answer(missed["id"].(string), map[string]interface{}{"answeredIndex": wrongIndex}, "learn-key-1")
+	require.Equal(t, false, answered["correct"])
+	require.Equal(t, "MULTIPLE_CHOICE", answered["stage"])
+	require.Equal(t, getNested(missed, "mcq", "correctChoiceIndex"), answered["correctChoiceIndex"])
+	require.Equal(t, float64(1), getNested(answered, "fsrsCard", "reps"))
+	require.Len(t, questions(answered["session"].(map[string]interface{})), 1)
+
+	/* retrying with the same idempotency key doesn't answer it again */
+	require.Equal(t, answered, answer(missed["id"].(string), map[string]interface{}{"answeredIndex": wrongIndex}, "learn-key-1"))
+	result = graphqlRequest(t, token, answerQuery, map[string]interface{}{
+		"questionId": missed["id"],
+		"answer":     map[string]interface{}{"answeredIndex": wrongIndex},
+	})
+	require.NotNil(t, result["errors"])
+
+	/* the round's last answer starts the next round, with seen terms before new ones */
+	second := round[1].(map[string]interface{})
+	answered = answer(second["id"].(string), correctAnswer(second), nil)
+	require.Equal(t, true, answered["correct"])
+	require.Equal(t, "WRITTEN", answered["stage"])
+	session = answered["session"].(map[string]interface{})
+	require.Equal(t, float64(2), session["round"])
+	require.Equal(t, float64(1), getNested(session, "progress", "written"))
+	round = questions(session)
+	require.Len(t, round, 2)
+	for _, q := range round {
+		question := q.(map[string]interface{})
+		if question["frq"] != nil {
+			require.Equal(t, getNested(second, "mcq", "term", "id"), getNested(question, "frq", "term", "id"))
+		} else {
+			require.Equal(t, getNested(missed, "mcq", "term", "id"), getNested(question, "mcq", "term", "id"))
+		}
+	}
+
+	// 5. Written: answers are graded on the server, and a wrong answer goes back to MCQs
+	for _, q := range round {
+		question := q.(map[string]interface{})
+		if question["frq"] == nil {
+			continue
+		}
+		answered = answer(question["id"].(string), map[string]interface{}{"answeredString": "purple"}, nil)
+		require.Equal(t, false, answered["correct"])
+		require.Equal(t, "INCORRECT", getNested(answered, "grade", "reason"))
+		require.Equal(t, "MULTIPLE_CHOICE", answered["stage"])
+	}
+
+	// 6. Complete: answering every question correctly learns every term and completes the session
+	session = answered["session"].(map[string]interface{})
+	for i := 0; i < 20 && session["completed"] == false; i++ {
+		for _, q := range questions(session) {
+			question := q.(map[string]interface{})
+			answered = answer(question["id"].(string), correctAnswer(question), nil)
+			require.Equal(t, true, answered["correct"])
+			session = answered["session"].(map[string]interface{})
+		}
+	}
+	require.Equal(t, true, session["completed"])
+	require.NotNil(t, session["completedAt"])
+	require.Empty(t, questions(session))
+	require.Equal(t, float64(3), getNested(session, "progress", "learned"))
+
+	result = graphqlRequest(t, token, `query { myLearnSessions { id } }`, nil)
+	require.Nil(t, result["errors"])
+	require.Empty(t, getNested(result, "data", "myLearnSessions"))
+	result = graphqlRequest(t, token, `query { myLearnSessions(includeCompleted: true) { id completed } }`, nil)
+	require.Nil(t, result["errors"])
+	require.Len(t, getNested(result, "data", "myLearnSessions"), 1)
+
+	// 7. Term Progress: learn answers are saved as term progress, for the side they're answered with
+	result = graphqlRequest(t, token, `query Term($id: ID!) {
+		term(id: $id) { progress { defCorrectCount defIncorrectCount termCorrectCount } fsrsCard { reps } }
+	}`, map[string]interface{}{"id": getNested(missed, "mcq", "term", "id")})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(1), getNested(result, "data", "term", "progress", "defIncorrectCount"))
+	require.Equal(t, float64(2), getNested(result, "data", "term", "progress", "defCorrectCount"))
+	require.Equal(t, float64(0), getNested(result, "data", "term", "progress", "termCorrectCount"))
+	require.Equal(t, float64(3), getNested(result, "data", "term", "fsrsCard", "reps"))
+
+	/* each answer is a review event & an FSRS review, but it's only one review for daily goals */
+	var answers int
+	err := dbPool.QueryRow(context.Background(), "SELECT count(*) FROM review_events WHERE user_id = $1", userID).Scan(&answers)
+	require.NoError(t, err)
+	result = graphqlRequest(t, token, `query { myStreak { today { reviews } } }`, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(answers), getNested(result, "data", "myStreak", "today", "reviews"))
+
+	// 8. Auth: other users & unauthenticated requests can't see or answer the session
+	result = graphqlRequest(t, user2Token, `query Session($id: ID!) { learnSession(id: $id) { id } }`,
+		map[string]interface{}{"id": sessionID})
+	require.Nil(t, result["errors"])
+	require.Nil(t, getNested(result, "data", "learnSession"))
+	result = graphqlRequest(t, user2Token, answerQuery, map[string]interface{}{
+		"questionId": missed["id"],
+		"answer":     map[string]interface{}{"answeredIndex": 0},
+	})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", `query { myLearnSessions { id } }`, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", `mutation { startLearnSession(studysetIds: []) { id } }`, nil)
+	require.NotNil(t, result["errors"])
+}
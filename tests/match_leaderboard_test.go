@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLeaderboard(t *testing.T) {
+	// 1. Setup: user1 creates a public studyset, and two new users play match games on it
+	studysetID, termIDs := createStudysetWithTerms(t, user1Token, "Match Leaderboard Set", false,
+		[2]string{"uno", "one"},
+		[2]string{"dos", "two"},
+		[2]string{"tres", "three"},
+	)
+	userAID, userAToken := createTestUser(t, "matchUserA")
+	userBID, userBToken := createTestUser(t, "matchUserB")
+
+	recordMatch := func(token string, durationMs int, termIDs []string, incorrectPairs int, endTimestamp *time.Time) {
+		t.Helper()
+		pairs := make([]interface{}, incorrectPairs)
+		for i := range pairs {
+			pairs[i] = []string{termIDs[0], termIDs[len(termIDs)-1]}
+		}
+		input := map[string]interface{}{
+			"durationMs":       durationMs,
+			"termIds":          termIDs,
+			"incorrectPairIds": pairs,
+		}
+		if endTimestamp != nil {
+			input["endTimestamp"] = endTimestamp.Format(time.RFC3339)
+		}
+		result := graphqlRequest(t, token, `mutation Record($input: MatchActivityInput!) {
+			recordMatchActivity(input: $input) { id }
+		}`, map[string]interface{}{"input": input})
+		require.Nil(t, result["errors"])
+	}
+	leaderboard := func(token string, period string) []interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, `query Leaderboard($id: ID!, $period: MatchLeaderboardPeriod) {
+			studyset(id: $id) {
+				matchLeaderboard(period: $period) {
+					rank user { id } matchActivityId durationMs incorrectPairs scoreMs endTimestamp
+				}
+			}
+		}`, map[string]interface{}{"id": studysetID, "period": period})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "studyset", "matchLeaderboard").([]interface{})
+	}
+	myBest := func(token string) interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, `query Best($id: ID!) {
+			studyset(id: $id) { myBestMatchTime { rank durationMs incorrectPairs scoreMs } }
+		}`, map[string]interface{}{"id": studysetID})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "studyset", "myBestMatchTime")
+	}
+
+	// 2. Partial Games: games that didn't match every term in the studyset don't count
+	recordMatch(userAToken, 1000, termIDs[:2], 0, nil)
+	require.Empty(t, leaderboard(userAToken, "ALL"))
+	require.Nil(t, myBest(userAToken))
+
+	// 3. Ranking: each user's best game is ranked by time, plus a penalty for incorrect pairs
+	recordMatch(userAToken, 20000, termIDs, 0, nil)
+	recordMatch(userAToken, 30000, termIDs, 0, nil)
+	recordMatch(userBToken, 15000, termIDs, 8, nil)
+	entries := leaderboard("", "ALL")
+	require.Len(t, entries, 2)
+	require.Equal(t, userAID, getNested(entries[0].(map[string]interface{}), "user", "id"))
+	require.Equal(t, float64(1), getNested(entries[0].(map[string]interface{}), "rank"))
+	require.Equal(t, float64(20000), getNested(entries[0].(map[string]interface{}), "scoreMs"))
+	require.Equal(t, userBID, getNested(entries[1].(map[string]interface{}), "user", "id"))
+	require.Equal(t, float64(2), getNested(entries[1].(map[string]interface{}), "rank"))
+	require.Equal(t, float64(8), getNested(entries[1].(map[string]interface{}), "incorrectPairs"))
+	require.Equal(t, float64(23000), getNested(entries[1].(map[string]interface{}), "scoreMs"))
+
+	// 4. Periods: games are only ranked in periods they ended in
+	threeDaysAgo := time.Now().AddDate(0, 0, -3)
+	recordMatch(user1Token, 5000, termIDs, 0, &threeDaysAgo)
+	require.Len(t, leaderboard(user1Token, "DAY"), 2)
+	week := leaderboard(user1Token, "WEEK")
+	require.Len(t, week, 3)
+	require.Equal(t, float64(5000), getNested(week[0].(map[string]interface{}), "scoreMs"))
+	require.Len(t, leaderboard(user1Token, "ALL"), 3)
+
+	// 5. Opt Out: users who hide from leaderboards aren't shown, but still see their best time
+	result := graphqlRequest(t, user1Token, `mutation {
+		updateUser(hideFromLeaderboards: true) { id }
+	}`, nil)
+	require.Nil(t, result["errors"])
+	t.Cleanup(func() {
+		graphqlRequest(t, user1Token, `mutation { updateUser(hideFromLeaderboards: false) { id } }`, nil)
+	})
+	result = graphqlRequest(t, user1Token, `query { authedUser { hideFromLeaderboards } }`, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, true, getNested(result, "data", "authedUser", "hideFromLeaderboards"))
+	entries = leaderboard(user1Token, "ALL")
+	require.Len(t, entries, 2)
+	require.Equal(t, userAID, getNested(entries[0].(map[string]interface{}), "user", "id"))
+	best := myBest(user1Token).(map[string]interface{})
+	require.Equal(t, float64(1), best["rank"])
+	require.Equal(t, float64(5000), best["durationMs"])
+
+	// 6. My Best Match Time: a user's best game, ranked against other users' best games
+	best = myBest(userBToken).(map[string]interface{})
+	require.Equal(t, float64(2), best["rank"])
+	require.Equal(t, float64(15000), best["durationMs"])
+	require.Equal(t, float64(23000), best["scoreMs"])
+
+	// 7. Auth: anyone can see leaderboards, but myBestMatchTime needs authentication
+	require.Len(t, leaderboard("", "ALL"), 2)
+	result = graphqlRequest(t, "", `query Best($id: ID!) {
+		studyset(id: $id) { myBestMatchTime { rank } }
+	}`, map[string]interface{}{"id": studysetID})
+	require.NotNil(t, result["errors"])
+
+	// 8. Invalid Durations: zero, negative, and over-an-hour games are rejected, so they can't top leaderboards
+	for _, durationMs := range []int{0, -5000, 60*60*1000 + 1} {
+		result = graphqlRequest(t, userAToken, `mutation Record($input: MatchActivityInput!) {
+			recordMatchActivity(input: $input) { id }
+		}`, map[string]interface{}{"input": map[string]interface{}{
+			"durationMs":       durationMs,
+			"termIds":          termIDs,
+			"incorrectPairIds": []interface{}{},
+		}})
+		require.NotNil(t, result["errors"], "durationMs %d should be rejected", durationMs)
+	}
+	entries = leaderboard("", "ALL")
+	require.Len(t, entries, 2)
+	require.Equal(t, userAID, getNested(entries[0].(map[string]interface{}), "user", "id"))
+}
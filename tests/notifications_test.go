@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifications(t *testing.T) {
+	// 1. Setup: a new author with a public studyset, and two users who save it
+	_, authorToken := createTestUser(t, "notificationAuthor")
+	_, saverToken := createTestUser(t, "notificationSaver")
+	_, otherSaverToken := createTestUser(t, "notificationOtherSaver")
+	studysetID, _ := createStudysetWithTerms(t, authorToken, "Notification Set", false,
+		[2]string{"hola", "hello"},
+	)
+
+	listQuery := `query List($first: Int, $after: String, $unreadOnly: Boolean) {
+		myNotifications(first: $first, after: $after, unreadOnly: $unreadOnly) {
+			edges { cursor node { id type actor { id } studyset { id title } read readAt } }
+			pageInfo { hasNextPage hasPreviousPage endCursor }
+		}
+		myUnreadNotificationCount
+	}`
+	saveQuery := `mutation Save($id: ID!) { saveStudyset(studysetId: $id) }`
+	unsaveQuery := `mutation Unsave($id: ID!) { unsaveStudyset(studysetId: $id) }`
+	markQuery := `mutation Mark($ids: [ID!]) { markNotificationsRead(ids: $ids) }`
+
+	result := graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Empty(t, getNested(result, "data", "myNotifications", "edges"))
+	require.Equal(t, float64(0), getNested(result, "data", "myUnreadNotificationCount"))
+
+	// 2. Saved: saving someone's studyset notifies its author, without showing who saved it
+	result = graphqlRequest(t, saverToken, saveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	edges := getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	node := edges[0].(map[string]interface{})["node"].(map[string]interface{})
+	require.Equal(t, "STUDYSET_SAVED", node["type"])
+	require.Nil(t, node["actor"])
+	require.Equal(t, map[string]interface{}{"id": studysetID, "title": "Notification Set"}, node["studyset"])
+	require.Equal(t, false, node["read"])
+	require.Equal(t, float64(1), getNested(result, "data", "myUnreadNotificationCount"))
+	firstNotificationID := node["id"].(string)
+
+	// 3. Collapsed: unsaving & saving again doesn't add another unread notification, and authors aren't notified about their own saves
+	result = graphqlRequest(t, saverToken, unsaveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, saverToken, saveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, saveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(1), getNested(result, "data", "myUnreadNotificationCount"))
+
+	// 4. Paging: a second save adds a newer notification, and notifications are paged newest first
+	result = graphqlRequest(t, otherSaverToken, saveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, map[string]interface{}{"first": 1})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "myUnreadNotificationCount"))
+	require.Equal(t, true, getNested(result, "data", "myNotifications", "pageInfo", "hasNextPage"))
+	edges = getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	secondNotificationID := getNested(edges[0].(map[string]interface{}), "node", "id").(string)
+	require.NotEqual(t, firstNotificationID, secondNotificationID)
+
+	endCursor := getNested(result, "data", "myNotifications", "pageInfo", "endCursor")
+	result = graphqlRequest(t, authorToken, listQuery, map[string]interface{}{"first": 1, "after": endCursor})
+	require.Nil(t, result["errors"])
+	require.Equal(t, true, getNested(result, "data", "myNotifications", "pageInfo", "hasPreviousPage"))
+	require.Equal(t, false, getNested(result, "data", "myNotifications", "pageInfo", "hasNextPage"))
+	edges = getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	require.Equal(t, firstNotificationID, getNested(edges[0].(map[string]interface{}), "node", "id"))
+
+	// 5. Read: marking by ids only marks the user's own unread notifications, and null ids marks all of them
+	result = graphqlRequest(t, saverToken, markQuery, map[string]interface{}{"ids": []string{firstNotificationID}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "markNotificationsRead"))
+	result = graphqlRequest(t, authorToken, markQuery, map[string]interface{}{"ids": []string{firstNotificationID}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(1), getNested(result, "data", "markNotificationsRead"))
+	result = graphqlRequest(t, authorToken, listQuery, map[string]interface{}{"unreadOnly": true})
+	require.Nil(t, result["errors"])
+	edges = getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	require.Equal(t, secondNotificationID, getNested(edges[0].(map[string]interface{}), "node", "id"))
+	require.Equal(t, float64(1), getNested(result, "data", "myUnreadNotificationCount"))
+
+	result = graphqlRequest(t, authorToken, markQuery, map[string]interface{}{"ids": nil})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(1), getNested(result, "data", "markNotificationsRead"))
+	result = graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "myUnreadNotificationCount"))
+	edges = getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 2)
+	for _, edge := range edges {
+		require.Equal(t, true, getNested(edge.(map[string]interface{}), "node", "read"))
+		require.NotNil(t, getNested(edge.(map[string]interface{}), "node", "readAt"))
+	}
+
+	// 6. Preferences: every type is on by default, and turned off types aren't created
+	preferencesQuery := `query { myNotificationPreferences { type enabled } }`
+	result = graphqlRequest(t, authorToken, preferencesQuery, nil)
+	require.Nil(t, result["errors"])
+	preferences := getNested(result, "data", "myNotificationPreferences").([]interface{})
+	require.Len(t, preferences, 2)
+	for _, preference := range preferences {
+		require.Equal(t, true, preference.(map[string]interface{})["enabled"])
+	}
+
+	updatePreferencesQuery := `mutation Update($preferences: [NotificationPreferenceInput!]!) {
+		updateNotificationPreferences(preferences: $preferences) { type enabled }
+	}`
+	result = graphqlRequest(t, authorToken, updatePreferencesQuery, map[string]interface{}{"preferences": []map[string]interface{}{
+		{"type": "STUDYSET_SAVED", "enabled": false},
+		{"type": "STUDYSET_SAVED", "enabled": true},
+	}})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, updatePreferencesQuery, map[string]interface{}{"preferences": []map[string]interface{}{
+		{"type": "STUDYSET_SAVED", "enabled": false},
+	}})
+	require.Nil(t, result["errors"])
+	preferences = getNested(result, "data", "updateNotificationPreferences").([]interface{})
+	require.Equal(t, map[string]interface{}{"type": "STUDYSET_SAVED", "enabled": false}, preferences[0])
+	require.Equal(t, map[string]interface{}{"type": "STUDYSET_HIDDEN_BY_MODERATOR", "enabled": true}, preferences[1])
+
+	result = graphqlRequest(t, saverToken, unsaveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, saverToken, saveQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "myUnreadNotificationCount"))
+
+	// 7. Hidden: a moderator unapproving seo indexing notifies the author, but approving it doesn't
+	seoQuery := `mutation Seo($id: ID!, $approved: Boolean!) { setStudysetSeoIndexing(studysetId: $id, approved: $approved) }`
+	result = graphqlRequest(t, modUser1Token, seoQuery, map[string]interface{}{"id": studysetID, "approved": true})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "myUnreadNotificationCount"))
+	result = graphqlRequest(t, modUser1Token, seoQuery, map[string]interface{}{"id": studysetID, "approved": false})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, authorToken, listQuery, map[string]interface{}{"unreadOnly": true})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(1), getNested(result, "data", "myUnreadNotificationCount"))
+	edges = getNested(result, "data", "myNotifications", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	node = edges[0].(map[string]interface{})["node"].(map[string]interface{})
+	require.Equal(t, "STUDYSET_HIDDEN_BY_MODERATOR", node["type"])
+	require.Nil(t, node["actor"])
+
+	// 8. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", listQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", preferencesQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", markQuery, map[string]interface{}{"ids": nil})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", updatePreferencesQuery, map[string]interface{}{"preferences": []map[string]interface{}{}})
+	require.NotNil(t, result["errors"])
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPracticeTestRetakeAndTrend(t *testing.T) {
+	// 1. Setup: a new user creates a studyset with 4 terms and records a practice test with 3 missed questions
+	_, token := createTestUser(t, "retakeUser")
+	studysetID, termIDs := createStudysetWithTerms(t, token, "Retake Set", false,
+		[2]string{"rojo", "red"},
+		[2]string{"azul", "blue"},
+		[2]string{"verde", "green"},
+		[2]string{"negro", "black"},
+	)
+	term := func(i int, term string, def string) map[string]interface{} {
+		return map[string]interface{}{"id": termIDs[i], "term": term, "def": def}
+	}
+	recordQuery := `mutation Record($input: PracticeTestInput!) {
+		recordPracticeTest(input: $input) { id questionsCorrect questionsTotal }
+	}`
+	result := graphqlRequest(t, token, recordQuery, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+		map[string]interface{}{"mcq": map[string]interface{}{
+			"term":               term(0, "rojo", "red"),
+			"answerWith":         "DEF",
+			"correct":            false,
+			"correctChoiceIndex": 0,
+			"answeredIndex":      1,
+			"distractors":        []interface{}{term(1, "azul", "blue"), term(2, "verde", "green")},
+		}},
+		map[string]interface{}{"tfq": map[string]interface{}{
+			"term":         term(1, "azul", "blue"),
+			"answerWith":   "DEF",
+			"correct":      true,
+			"answeredBool": true,
+		}},
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":           term(2, "verde", "green"),
+			"answerWith":     "TERM",
+			"answeredString": "morado",
+		}},
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":           term(3, "negro", "black"),
+			"answerWith":     "DEF",
+			"answeredString": "white",
+		}},
+	}}})
+	require.Nil(t, result["errors"])
+	practiceTestID := getNested(result, "data", "recordPracticeTest", "id").(string)
+	require.Equal(t, float64(1), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+
+	// 2. Edit: one missed term's def is changed, and another missed term is deleted
+	result = graphqlRequest(t, token, `mutation Update($studysetId: ID!, $terms: [TermInput!]!) {
+		updateTerms(studysetId: $studysetId, terms: $terms) { id }
+	}`, map[string]interface{}{"studysetId": studysetID, "terms": []interface{}{
+		map[string]interface{}{"id": termIDs[0], "term": "rojo", "def": "scarlet", "sortOrder": 0},
+	}})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, token, `mutation Delete($studysetId: ID!, $ids: [ID!]!) {
+		deleteTerms(studysetId: $studysetId, ids: $ids)
+	}`, map[string]interface{}{"studysetId": studysetID, "ids": []string{termIDs[3]}})
+	require.Nil(t, result["errors"])
+
+	// 3. Retake: only missed questions for existing terms are retaken, with the current text
+	retakeQuery := `query Retake($id: ID!) {
+		retakeMissed(practiceTestId: $id) {
+			id
+			studysetIds
+			questions {
+				mcq { term { id term def } answerWith correctChoiceIndex distractors { id term def } }
+				tfq { term { id } }
+				frq { term { id term def } answerWith }
+			}
+		}
+	}`
+	result = graphqlRequest(t, token, retakeQuery, map[string]interface{}{"id": practiceTestID})
+	require.Nil(t, result["errors"])
+	retake := getNested(result, "data", "retakeMissed").(map[string]interface{})
+	require.Equal(t, []interface{}{studysetID}, retake["studysetIds"])
+	questions := retake["questions"].([]interface{})
+	require.Len(t, questions, 2)
+	mcq := questions[0].(map[string]interface{})["mcq"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"id": termIDs[0], "term": "rojo", "def": "scarlet"}, mcq["term"])
+	require.Equal(t, "DEF", mcq["answerWith"])
+	require.Len(t, mcq["distractors"], 2)
+	frq := questions[1].(map[string]interface{})["frq"].(map[string]interface{})
+	require.Equal(t, termIDs[2], getNested(frq, "term", "id"))
+	require.Equal(t, "TERM", frq["answerWith"])
+
+	// 4. Submit: retakes are generated tests, so they're checked by the server
+	result = graphqlRequest(t, token, recordQuery, map[string]interface{}{"input": map[string]interface{}{
+		"generatedPracticeTestId": retake["id"],
+		"questions": []interface{}{
+			map[string]interface{}{"mcq": map[string]interface{}{
+				"term":               mcq["term"],
+				"answerWith":         mcq["answerWith"],
+				"correct":            true,
+				"correctChoiceIndex": mcq["correctChoiceIndex"],
+				"answeredIndex":      mcq["correctChoiceIndex"],
+				"distractors":        mcq["distractors"],
+			}},
+			map[string]interface{}{"frq": map[string]interface{}{
+				"term":           frq["term"],
+				"answerWith":     frq["answerWith"],
+				"answeredString": "verde",
+			}},
+		},
+	}})
+	require.Nil(t, result["errors"])
+	retakeID := getNested(result, "data", "recordPracticeTest", "id").(string)
+	require.Equal(t, float64(2), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+
+	// 5. Trend: scores (only for the studyset's remaining terms) are oldest first, by question type & answerWith
+	trendQuery := `query Trend($id: ID!, $last: Int) {
+		studyset(id: $id) {
+			practiceTestTrend(last: $last) {
+				tests {
+					practiceTestId
+					score { questionsCorrect questionsTotal score }
+				}
+				overall {
+					questionsCorrect questionsTotal score
+					byQuestionType { questionType questionsCorrect questionsTotal }
+					byAnswerWith { answerWith questionsCorrect questionsTotal }
+				}
+			}
+		}
+	}`
+	result = graphqlRequest(t, token, trendQuery, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	trend := getNested(result, "data", "studyset", "practiceTestTrend").(map[string]interface{})
+	tests := trend["tests"].([]interface{})
+	require.Len(t, tests, 2)
+	require.Equal(t, practiceTestID, tests[0].(map[string]interface{})["practiceTestId"])
+	require.Equal(t, map[string]interface{}{
+		"questionsCorrect": float64(1), "questionsTotal": float64(3), "score": float64(1) / 3,
+	}, tests[0].(map[string]interface{})["score"])
+	require.Equal(t, retakeID, tests[1].(map[string]interface{})["practiceTestId"])
+	require.Equal(t, float64(1), getNested(tests[1].(map[string]interface{}), "score", "score"))
+	overall := trend["overall"].(map[string]interface{})
+	require.Equal(t, float64(3), overall["questionsCorrect"])
+	require.Equal(t, float64(5), overall["questionsTotal"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"questionType": "MCQ", "questionsCorrect": float64(1), "questionsTotal": float64(2)},
+		map[string]interface{}{"questionType": "TFQ", "questionsCorrect": float64(1), "questionsTotal": float64(1)},
+		map[string]interface{}{"questionType": "FRQ", "questionsCorrect": float64(1), "questionsTotal": float64(2)},
+	}, overall["byQuestionType"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"answerWith": "TERM", "questionsCorrect": float64(1), "questionsTotal": float64(2)},
+		map[string]interface{}{"answerWith": "DEF", "questionsCorrect": float64(2), "questionsTotal": float64(3)},
+	}, overall["byAnswerWith"])
+
+	/* last only includes the most recent tests */
+	result = graphqlRequest(t, token, trendQuery, map[string]interface{}{"id": studysetID, "last": 1})
+	require.Nil(t, result["errors"])
+	tests = getNested(result, "data", "studyset", "practiceTestTrend", "tests").([]interface{})
+	require.Len(t, tests, 1)
+	require.Equal(t, retakeID, tests[0].(map[string]interface{})["practiceTestId"])
+	result = graphqlRequest(t, token, trendQuery, map[string]interface{}{"id": studysetID, "last": 0})
+	require.NotNil(t, result["errors"])
+
+	// 6. Private: missed terms that the user can't view anymore are skipped, so the retake can still be recorded
+	result = graphqlRequest(t, user2Token, recordQuery, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":           term(1, "azul", "blue"),
+			"answerWith":     "DEF",
+			"answeredString": "red",
+		}},
+	}}})
+	require.Nil(t, result["errors"])
+	otherPracticeTestID := getNested(result, "data", "recordPracticeTest", "id").(string)
+	result = graphqlRequest(t, token, `mutation Private($id: ID!) {
+		updateStudyset(id: $id, studyset: {title: "Retake Set", private: true}, draft: false) { id }
+	}`, map[string]interface{}{"id": studysetID})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, user2Token, retakeQuery, map[string]interface{}{"id": otherPracticeTestID})
+	require.Nil(t, result["errors"])
+	require.Empty(t, getNested(result, "data", "retakeMissed", "questions"))
+
+	// 7. Auth: other users can't retake someone else's test, and unauthenticated requests can't see trends
+	result = graphqlRequest(t, user2Token, retakeQuery, map[string]interface{}{"id": practiceTestID})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", retakeQuery, map[string]interface{}{"id": practiceTestID})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", trendQuery, map[string]interface{}{"id": studysetID})
+	require.NotNil(t, result["errors"])
+}
@@ -173,3 +173,236 @@ func TestPracticeTestLifecycle(t *testing.T) {
 	json.NewDecoder(resp.Body).Decode(&privateResult)
 	require.Nil(t, privateResult["errors"], "user2 should be able to record PT for user1's private set")
 }
+
+func TestGeneratePracticeTest(t *testing.T) {
+	// 1. Setup: user1 creates a private studyset
+	studysetID, _ := createStudysetWithTerms(t, user1Token, "Generated PT Set", true,
+		[2]string{"rojo", "red"},
+		[2]string{"azul", "blue"},
+		[2]string{"verde", "green"},
+		[2]string{"amarillo", "yellow"},
+		[2]string{"negro", "black"},
+		[2]string{"blanco", "white"},
+	)
+
+	generateQuery := `query Generate($ids: [ID!]!, $types: [QuestionType!], $count: Int, $weighting: PracticeTestWeighting) {
+		generatePracticeTest(studysetIds: $ids, questionTypes: $types, count: $count, answerWith: DEF, weighting: $weighting) {
+			id
+			studysetIds
+			expiresAt
+			questions {
+				id
+				mcq { term { id term def } answerWith correctChoiceIndex distractors { id term def } }
+				tfq { term { id term def } answerWith distractor { id term def } }
+				frq { term { id term def } answerWith }
+			}
+		}
+	}`
+	generate := func(token string, vars map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, generateQuery, vars)
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "generatePracticeTest").(map[string]interface{})
+	}
+
+	// 2. Generate: questions have the requested types, no repeated terms, and distractors from the set
+	generated := generate(user1Token, map[string]interface{}{
+		"ids":       []string{studysetID},
+		"types":     []string{"MCQ", "TFQ"},
+		"count":     4,
+		"weighting": "UNIFORM",
+	})
+	require.Equal(t, []interface{}{studysetID}, generated["studysetIds"])
+	require.NotEmpty(t, generated["expiresAt"])
+	questions := generated["questions"].([]interface{})
+	require.Len(t, questions, 4)
+	seen := map[string]bool{}
+	for _, q := range questions {
+		question := q.(map[string]interface{})
+		require.Nil(t, question["frq"])
+		if mcq, ok := question["mcq"].(map[string]interface{}); ok {
+			termID := getNested(mcq, "term", "id").(string)
+			require.False(t, seen[termID])
+			seen[termID] = true
+			require.Equal(t, "DEF", mcq["answerWith"])
+			distractors := mcq["distractors"].([]interface{})
+			require.Len(t, distractors, 3)
+			for _, d := range distractors {
+				require.NotEqual(t, termID, d.(map[string]interface{})["id"])
+			}
+			require.GreaterOrEqual(t, mcq["correctChoiceIndex"], float64(0))
+			require.LessOrEqual(t, mcq["correctChoiceIndex"], float64(3))
+		} else {
+			tfq := question["tfq"].(map[string]interface{})
+			termID := getNested(tfq, "term", "id").(string)
+			require.False(t, seen[termID])
+			seen[termID] = true
+		}
+	}
+
+	/* answers every generated question, correctly or not, but always claims they're correct */
+	submission := func(generated map[string]interface{}, answerCorrectly bool) map[string]interface{} {
+		questions := []interface{}{}
+		for _, q := range generated["questions"].([]interface{}) {
+			question := q.(map[string]interface{})
+			if mcq, ok := question["mcq"].(map[string]interface{}); ok {
+				answeredIndex := int(mcq["correctChoiceIndex"].(float64))
+				if !answerCorrectly {
+					answeredIndex = (answeredIndex + 1) % 4
+				}
+				questions = append(questions, map[string]interface{}{"mcq": map[string]interface{}{
+					"term":               mcq["term"],
+					"answerWith":         mcq["answerWith"],
+					"correct":            true,
+					"correctChoiceIndex": mcq["correctChoiceIndex"],
+					"answeredIndex":      answeredIndex,
+					"distractors":        mcq["distractors"],
+				}})
+			} else {
+				tfq := question["tfq"].(map[string]interface{})
+				answeredBool := tfq["distractor"] == nil
+				if !answerCorrectly {
+					answeredBool = !answeredBool
+				}
+				input := map[string]interface{}{
+					"term":         tfq["term"],
+					"answerWith":   tfq["answerWith"],
+					"correct":      true,
+					"answeredBool": answeredBool,
+				}
+				if tfq["distractor"] != nil {
+					input["distractor"] = tfq["distractor"]
+				}
+				questions = append(questions, map[string]interface{}{"tfq": input})
+			}
+		}
+		return map[string]interface{}{
+			"generatedPracticeTestId": generated["id"],
+			"questions":               questions,
+		}
+	}
+	recordQuery := `mutation Record($input: PracticeTestInput!) {
+		recordPracticeTest(input: $input) { id questionsCorrect questionsTotal }
+	}`
+
+	// 3. Submit: correctness is checked by the server
+	result := graphqlRequest(t, user1Token, recordQuery, map[string]interface{}{"input": submission(generated, true)})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(4), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+	require.Equal(t, float64(4), getNested(result, "data", "recordPracticeTest", "questionsTotal"))
+
+	// 4. Resubmit: generated tests can only be submitted once
+	result = graphqlRequest(t, user1Token, recordQuery, map[string]interface{}{"input": submission(generated, true)})
+	require.NotNil(t, result["errors"])
+
+	// 5. Tampering: questions that don't match are rejected, and wrong answers claimed correct aren't counted
+	generated = generate(user1Token, map[string]interface{}{
+		"ids":   []string{studysetID},
+		"types": []string{"MCQ", "TFQ"},
+		"count": 4,
+	})
+	tampered := submission(generated, true)
+	tampered["questions"] = tampered["questions"].([]interface{})[1:]
+	result = graphqlRequest(t, user1Token, recordQuery, map[string]interface{}{"input": tampered})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, recordQuery, map[string]interface{}{"input": submission(generated, false)})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+
+	// 6. Private Set Security: user2 gets no questions from user1's private studyset, and can't submit user1's test
+	generated = generate(user2Token, map[string]interface{}{"ids": []string{studysetID}})
+	require.Len(t, generated["questions"].([]interface{}), 0)
+	require.Len(t, generated["studysetIds"].([]interface{}), 0)
+	generated = generate(user1Token, map[string]interface{}{"ids": []string{studysetID}, "types": []string{"MCQ"}})
+	result = graphqlRequest(t, user2Token, recordQuery, map[string]interface{}{"input": submission(generated, true)})
+	require.NotNil(t, result["errors"])
+
+	// 7. Invalid Input: no auth, no studysets, and out of range counts
+	result = graphqlRequest(t, "", generateQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, generateQuery, map[string]interface{}{"ids": []string{}})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, generateQuery, map[string]interface{}{"ids": []string{studysetID}, "count": 0})
+	require.NotNil(t, result["errors"])
+}
+
+func TestFRQGrading(t *testing.T) {
+	// 1. Setup: user1 creates a studyset with alternate answers
+	_, termIDs := createStudysetWithTerms(t, user1Token, "FRQ Grading Set", true,
+		[2]string{"el coche", "car / automobile"},
+		[2]string{"la mariposa", "butterfly"},
+		[2]string{"el año", "year"},
+	)
+	frq := func(termID string, term string, def string, answerWith string, answered string) map[string]interface{} {
+		return map[string]interface{}{"frq": map[string]interface{}{
+			"term":           map[string]interface{}{"id": termID, "term": term, "def": def},
+			"answerWith":     answerWith,
+			"answeredString": answered,
+		}}
+	}
+
+	// 2. Record: FRQs without correct are graded by the server
+	result := graphqlRequest(t, user1Token, `mutation Record($input: PracticeTestInput!) {
+		recordPracticeTest(input: $input) {
+			id
+			questionsCorrect
+			questionsTotal
+		}
+	}`, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+		frq(termIDs[0], "el coche", "car / automobile", "DEF", "Automobile"),
+		frq(termIDs[1], "la mariposa", "butterfly", "DEF", "buterfly"),
+		frq(termIDs[2], "el año", "year", "TERM", "el ano"),
+		frq(termIDs[1], "la mariposa", "butterfly", "DEF", "moth"),
+		frq(termIDs[0], "el coche", "car / automobile", "DEF", ""),
+	}}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(3), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+	require.Equal(t, float64(5), getNested(result, "data", "recordPracticeTest", "questionsTotal"))
+	practiceTestID := getNested(result, "data", "recordPracticeTest", "id").(string)
+
+	// 3. Grades: each graded FRQ has a score and a reason
+	result = graphqlRequest(t, user1Token, `query PT($id: ID!) {
+		practiceTest(id: $id) {
+			questions { frq { correct grade { correct score reason matchedAnswer } } }
+		}
+	}`, map[string]interface{}{"id": practiceTestID})
+	require.Nil(t, result["errors"])
+	expected := []struct {
+		correct bool
+		reason  string
+		matched string
+	}{
+		{true, "NORMALIZED", "automobile"},
+		{true, "TYPO", "butterfly"},
+		{true, "NORMALIZED", "el año"},
+		{false, "INCORRECT", "butterfly"},
+		{false, "EMPTY", "car / automobile"},
+	}
+	for i, e := range expected {
+		require.Equal(t, e.correct, getNested(result, "data", "practiceTest", "questions", i, "frq", "correct"))
+		require.Equal(t, e.reason, getNested(result, "data", "practiceTest", "questions", i, "frq", "grade", "reason"))
+		require.Equal(t, e.matched, getNested(result, "data", "practiceTest", "questions", i, "frq", "grade", "matchedAnswer"))
+	}
+	require.Equal(t, float64(1), getNested(result, "data", "practiceTest", "questions", 0, "frq", "grade", "score"))
+	require.Less(t, getNested(result, "data", "practiceTest", "questions", 3, "frq", "grade", "score"), float64(0.5))
+
+	// 4. Client Grading: FRQs with correct (or userMarkedCorrect) aren't graded by the server
+	result = graphqlRequest(t, user1Token, `mutation Record($input: PracticeTestInput!) {
+		recordPracticeTest(input: $input) { questionsCorrect }
+	}`, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":           map[string]interface{}{"id": termIDs[1], "term": "la mariposa", "def": "butterfly"},
+			"answerWith":     "DEF",
+			"correct":        true,
+			"answeredString": "moth",
+		}},
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":              map[string]interface{}{"id": termIDs[1], "term": "la mariposa", "def": "butterfly"},
+			"answerWith":        "DEF",
+			"userMarkedCorrect": true,
+			"answeredString":    "moth",
+		}},
+	}}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "recordPracticeTest", "questionsCorrect"))
+}
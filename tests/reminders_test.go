@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"quizfreely/api/reminders"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+recordingNotifier saves reminders instead of sending them, or fails if err is set.
+reminders.Send notifies users concurrently, so it's locked
+*/
+type recordingNotifier struct {
+	mu        sync.Mutex
+	userID    string
+	err       error
+	reminders []reminders.Reminder
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, reminder reminders.Reminder) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	if reminder.UserID == n.userID {
+		n.reminders = append(n.reminders, reminder)
+	}
+	return nil
+}
+
+func TestReminders(t *testing.T) {
+	ctx := context.Background()
+
+	// 1. Setup: a new user (without reminders) reviews a term, so it's due again today
+	userID, token := createTestUser(t, "reminderUser")
+	_, termIDs := createStudysetWithTerms(t, token, "Reminder Set", true,
+		[2]string{"uno", "one"},
+		[2]string{"dos", "two"},
+	)
+	result := graphqlRequest(t, token, `mutation Review($termId: ID!) {
+		reviewTerm(termId: $termId, rating: AGAIN) { state }
+	}`, map[string]interface{}{"termId": termIDs[0]})
+	require.Nil(t, result["errors"])
+
+	settingsQuery := `query { myReminderSettings { enabled time timezone channel email webhookUrl lastSentAt } }`
+	result = graphqlRequest(t, token, settingsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Nil(t, getNested(result, "data", "myReminderSettings"))
+
+	// 2. Validation: invalid times, timezones, and webhook urls are rejected, and email reminders need a verified (google) email
+	updateQuery := `mutation Update($settings: ReminderSettingsInput!) {
+		updateReminderSettings(settings: $settings) { enabled time timezone channel email webhookUrl lastSentAt }
+	}`
+	for _, invalid := range []map[string]interface{}{
+		{"channel": "EMAIL"},
+		{"channel": "EMAIL", "email": "someone.else@example.org"},
+		{"time": "25:00", "channel": "WEBHOOK", "webhookUrl": "https://example.org/hook"},
+		{"timezone": "Mars/Olympus_Mons", "channel": "WEBHOOK", "webhookUrl": "https://example.org/hook"},
+		{"channel": "WEBHOOK", "webhookUrl": "http://example.org/hook"},
+		{"channel": "WEBHOOK"},
+	} {
+		result = graphqlRequest(t, token, updateQuery, map[string]interface{}{"settings": invalid})
+		require.NotNil(t, result["errors"], invalid)
+	}
+
+	// 3. Update: reminders are set for a minute ago, in the user's timezone
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	reminderTime := time.Now().In(tokyo).Add(-time.Minute)
+	result = graphqlRequest(t, token, updateQuery, map[string]interface{}{"settings": map[string]interface{}{
+		"time":       reminderTime.Format("15:04"),
+		"timezone":   "Asia/Tokyo",
+		"channel":    "WEBHOOK",
+		"webhookUrl": "https://example.org/hook",
+	}})
+	require.Nil(t, result["errors"])
+	expected := map[string]interface{}{
+		"enabled":    true,
+		"time":       reminderTime.Format("15:04"),
+		"timezone":   "Asia/Tokyo",
+		"channel":    "WEBHOOK",
+		"email":      nil,
+		"webhookUrl": "https://example.org/hook",
+		"lastSentAt": nil,
+	}
+	require.Equal(t, expected, getNested(result, "data", "updateReminderSettings"))
+	result = graphqlRequest(t, token, settingsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, expected, getNested(result, "data", "myReminderSettings"))
+
+	// 4. Send: the reminder has the user's due count & an unsubscribe link, and is only sent once a day
+	var unsubscribeToken string
+	err = pgxscan.Get(ctx, dbPool, &unsubscribeToken, "SELECT unsubscribe_token FROM reminder_settings WHERE user_id = $1", userID)
+	require.NoError(t, err)
+	notifier := &recordingNotifier{userID: userID}
+	notifiers := reminders.Notifiers{reminders.ChannelWebhook: notifier}
+	unsubscribeURL := testServer.URL + "/reminders/unsubscribe"
+	_, err = reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, []reminders.Reminder{{
+		UserID:         userID,
+		DisplayName:    "reminderUser",
+		Channel:        reminders.ChannelWebhook,
+		Address:        "https://example.org/hook",
+		Date:           reminderTime.Format(time.DateOnly),
+		DueCount:       1,
+		UnsubscribeURL: unsubscribeURL + "?token=" + unsubscribeToken,
+	}}, notifier.reminders)
+	_, err = reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	require.NoError(t, err)
+	require.Len(t, notifier.reminders, 1)
+	result = graphqlRequest(t, token, settingsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.NotNil(t, getNested(result, "data", "myReminderSettings", "lastSentAt"))
+
+	// 5. Retry: reminders that fail to send are sent by the next run
+	_, err = dbPool.Exec(ctx, "UPDATE reminder_settings SET last_reminded_on = NULL WHERE user_id = $1", userID)
+	require.NoError(t, err)
+	notifier.reminders = nil
+	notifier.err = errors.New("webhook is down")
+	_, err = reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	require.NoError(t, err)
+	notifier.err = nil
+	_, err = reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	require.NoError(t, err)
+	require.Len(t, notifier.reminders, 1)
+
+	// 6. Unsubscribe: the unsubscribe link turns off reminders without signing in
+	resp, err := http.Get(testServer.URL + "/reminders/unsubscribe?token=wrong")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, err = http.Post(notifier.reminders[0].UnsubscribeURL, "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result = graphqlRequest(t, token, settingsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, false, getNested(result, "data", "myReminderSettings", "enabled"))
+
+	_, err = dbPool.Exec(ctx, "UPDATE reminder_settings SET last_reminded_on = NULL WHERE user_id = $1", userID)
+	require.NoError(t, err)
+	_, err = reminders.Send(ctx, dbPool, notifiers, unsubscribeURL, time.Now())
+	require.NoError(t, err)
+	require.Len(t, notifier.reminders, 1)
+
+	// 7. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", settingsQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", updateQuery, map[string]interface{}{"settings": map[string]interface{}{"enabled": false}})
+	require.NotNil(t, result["errors"])
+}
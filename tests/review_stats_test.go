@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewForecastAndRetentionStats(t *testing.T) {
+	// 1. Setup: user1 creates a studyset, and reviews 2 of its 3 terms
+	studysetID, termIDs := createStudysetWithTerms(t, user1Token, "Review Stats Set", true,
+		[2]string{"sol", "sun"},
+		[2]string{"luna", "moon"},
+		[2]string{"estrella", "star"},
+	)
+	reviewQuery := `mutation Review($termId: ID!, $rating: FSRSRating!, $reviewedAt: String) {
+		reviewTerm(termId: $termId, rating: $rating, reviewedAt: $reviewedAt) { state }
+	}`
+	review := func(termID string, rating string, reviewedAt time.Time) {
+		t.Helper()
+		result := graphqlRequest(t, user1Token, reviewQuery, map[string]interface{}{
+			"termId":     termID,
+			"rating":     rating,
+			"reviewedAt": reviewedAt.Format(time.RFC3339),
+		})
+		require.Nil(t, result["errors"])
+	}
+	now := time.Now().UTC()
+	/* overdue (EASY schedules it ~8 days later) */
+	review(termIDs[0], "EASY", now.AddDate(0, 0, -30))
+	/* learning, due a minute later */
+	review(termIDs[1], "AGAIN", now.Add(-time.Minute))
+
+	// 2. Forecast: overdue & learning cards are due today, and reviewing them projects more reviews later
+	forecastQuery := `query Forecast($ids: [ID!], $days: Int) {
+		reviewForecast(studysetIds: $ids, days: $days) { date dueCount projectedReviews }
+	}`
+	result := graphqlRequest(t, user1Token, forecastQuery, map[string]interface{}{
+		"ids":  []string{studysetID},
+		"days": 60,
+	})
+	require.Nil(t, result["errors"])
+	days := getNested(result, "data", "reviewForecast").([]interface{})
+	require.Len(t, days, 60)
+	require.Equal(t, now.Format("2006-01-02"), getNested(result, "data", "reviewForecast", 0, "date"))
+	require.Equal(t, now.AddDate(0, 0, 1).Format("2006-01-02"), getNested(result, "data", "reviewForecast", 1, "date"))
+	require.Equal(t, float64(2), getNested(result, "data", "reviewForecast", 0, "dueCount"))
+	dueTotal, projectedTotal := 0.0, 0.0
+	for _, day := range days {
+		dueTotal += day.(map[string]interface{})["dueCount"].(float64)
+		projectedTotal += day.(map[string]interface{})["projectedReviews"].(float64)
+	}
+	require.Equal(t, float64(2), dueTotal)
+	require.Greater(t, projectedTotal, float64(2))
+
+	// 3. Retention Setup: review the overdue card (recalled after 30 days), and another card (forgotten after 10 days)
+	review(termIDs[0], "GOOD", now)
+	review(termIDs[2], "EASY", now.AddDate(0, 0, -10))
+	review(termIDs[2], "AGAIN", now)
+
+	// 4. Retention Stats: true retention overall, by state (before each review), and by days since the previous review
+	retentionQuery := `query Retention($ids: [ID!], $last: Int) {
+		retentionStats(studysetIds: $ids, last: $last) {
+			reviews
+			recalled
+			retention
+			byState { state reviews recalled retention }
+			byInterval { minDays maxDays reviews recalled retention }
+		}
+	}`
+	result = graphqlRequest(t, user1Token, retentionQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(5), getNested(result, "data", "retentionStats", "reviews"))
+	require.Equal(t, float64(3), getNested(result, "data", "retentionStats", "recalled"))
+	require.InDelta(t, 0.6, getNested(result, "data", "retentionStats", "retention"), 1e-9)
+
+	byState := map[string]map[string]interface{}{}
+	for _, s := range getNested(result, "data", "retentionStats", "byState").([]interface{}) {
+		byState[s.(map[string]interface{})["state"].(string)] = s.(map[string]interface{})
+	}
+	require.Equal(t, float64(3), byState["NEW"]["reviews"])
+	require.Equal(t, float64(2), byState["NEW"]["recalled"])
+	require.Equal(t, float64(2), byState["REVIEW"]["reviews"])
+	require.Equal(t, float64(1), byState["REVIEW"]["recalled"])
+	require.Equal(t, float64(0), byState["RELEARNING"]["reviews"])
+	require.Nil(t, byState["RELEARNING"]["retention"])
+
+	byInterval := map[float64]map[string]interface{}{}
+	for _, interval := range getNested(result, "data", "retentionStats", "byInterval").([]interface{}) {
+		byInterval[interval.(map[string]interface{})["minDays"].(float64)] = interval.(map[string]interface{})
+	}
+	require.Equal(t, float64(1), byInterval[15]["reviews"])
+	require.Equal(t, float64(1.0), byInterval[15]["retention"])
+	require.Equal(t, float64(1), byInterval[8]["reviews"])
+	require.Equal(t, float64(0.0), byInterval[8]["retention"])
+	require.Equal(t, float64(0), byInterval[1]["reviews"])
+	require.Nil(t, byInterval[181]["maxDays"])
+
+	// 5. Last: only today's reviews
+	result = graphqlRequest(t, user1Token, retentionQuery, map[string]interface{}{
+		"ids":  []string{studysetID},
+		"last": 1,
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(3), getNested(result, "data", "retentionStats", "reviews"))
+	require.Equal(t, float64(1), getNested(result, "data", "retentionStats", "recalled"))
+
+	// 6. User Isolation: user2 has no reviews or due cards in user1's private studyset
+	result = graphqlRequest(t, user2Token, retentionQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "retentionStats", "reviews"))
+	require.Nil(t, getNested(result, "data", "retentionStats", "retention"))
+	result = graphqlRequest(t, user2Token, forecastQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(0), getNested(result, "data", "reviewForecast", 0, "dueCount"))
+
+	// 7. Invalid Input: no auth, and both studysetIds and folderId
+	result = graphqlRequest(t, "", forecastQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, user1Token, `query {
+		retentionStats(studysetIds: [], folderId: "00000000-0000-0000-0000-000000000000") { reviews }
+	}`, nil)
+	require.NotNil(t, result["errors"])
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreaks(t *testing.T) {
+	// 1. Setup: a new user (without other tests' reviews) creates a studyset
+	_, token := createTestUser(t, "streakUser")
+	_, termIDs := createStudysetWithTerms(t, token, "Streak Set", true, [2]string{"sol", "sun"})
+	now := time.Now().UTC()
+	review := func(reviewedAt time.Time) {
+		t.Helper()
+		result := graphqlRequest(t, token, `mutation Review($termId: ID!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: GOOD, reviewedAt: $reviewedAt) { state }
+		}`, map[string]interface{}{"termId": termIDs[0], "reviewedAt": reviewedAt.Format(time.RFC3339)})
+		require.Nil(t, result["errors"])
+	}
+	streakQuery := `query {
+		myStreak {
+			current longest studiedToday freezes maxFreezes frozenDays
+			today { date reviews minutes progress met goal { type target } }
+		}
+	}`
+	myStreak := func() map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, streakQuery, nil)
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "myStreak").(map[string]interface{})
+	}
+
+	// 2. Defaults: no streak, and the default daily goal
+	s := myStreak()
+	require.Equal(t, float64(0), s["current"])
+	require.Equal(t, float64(0), s["longest"])
+	require.Equal(t, false, s["studiedToday"])
+	require.Equal(t, now.Format(time.DateOnly), getNested(s, "today", "date"))
+	require.Equal(t, "REVIEWS", getNested(s, "today", "goal", "type"))
+	require.Equal(t, float64(20), getNested(s, "today", "goal", "target"))
+	require.Equal(t, float64(0), getNested(s, "today", "progress"))
+
+	// 3. Freezes: 7 days in a row earns a freeze, which covers a missed day
+	for d := 9; d >= 3; d-- {
+		review(now.AddDate(0, 0, -d))
+	}
+	review(now.AddDate(0, 0, -1))
+	s = myStreak()
+	require.Equal(t, float64(8), s["current"])
+	require.Equal(t, float64(8), s["longest"])
+	require.Equal(t, false, s["studiedToday"])
+	require.Equal(t, float64(0), s["freezes"])
+	require.Equal(t, []interface{}{now.AddDate(0, 0, -2).Format(time.DateOnly)}, s["frozenDays"])
+
+	// 4. Today: reviewing today continues the streak and counts toward the daily goal
+	review(now.Add(-time.Minute))
+	review(now)
+	s = myStreak()
+	require.Equal(t, float64(9), s["current"])
+	require.Equal(t, true, s["studiedToday"])
+	require.Equal(t, float64(2), getNested(s, "today", "reviews"))
+	require.Equal(t, float64(1), getNested(s, "today", "minutes"))
+	require.Equal(t, false, getNested(s, "today", "met"))
+
+	// 5. Set Daily Goal: review & minute goals
+	goalQuery := `mutation Goal($type: DailyGoalType!, $target: Int!) { setDailyGoal(type: $type, target: $target) { type target } }`
+	result := graphqlRequest(t, token, goalQuery, map[string]interface{}{"type": "REVIEWS", "target": 2})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "setDailyGoal", "target"))
+	s = myStreak()
+	require.Equal(t, true, getNested(s, "today", "met"))
+	require.Equal(t, float64(1), getNested(s, "today", "progress"))
+
+	result = graphqlRequest(t, token, goalQuery, map[string]interface{}{"type": "MINUTES", "target": 4})
+	require.Nil(t, result["errors"])
+	s = myStreak()
+	require.Equal(t, "MINUTES", getNested(s, "today", "goal", "type"))
+	require.Equal(t, false, getNested(s, "today", "met"))
+	require.Equal(t, 0.25, getNested(s, "today", "progress"))
+
+	// 6. Invalid Goals: out of range targets are rejected
+	for _, vars := range []map[string]interface{}{
+		{"type": "REVIEWS", "target": 0},
+		{"type": "MINUTES", "target": 2000},
+	} {
+		result = graphqlRequest(t, token, goalQuery, vars)
+		require.NotNil(t, result["errors"])
+	}
+
+	// 7. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", streakQuery, nil)
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", goalQuery, map[string]interface{}{"type": "REVIEWS", "target": 10})
+	require.NotNil(t, result["errors"])
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudySettings(t *testing.T) {
+	settingsQuery := `query Settings($studysetId: ID) {
+		myStudySettings(studysetId: $studysetId) {
+			desiredRetention
+			maxNewCardsPerDay
+			maxReviewsPerDay
+			learningStepsMinutes
+			relearningStepsMinutes
+			dayRolloverHour
+		}
+	}`
+	updateMutation := `mutation Update($settings: StudySettingsInput!) {
+		updateStudySettings(settings: $settings) { desiredRetention maxNewCardsPerDay maxReviewsPerDay dayRolloverHour }
+	}`
+	updateStudysetMutation := `mutation UpdateStudyset($studysetId: ID!, $settings: StudysetStudySettingsInput!) {
+		updateStudysetStudySettings(studysetId: $studysetId, settings: $settings) {
+			studysetId
+			desiredRetention
+			maxNewCardsPerDay
+			learningStepsMinutes
+		}
+	}`
+
+	// 1. Defaults: users who never changed their settings get the defaults
+	result := graphqlRequest(t, user2Token, settingsQuery, nil)
+	require.Nil(t, result["errors"])
+	require.Equal(t, 0.9, getNested(result, "data", "myStudySettings", "desiredRetention"))
+	require.Equal(t, float64(20), getNested(result, "data", "myStudySettings", "maxNewCardsPerDay"))
+	require.Equal(t, float64(200), getNested(result, "data", "myStudySettings", "maxReviewsPerDay"))
+	require.Equal(t, []interface{}{float64(1), float64(10)}, getNested(result, "data", "myStudySettings", "learningStepsMinutes"))
+	require.Equal(t, []interface{}{float64(10)}, getNested(result, "data", "myStudySettings", "relearningStepsMinutes"))
+	require.Equal(t, float64(0), getNested(result, "data", "myStudySettings", "dayRolloverHour"))
+
+	// 2. Partial Updates: settings that aren't in the input are unchanged
+	result = graphqlRequest(t, user2Token, updateMutation, map[string]interface{}{
+		"settings": map[string]interface{}{"desiredRetention": 0.85},
+	})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, user2Token, updateMutation, map[string]interface{}{
+		"settings": map[string]interface{}{"maxReviewsPerDay": 50},
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, 0.85, getNested(result, "data", "updateStudySettings", "desiredRetention"))
+	require.Equal(t, float64(50), getNested(result, "data", "updateStudySettings", "maxReviewsPerDay"))
+	require.Equal(t, float64(20), getNested(result, "data", "updateStudySettings", "maxNewCardsPerDay"))
+
+	// 3. Invalid Settings: out of range retention, steps, limits, and rollover hours are rejected
+	for _, invalid := range []map[string]interface{}{
+		{"desiredRetention": 1.5},
+		{"learningStepsMinutes": []int{0}},
+		{"maxNewCardsPerDay": -1},
+		{"dayRolloverHour": 24},
+	} {
+		result = graphqlRequest(t, user2Token, updateMutation, map[string]interface{}{"settings": invalid})
+		require.NotNil(t, result["errors"], invalid)
+	}
+
+	// 4. Daily New Card Limit: a studyset override limits new cards in dueCards
+	studysetID, termIDs := createStudysetWithTerms(t, user2Token, "Study Settings Set", true,
+		[2]string{"uno", "one"},
+		[2]string{"dos", "two"},
+		[2]string{"tres", "three"},
+	)
+	dueCardsQuery := `query DueCards($ids: [ID!]) {
+		dueCards(studysetIds: $ids, includeNew: true) { terms { id } newCount }
+	}`
+	result = graphqlRequest(t, user2Token, dueCardsQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Equal(t, float64(3), getNested(result, "data", "dueCards", "newCount"))
+
+	result = graphqlRequest(t, user2Token, updateStudysetMutation, map[string]interface{}{
+		"studysetId": studysetID,
+		"settings":   map[string]interface{}{"maxNewCardsPerDay": 2},
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "updateStudysetStudySettings", "maxNewCardsPerDay"))
+	require.Nil(t, getNested(result, "data", "updateStudysetStudySettings", "desiredRetention"))
+	result = graphqlRequest(t, user2Token, dueCardsQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Equal(t, float64(2), getNested(result, "data", "dueCards", "newCount"))
+
+	/* new cards reviewed today count towards the limit */
+	reviewQuery := `mutation Review($termId: ID!) {
+		reviewTerm(termId: $termId, rating: GOOD) { state }
+	}`
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{"termId": termIDs[0]})
+	require.Equal(t, "LEARNING", getNested(result, "data", "reviewTerm", "state"))
+	result = graphqlRequest(t, user2Token, dueCardsQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Equal(t, float64(1), getNested(result, "data", "dueCards", "newCount"))
+	require.Len(t, getNested(result, "data", "dueCards", "terms"), 1)
+
+	// 5. Scheduling: reviews use the studyset's learning steps (GOOD on the only step graduates the card)
+	result = graphqlRequest(t, user2Token, updateStudysetMutation, map[string]interface{}{
+		"studysetId": studysetID,
+		"settings":   map[string]interface{}{"learningStepsMinutes": []int{30}},
+	})
+	require.Nil(t, result["errors"])
+	require.Equal(t, float64(2), getNested(result, "data", "updateStudysetStudySettings", "maxNewCardsPerDay"))
+	result = graphqlRequest(t, user2Token, reviewQuery, map[string]interface{}{"termId": termIDs[1]})
+	require.Equal(t, "REVIEW", getNested(result, "data", "reviewTerm", "state"))
+
+	result = graphqlRequest(t, user2Token, settingsQuery, map[string]interface{}{"studysetId": studysetID})
+	require.Equal(t, []interface{}{float64(30)}, getNested(result, "data", "myStudySettings", "learningStepsMinutes"))
+	require.Equal(t, 0.85, getNested(result, "data", "myStudySettings", "desiredRetention"))
+
+	// 6. Global Limit: the user's limit applies to all studysets, even with a higher studyset override
+	result = graphqlRequest(t, user2Token, updateMutation, map[string]interface{}{
+		"settings": map[string]interface{}{"maxNewCardsPerDay": 0},
+	})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, user2Token, dueCardsQuery, map[string]interface{}{"ids": []string{studysetID}})
+	require.Equal(t, float64(0), getNested(result, "data", "dueCards", "newCount"))
+
+	// 7. Reset: resetting the studyset's overrides uses the user's settings again
+	result = graphqlRequest(t, user2Token, `mutation Reset($studysetId: ID!) {
+		resetStudysetStudySettings(studysetId: $studysetId)
+	}`, map[string]interface{}{"studysetId": studysetID})
+	require.Equal(t, true, getNested(result, "data", "resetStudysetStudySettings"))
+	result = graphqlRequest(t, user2Token, `query Overrides($studysetId: ID!) {
+		myStudysetStudySettings(studysetId: $studysetId) { studysetId }
+	}`, map[string]interface{}{"studysetId": studysetID})
+	require.Nil(t, result["errors"])
+	require.Nil(t, getNested(result, "data", "myStudysetStudySettings"))
+
+	// 8. Private Set Security: user1 can't override settings for user2's private studyset
+	result = graphqlRequest(t, user1Token, updateStudysetMutation, map[string]interface{}{
+		"studysetId": studysetID,
+		"settings":   map[string]interface{}{"maxNewCardsPerDay": 5},
+	})
+	require.NotNil(t, result["errors"])
+
+	// 9. Auth: settings need authentication
+	result = graphqlRequest(t, "", settingsQuery, nil)
+	require.NotNil(t, result["errors"])
+
+	/* restore user2's limits for other tests */
+	result = graphqlRequest(t, user2Token, updateMutation, map[string]interface{}{
+		"settings": map[string]interface{}{"desiredRetention": 0.9, "maxNewCardsPerDay": 20, "maxReviewsPerDay": 200},
+	})
+	require.Nil(t, result["errors"])
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStudysetExport(t *testing.T) {
+	// 1. Setup: user1 creates a private studyset with terms
+	createSSBody := map[string]interface{}{
+		"query": `mutation {
+			createStudyset(studyset: {title: "Export Set", private: true}, draft: false) { id }
+		}`,
+	}
+	req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/graphql", marshal(createSSBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	resp, _ := http.DefaultClient.Do(req)
+	var createSSResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&createSSResult)
+	studysetID := getNested(createSSResult, "data", "createStudyset", "id").(string)
+
+	createTermsBody := map[string]interface{}{
+		"query": `mutation CreateTerms($sid: ID!, $terms: [NewTermInput!]!) {
+			createTerms(studysetId: $sid, terms: $terms) { id }
+		}`,
+		"variables": map[string]interface{}{
+			"sid": studysetID,
+			"terms": []map[string]interface{}{
+				{"term": "T1", "def": "D1, with a comma", "sortOrder": 0},
+				{"term": "T2", "def": "D2", "sortOrder": 1},
+			},
+		},
+	}
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/graphql", marshal(createTermsBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	http.DefaultClient.Do(req)
+
+	exportURL := testServer.URL + "/v0/studysets/" + studysetID + "/export"
+
+	// 2. Owner CSV: user1 exports their private studyset as CSV
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"term", "def", "term_image_url", "def_image_url"},
+		{"T1", "D1, with a comma", "", ""},
+		{"T2", "D2", "", ""},
+	}, records)
+
+	// 3. Private Set Security: user2 and anonymous users can't export it
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+user2Token)
+	resp, _ = http.DefaultClient.Do(req)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=json", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	/* invalid ids are not found too, instead of a DB error */
+	resp, _ = http.Get(testServer.URL + "/v0/studysets/not-a-uuid/export?format=json")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// 4. Public JSON: user1 makes it public, then an anonymous user exports it as JSON
+	updateSSBody := map[string]interface{}{
+		"query": `mutation UpdateSS($id: ID!) {
+			updateStudyset(id: $id, studyset: {title: "Export Set", private: false}, draft: false) { id }
+		}`,
+		"variables": map[string]interface{}{
+			"id": studysetID,
+		},
+	}
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/graphql", marshal(updateSSBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	http.DefaultClient.Do(req)
+
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=json", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var exportResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&exportResult)
+	require.Equal(t, "Export Set", getNested(exportResult, "studyset", "title"))
+	terms := exportResult["terms"].([]interface{})
+	require.Len(t, terms, 2)
+	require.Equal(t, "T2", terms[1].(map[string]interface{})["term"])
+
+	// 5. PDF: both layouts return a PDF, invalid layouts/formats are rejected
+	for _, layout := range []string{"flashcards", "quiz"} {
+		req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=pdf&layout="+layout, nil)
+		resp, _ = http.DefaultClient.Do(req)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, "%PDF", string(body[:4]))
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=pdf&layout=poster", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, exportURL+"?format=xlsx", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncStudyActivity(t *testing.T) {
+	// 1. Setup: user1 creates a private studyset
+	_, termIDs := createStudysetWithTerms(t, user1Token, "Sync Set", true,
+		[2]string{"rojo", "red"},
+		[2]string{"azul", "blue"},
+		[2]string{"verde", "green"},
+	)
+	now := time.Now().UTC().Truncate(time.Second)
+	at := func(ago time.Duration) string {
+		return now.Add(-ago).Format(time.RFC3339)
+	}
+
+	syncQuery := `mutation Sync($input: StudySyncInput!) {
+		syncStudyActivity(input: $input) {
+			cursor
+			cards { termId card { state reps lapses lastReview stability difficulty due } }
+			termProgress { termId progress { termCorrectCount defCorrectCount } }
+			practiceTests { id timestamp questionsCorrect }
+			matchActivities { id endTimestamp }
+			rejected { type index error }
+		}
+	}`
+	sync := func(token string, input map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, syncQuery, map[string]interface{}{"input": input})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "syncStudyActivity").(map[string]interface{})
+	}
+	cardFor := func(synced map[string]interface{}, termID string) map[string]interface{} {
+		t.Helper()
+		for _, c := range synced["cards"].([]interface{}) {
+			card := c.(map[string]interface{})
+			if card["termId"] == termID {
+				return card["card"].(map[string]interface{})
+			}
+		}
+		return nil
+	}
+
+	// 2. First Sync: queued reviews, term progress, a practice test, and a match activity are applied
+	firstInput := map[string]interface{}{
+		"reviews": []interface{}{
+			map[string]interface{}{"termId": termIDs[0], "rating": "GOOD", "clientTimestamp": at(26 * time.Hour)},
+			map[string]interface{}{"termId": termIDs[0], "rating": "GOOD", "clientTimestamp": at(50 * time.Hour)},
+		},
+		"termProgress": []interface{}{
+			map[string]interface{}{
+				"clientTimestamp": at(2 * time.Hour),
+				"idempotencyKey":  "sync-progress-1",
+				"termProgress": []interface{}{map[string]interface{}{
+					"termId":              termIDs[2],
+					"termReviewedAt":      at(2 * time.Hour),
+					"termCorrectIncrease": 1,
+				}},
+			},
+		},
+		"practiceTests": []interface{}{
+			map[string]interface{}{
+				"clientTimestamp": at(3 * time.Hour),
+				"idempotencyKey":  "sync-practice-test-1",
+				"practiceTest": map[string]interface{}{"questions": []interface{}{
+					map[string]interface{}{"frq": map[string]interface{}{
+						"term":           map[string]interface{}{"id": termIDs[2], "term": "verde", "def": "green"},
+						"answerWith":     "DEF",
+						"answeredString": "green",
+					}},
+				}},
+			},
+		},
+		"matchActivities": []interface{}{
+			map[string]interface{}{
+				"clientTimestamp": at(time.Hour),
+				"idempotencyKey":  "sync-match-1",
+				"matchActivity": map[string]interface{}{
+					"durationMs":       9000,
+					"termIds":          termIDs,
+					"incorrectPairIds": []interface{}{},
+				},
+			},
+		},
+	}
+	first := sync(user1Token, firstInput)
+	require.Empty(t, first["rejected"])
+	require.NotEmpty(t, first["cursor"])
+	card := cardFor(first, termIDs[0])
+	require.NotNil(t, card)
+	require.Equal(t, float64(2), card["reps"])
+	lastReview, err := time.Parse(time.RFC3339, card["lastReview"].(string))
+	require.Nil(t, err)
+	require.True(t, lastReview.Equal(now.Add(-26*time.Hour)), "reviews are applied in timestamp order")
+
+	practiceTests := first["practiceTests"].([]interface{})
+	require.Len(t, practiceTests, 1)
+	ptTimestamp, err := time.Parse(time.RFC3339, getNested(first, "practiceTests", 0, "timestamp").(string))
+	require.Nil(t, err)
+	require.True(t, ptTimestamp.Equal(now.Add(-3*time.Hour)), "practice tests keep the client timestamp")
+	require.Equal(t, float64(1), getNested(first, "practiceTests", 0, "questionsCorrect"))
+	endTimestamp, err := time.Parse(time.RFC3339, getNested(first, "matchActivities", 0, "endTimestamp").(string))
+	require.Nil(t, err)
+	require.True(t, endTimestamp.Equal(now.Add(-time.Hour)))
+
+	/* their review events are at the client timestamps too, so they count on the days they were studied */
+	var ptReviewedAt, matchReviewedAt []time.Time
+	err = pgxscan.Select(context.Background(), dbPool, &ptReviewedAt,
+		`SELECT re."timestamp" FROM review_events re
+		JOIN practice_test_questions q ON q.id = re.practice_test_question_id
+		WHERE q.practice_test_id = $1`, getNested(first, "practiceTests", 0, "id"))
+	require.NoError(t, err)
+	require.Len(t, ptReviewedAt, 1)
+	require.True(t, ptReviewedAt[0].Equal(now.Add(-3*time.Hour)))
+	err = pgxscan.Select(context.Background(), dbPool, &matchReviewedAt,
+		`SELECT "timestamp" FROM review_events WHERE match_activity_id = $1`, getNested(first, "matchActivities", 0, "id"))
+	require.NoError(t, err)
+	require.Len(t, matchReviewedAt, len(termIDs))
+	for _, reviewedAt := range matchReviewedAt {
+		require.True(t, reviewedAt.Equal(now.Add(-time.Hour)))
+	}
+
+	progressFound := false
+	for _, p := range first["termProgress"].([]interface{}) {
+		progress := p.(map[string]interface{})
+		if progress["termId"] == termIDs[2] {
+			progressFound = true
+			/* 1 from term progress, 1 from the match activity */
+			require.Equal(t, float64(2), getNested(progress, "progress", "termCorrectCount"))
+			require.Equal(t, float64(1), getNested(progress, "progress", "defCorrectCount"))
+		}
+	}
+	require.True(t, progressFound)
+
+	// 3. Conflict: an offline review older than an online review is replayed before it
+	review := func(termID string, rating string, reviewedAt string) map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, user1Token, `mutation Review($termId: ID!, $rating: FSRSRating!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: $rating, reviewedAt: $reviewedAt) { state reps lapses lastReview stability difficulty due }
+		}`, map[string]interface{}{"termId": termID, "rating": rating, "reviewedAt": reviewedAt})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "reviewTerm").(map[string]interface{})
+	}
+	review(termIDs[1], "GOOD", at(0))
+	conflict := sync(user1Token, map[string]interface{}{
+		"cursor": first["cursor"],
+		"reviews": []interface{}{
+			map[string]interface{}{"termId": termIDs[1], "rating": "AGAIN", "clientTimestamp": at(time.Hour)},
+		},
+	})
+	require.Empty(t, conflict["rejected"])
+	replayed := cardFor(conflict, termIDs[1])
+	require.NotNil(t, replayed)
+
+	/* the same reviews done online, in order, give the same card */
+	_, orderedTermIDs := createStudysetWithTerms(t, user1Token, "Sync Order Set", true, [2]string{"rosa", "pink"})
+	review(orderedTermIDs[0], "AGAIN", at(time.Hour))
+	ordered := review(orderedTermIDs[0], "GOOD", at(0))
+	require.Equal(t, ordered, replayed)
+
+	// 4. Retry: syncing the same activity again doesn't apply it twice
+	retry := sync(user1Token, firstInput)
+	require.Empty(t, retry["rejected"])
+	require.Equal(t, card, cardFor(retry, termIDs[0]))
+	require.Equal(t, getNested(first, "practiceTests", 0, "id"), getNested(retry, "practiceTests", 0, "id"))
+	require.Equal(t, getNested(first, "matchActivities", 0, "id"), getNested(retry, "matchActivities", 0, "id"))
+
+	// 5. Cursor: cards changed by other devices since the last sync are pulled, and invalid cursors are rejected
+	review(termIDs[2], "EASY", at(0))
+	pulled := sync(user1Token, map[string]interface{}{"cursor": retry["cursor"]})
+	require.NotNil(t, cardFor(pulled, termIDs[2]))
+	result := graphqlRequest(t, user1Token, syncQuery, map[string]interface{}{
+		"input": map[string]interface{}{"cursor": "not a cursor"},
+	})
+	require.NotNil(t, result["errors"])
+
+	// 6. Rejected Items: items that can't be applied are rejected without rejecting the rest
+	rejectedSync := sync(user2Token, map[string]interface{}{
+		"reviews": []interface{}{
+			map[string]interface{}{"termId": termIDs[0], "rating": "GOOD", "clientTimestamp": at(time.Hour)},
+			map[string]interface{}{"termId": orderedTermIDs[0], "rating": "GOOD", "clientTimestamp": now.Add(time.Hour).Format(time.RFC3339)},
+		},
+		"matchActivities": []interface{}{
+			map[string]interface{}{
+				"clientTimestamp": at(time.Hour),
+				"matchActivity": map[string]interface{}{
+					"durationMs":       5000,
+					"termIds":          []string{},
+					"incorrectPairIds": []interface{}{},
+				},
+			},
+		},
+	})
+	rejected := rejectedSync["rejected"].([]interface{})
+	require.Len(t, rejected, 2)
+	require.ElementsMatch(t, []interface{}{float64(0), float64(1)}, []interface{}{
+		getNested(rejectedSync, "rejected", 0, "index"),
+		getNested(rejectedSync, "rejected", 1, "index"),
+	})
+	require.Equal(t, "REVIEW", getNested(rejectedSync, "rejected", 0, "type"))
+	require.Nil(t, cardFor(rejectedSync, termIDs[0]), "user2 can't review user1's private terms")
+	require.NotNil(t, getNested(rejectedSync, "matchActivities", 0, "id"))
+
+	// 7. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", syncQuery, map[string]interface{}{"input": map[string]interface{}{}})
+	require.NotNil(t, result["errors"])
+}
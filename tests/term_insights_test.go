@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermInsights(t *testing.T) {
+	// 1. Setup: user1 creates a public studyset, and new users study it
+	studysetID, termIDs := createStudysetWithTerms(t, user1Token, "Term Insights Set", false,
+		[2]string{"rojo", "red"},
+		[2]string{"azul", "blue"},
+		[2]string{"verde", "green"},
+	)
+	tokens := make([]string, 5)
+	for i := range tokens {
+		_, tokens[i] = createTestUser(t, fmt.Sprintf("insightsUser%d", i))
+	}
+
+	insightsQuery := `query Insights($id: ID!) {
+		studyset(id: $id) {
+			termInsights {
+				refreshedAt minUsers
+				terms { term { id } enoughData users attempts errorRate commonWrongTerm { id } commonWrongAnswer }
+			}
+		}
+	}`
+	insights := func() map[string]interface{} {
+		t.Helper()
+		result := graphqlRequest(t, user1Token, insightsQuery, map[string]interface{}{"id": studysetID})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "studyset", "termInsights").(map[string]interface{})
+	}
+	refresh := func() {
+		t.Helper()
+		_, err := dbPool.Exec(context.Background(), "SELECT refresh_term_insights()")
+		require.Nil(t, err)
+	}
+	/* each user answers rojo wrong in a practice test, and matches it with azul before getting it right */
+	study := func(token string, wrongAnswer string) {
+		t.Helper()
+		result := graphqlRequest(t, token, `mutation Record($input: PracticeTestInput!) {
+			recordPracticeTest(input: $input) { id }
+		}`, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+			map[string]interface{}{"frq": map[string]interface{}{
+				"term":           map[string]interface{}{"id": termIDs[0], "term": "rojo", "def": "red"},
+				"answerWith":     "DEF",
+				"answeredString": wrongAnswer,
+			}},
+		}}})
+		require.Nil(t, result["errors"])
+		result = graphqlRequest(t, token, `mutation Record($input: MatchActivityInput!) {
+			recordMatchActivity(input: $input) { id }
+		}`, map[string]interface{}{"input": map[string]interface{}{
+			"durationMs":       10000,
+			"termIds":          termIDs,
+			"incorrectPairIds": []interface{}{[]string{termIDs[0], termIDs[1]}},
+		}})
+		require.Nil(t, result["errors"])
+	}
+
+	// 2. Author Only: other users and unauthenticated requests are rejected
+	result := graphqlRequest(t, user2Token, insightsQuery, map[string]interface{}{"id": studysetID})
+	require.NotNil(t, result["errors"])
+	result = graphqlRequest(t, "", insightsQuery, map[string]interface{}{"id": studysetID})
+	require.NotNil(t, result["errors"])
+
+	// 3. Minimum Users: terms answered by fewer than minUsers users don't have stats
+	for _, token := range tokens[:4] {
+		study(token, "Blue")
+	}
+	refresh()
+	i := insights()
+	require.Equal(t, float64(5), i["minUsers"])
+	require.NotNil(t, i["refreshedAt"])
+	terms := i["terms"].([]interface{})
+	require.Len(t, terms, 3)
+	first := terms[0].(map[string]interface{})
+	require.Equal(t, termIDs[0], getNested(first, "term", "id"))
+	require.Equal(t, false, first["enoughData"])
+	require.Nil(t, first["attempts"])
+	require.Nil(t, first["errorRate"])
+	require.Nil(t, first["commonWrongTerm"])
+
+	// 4. Rollup: new answers are only counted after the rollup is refreshed
+	study(tokens[4], "green")
+	require.Equal(t, false, getNested(insights()["terms"].([]interface{})[0].(map[string]interface{}), "enoughData"))
+	refresh()
+	first = insights()["terms"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, true, first["enoughData"])
+	require.Equal(t, float64(5), first["users"])
+	/* each user answered rojo 3 times (practice test, incorrect pair, then matched) and got it wrong twice */
+	require.Equal(t, float64(15), first["attempts"])
+	require.InDelta(t, 2.0/3.0, first["errorRate"], 0.0001)
+
+	// 5. Common Wrong Answers: only shown if at least minUsers users gave them
+	require.Equal(t, termIDs[1], getNested(first, "commonWrongTerm", "id"))
+	require.Nil(t, first["commonWrongAnswer"], "only 4 users answered blue")
+	for _, token := range tokens[4:] {
+		study(token, "BLUE")
+	}
+	refresh()
+	first = insights()["terms"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "blue", first["commonWrongAnswer"])
+}
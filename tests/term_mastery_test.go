@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermMastery(t *testing.T) {
+	// 1. Setup: a new user creates a public studyset with 4 terms
+	_, token := createTestUser(t, "masteryUser")
+	studysetID, termIDs := createStudysetWithTerms(t, token, "Mastery Set", false,
+		[2]string{"gato", "cat"},
+		[2]string{"perro", "dog"},
+		[2]string{"pez", "fish"},
+		[2]string{"pájaro", "bird"},
+	)
+	now := time.Now().UTC()
+
+	termMastery := func(orderBy string, first interface{}) []interface{} {
+		t.Helper()
+		result := graphqlRequest(t, token, `query Mastery($id: ID!, $orderBy: TermMasteryOrder, $first: Int) {
+			studyset(id: $id) {
+				myTermMastery(orderBy: $orderBy, first: $first) {
+					term { id } level score recentAccuracy lastReviewedAt
+				}
+			}
+		}`, map[string]interface{}{"id": studysetID, "orderBy": orderBy, "first": first})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "studyset", "myTermMastery").([]interface{})
+	}
+	masteryPercent := func() float64 {
+		t.Helper()
+		result := graphqlRequest(t, token, `query Percent($id: ID!) {
+			studyset(id: $id) { myMasteryPercent }
+		}`, map[string]interface{}{"id": studysetID})
+		require.Nil(t, result["errors"])
+		return getNested(result, "data", "studyset", "myMasteryPercent").(float64)
+	}
+	byTermID := func(entries []interface{}) map[string]map[string]interface{} {
+		m := make(map[string]map[string]interface{})
+		for _, e := range entries {
+			entry := e.(map[string]interface{})
+			m[getNested(entry, "term", "id").(string)] = entry
+		}
+		return m
+	}
+
+	// 2. New: terms that haven't been studied are NEW, and the studyset is 0% mastered
+	entries := termMastery("STUDYSET", nil)
+	require.Len(t, entries, 4)
+	for i, e := range entries {
+		entry := e.(map[string]interface{})
+		require.Equal(t, termIDs[i], getNested(entry, "term", "id"))
+		require.Equal(t, "NEW", entry["level"])
+		require.Equal(t, float64(0), entry["score"])
+		require.Nil(t, entry["recentAccuracy"])
+	}
+	require.Equal(t, float64(0), masteryPercent())
+
+	// 3. Levels: term progress and practice test answers decide each term's level
+	result := graphqlRequest(t, token, `mutation Progress($termProgress: [TermProgressInput!]!) {
+		updateTermProgress(termProgress: $termProgress) { id }
+	}`, map[string]interface{}{"termProgress": []interface{}{
+		map[string]interface{}{"termId": termIDs[0], "termReviewedAt": now.Format(time.RFC3339), "termCorrectIncrease": 8},
+		map[string]interface{}{"termId": termIDs[1], "termReviewedAt": now.Format(time.RFC3339), "termCorrectIncrease": 1, "termIncorrectIncrease": 5},
+	}})
+	require.Nil(t, result["errors"])
+	result = graphqlRequest(t, token, `mutation Record($input: PracticeTestInput!) {
+		recordPracticeTest(input: $input) { id }
+	}`, map[string]interface{}{"input": map[string]interface{}{"questions": []interface{}{
+		map[string]interface{}{"frq": map[string]interface{}{
+			"term":           map[string]interface{}{"id": termIDs[2], "term": "pez", "def": "fish"},
+			"answerWith":     "DEF",
+			"answeredString": "bird",
+		}},
+	}}})
+	require.Nil(t, result["errors"])
+	mastery := byTermID(termMastery("STUDYSET", nil))
+	require.Equal(t, "MASTERED", mastery[termIDs[0]]["level"])
+	require.Equal(t, float64(1), mastery[termIDs[0]]["recentAccuracy"])
+	require.NotNil(t, mastery[termIDs[0]]["lastReviewedAt"])
+	require.Equal(t, "LEARNING", mastery[termIDs[1]]["level"])
+	require.Equal(t, "LEARNING", mastery[termIDs[2]]["level"])
+	require.Equal(t, float64(0), mastery[termIDs[2]]["recentAccuracy"])
+	require.Equal(t, "NEW", mastery[termIDs[3]]["level"])
+
+	// 4. Weakest: studied terms with the lowest scores are first, then new terms
+	weakest := termMastery("WEAKEST", nil)
+	require.Len(t, weakest, 4)
+	require.Equal(t, termIDs[1], getNested(weakest[0].(map[string]interface{}), "term", "id"))
+	require.Equal(t, termIDs[2], getNested(weakest[1].(map[string]interface{}), "term", "id"))
+	require.Equal(t, termIDs[0], getNested(weakest[2].(map[string]interface{}), "term", "id"))
+	require.Equal(t, termIDs[3], getNested(weakest[3].(map[string]interface{}), "term", "id"))
+	require.Len(t, termMastery("WEAKEST", 2), 2)
+
+	// 5. Recent Answers: recent FSRS reviews are used instead of older term progress counts
+	for i := 0; i < 10; i++ {
+		result := graphqlRequest(t, token, `mutation Review($termId: ID!, $reviewedAt: String) {
+			reviewTerm(termId: $termId, rating: GOOD, reviewedAt: $reviewedAt) { state }
+		}`, map[string]interface{}{"termId": termIDs[1], "reviewedAt": now.Add(time.Duration(i-10) * time.Minute).Format(time.RFC3339)})
+		require.Nil(t, result["errors"])
+	}
+	mastery = byTermID(termMastery("STUDYSET", nil))
+	require.Equal(t, float64(1), mastery[termIDs[1]]["recentAccuracy"])
+
+	// 6. Percent: the studyset's mastery percentage is the average score of its terms
+	var total float64
+	for _, entry := range mastery {
+		total += entry["score"].(float64)
+	}
+	require.InDelta(t, total/4*100, masteryPercent(), 0.0001)
+
+	// 7. Practice Tests: MASTERY weighting generates tests from the studyset
+	result = graphqlRequest(t, token, `query Generate($ids: [ID!]!) {
+		generatePracticeTest(studysetIds: $ids, count: 2, weighting: MASTERY) { questions { id } }
+	}`, map[string]interface{}{"ids": []string{studysetID}})
+	require.Nil(t, result["errors"])
+	require.Len(t, getNested(result, "data", "generatePracticeTest", "questions").([]interface{}), 2)
+
+	// 8. Auth: unauthenticated requests are rejected
+	result = graphqlRequest(t, "", `query Mastery($id: ID!) {
+		studyset(id: $id) { myTermMastery { level } }
+	}`, map[string]interface{}{"id": studysetID})
+	require.NotNil(t, result["errors"])
+}
@@ -145,6 +145,20 @@ func TestTermLifecycle(t *testing.T) {
 	var unauthorizedDeleteResult map[string]interface{}
 	json.NewDecoder(resp.Body).Decode(&unauthorizedDeleteResult)
 	require.NotNil(t, unauthorizedDeleteResult["errors"], "user2 should not be able to delete terms from user1's studyset")
+
+	// 8. Invalid Image Key (user1 adding a term with an image key that was never uploaded)
+	createTermsBody["variables"].(map[string]interface{})["terms"] = []map[string]interface{}{
+		{"term": "T3", "def": "D3", "termImageKey": "term-images/does-not-exist.webp", "sortOrder": 2},
+	}
+	req, _ = http.NewRequest(http.MethodPost, testServer.URL+"/graphql", marshal(createTermsBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	resp, _ = http.DefaultClient.Do(req)
+	var invalidImageResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&invalidImageResult)
+	require.NotNil(t, invalidImageResult["errors"], "unknown image keys should be rejected")
+	errs := invalidImageResult["errors"].([]interface{})
+	require.Equal(t, "invalid image key", errs[0].(map[string]interface{})["message"])
 }
 
 func TestTermNoAuth(t *testing.T) {
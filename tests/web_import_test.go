@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pollWebImportJob(t *testing.T, jobID string) map[string]interface{} {
+	t.Helper()
+	for range 50 {
+		resp, err := http.Get(testServer.URL + "/web-import/jobs/" + jobID)
+		require.NoError(t, err)
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		status := getNested(result, "data", "status")
+		if status != "PENDING" && status != "RUNNING" {
+			return result
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("web import job %s didn't finish", jobID)
+	return nil
+}
+
+func TestWebImportJobs(t *testing.T) {
+	setURL := "https://quizlet.com/900/cell-organelles-flash-cards/"
+
+	// 1. Create Job: POST /web-import/jobs returns a job
+	resp, err := http.Post(testServer.URL+"/web-import/jobs", "application/json", marshal(map[string]interface{}{
+		"url": setURL,
+	}))
+	require.NoError(t, err)
+	var createResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&createResult)
+	require.Equal(t, false, createResult["error"])
+	jobID := getNested(createResult, "data", "id").(string)
+	require.Equal(t, "quizlet", getNested(createResult, "data", "source"))
+
+	// 2. Poll Status: the job finishes with the parsed studyset
+	jobResult := pollWebImportJob(t, jobID)
+	require.Equal(t, "SUCCEEDED", getNested(jobResult, "data", "status"))
+	require.Equal(t, "Cell Organelles", getNested(jobResult, "data", "result", "title"))
+	terms := getNested(jobResult, "data", "result", "terms").([]interface{})
+	require.Len(t, terms, 3)
+	require.Equal(t, "Mitochondria", terms[0].(map[string]interface{})["term"])
+	require.Equal(t, "<p><b>Powerhouse</b> of the cell; makes ATP</p>", terms[0].(map[string]interface{})["defRichText"])
+	require.Equal(t, "https://o.quizlet.com/abc123_m.png", terms[0].(map[string]interface{})["defImageUrl"])
+
+	// 3. Cached: importing the same url (with a different form) returns the cached job
+	resp, err = http.Post(testServer.URL+"/web-import/jobs", "application/json", marshal(map[string]interface{}{
+		"url": "https://www.quizlet.com/900/cell-organelles-flash-cards?utm_source=share",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var cachedResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&cachedResult)
+	require.Equal(t, jobID, getNested(cachedResult, "data", "id"))
+	require.Equal(t, true, getNested(cachedResult, "data", "cached"))
+
+	// 4. Sync Import: POST /web-import still responds with terms directly
+	resp, err = http.Post(testServer.URL+"/web-import", "application/json", marshal(map[string]interface{}{
+		"url": setURL,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var syncResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&syncResult)
+	require.Equal(t, "Cell Organelles", syncResult["title"])
+	require.Equal(t, []interface{}{"Mitochondria", "Powerhouse of the cell; makes ATP"}, syncResult["terms"].([]interface{})[0])
+
+	// 5. Failed Job: a page that can't be fetched fails, and isn't cached
+	resp, err = http.Post(testServer.URL+"/web-import/jobs", "application/json", marshal(map[string]interface{}{
+		"url": "https://quizlet.com/404/not-saved/",
+	}))
+	require.NoError(t, err)
+	var failResult map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&failResult)
+	failedJobID := getNested(failResult, "data", "id").(string)
+	failedJob := pollWebImportJob(t, failedJobID)
+	require.Equal(t, "FAILED", getNested(failedJob, "data", "status"))
+	require.Equal(t, "error fetching page", getNested(failedJob, "data", "error"))
+
+	// 6. Invalid Input: unsupported urls, unknown job ids, and downloadImages anonymously or without storage configured
+	downloadImagesBody := map[string]interface{}{
+		"url":            setURL,
+		"downloadImages": true,
+	}
+	resp, err = http.Post(testServer.URL+"/web-import/jobs", "application/json", marshal(downloadImagesBody))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/web-import/jobs", marshal(downloadImagesBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user1Token)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Post(testServer.URL+"/web-import/jobs", "application/json", marshal(map[string]interface{}{
+		"url": "https://example.org/flashcards/123",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(testServer.URL + "/web-import/jobs/00000000-0000-0000-0000-000000000000")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(testServer.URL + "/web-import/jobs/not-a-uuid")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
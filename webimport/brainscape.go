@@ -0,0 +1,70 @@
+package webimport
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+/*
+Brainscape parses Brainscape deck pages,
+which render each card as a `.flashcard-row` with question/answer faces
+*/
+type Brainscape struct{}
+
+func (Brainscape) Name() string {
+	return "brainscape"
+}
+
+func (Brainscape) Hosts() []string {
+	return []string{"brainscape.com"}
+}
+
+func (Brainscape) FetchURL(u *url.URL) (string, error) {
+	if !strings.HasPrefix(u.Path, "/flashcards/") {
+		return "", ErrUnsupportedURL
+	}
+	return u.String(), nil
+}
+
+func (Brainscape) Parse(r io.Reader) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Title: strings.TrimSpace(doc.Find("h1.deck-name, h1").First().Text()),
+	}
+	if result.Title == "" {
+		result.Title = metaTitle(doc)
+	}
+	doc.Find(".flashcard-row").Each(func(_ int, row *goquery.Selection) {
+		question := row.Find(".card-question .scf-face").First()
+		if question.Length() == 0 {
+			question = row.Find(".card-question").First()
+		}
+		answer := row.Find(".card-answer .scf-face").First()
+		if answer.Length() == 0 {
+			answer = row.Find(".card-answer").First()
+		}
+		if question.Length() == 0 && answer.Length() == 0 {
+			return
+		}
+		result.Terms = append(result.Terms, Term{
+			Term:         selectionText(question),
+			Def:          selectionText(answer),
+			TermRichText: formattedOrEmpty(selectionHTML(question)),
+			DefRichText:  formattedOrEmpty(selectionHTML(answer)),
+			TermImageURL: firstImageURL(question),
+			DefImageURL:  firstImageURL(answer),
+		})
+	})
+	if len(result.Terms) == 0 {
+		return nil, errors.New("no terms in brainscape page")
+	}
+	return result, nil
+}
@@ -0,0 +1,220 @@
+package webimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	qzfrAPIConfig "quizfreely/api/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNoFetchers = errors.New("no web import fetchers configured")
+
+/* default per-fetcher timeouts, overridden by web_import_fetcher_timeouts in config.toml */
+var defaultFetcherTimeouts = map[string]time.Duration{
+	"direct":    20 * time.Second,
+	"crawlbase": 90 * time.Second,
+	"zyte":      90 * time.Second,
+	"file":      5 * time.Second,
+}
+
+type FetcherMetrics struct {
+	Attempts    int64 `json:"attempts"`
+	Successes   int64 `json:"successes"`
+	Failures    int64 `json:"failures"`
+	Timeouts    int64 `json:"timeouts"`
+	TotalMillis int64 `json:"totalMillis"`
+}
+
+type fetcherCounters struct {
+	attempts    atomic.Int64
+	successes   atomic.Int64
+	failures    atomic.Int64
+	timeouts    atomic.Int64
+	totalMillis atomic.Int64
+}
+
+type chainEntry struct {
+	fetcher  Fetcher
+	timeout  time.Duration
+	counters *fetcherCounters
+}
+
+/*
+Chain tries each fetcher in order until one works,
+giving each fetcher its own timeout and keeping metrics per fetcher
+*/
+type Chain struct {
+	entries []chainEntry
+}
+
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) Add(fetcher Fetcher, timeout time.Duration) {
+	c.entries = append(c.entries, chainEntry{
+		fetcher:  fetcher,
+		timeout:  timeout,
+		counters: &fetcherCounters{},
+	})
+}
+
+func (c *Chain) Len() int {
+	return len(c.entries)
+}
+
+/* Timeout returns the longest Fetch can take, if every fetcher times out */
+func (c *Chain) Timeout() time.Duration {
+	var total time.Duration
+	for _, entry := range c.entries {
+		total += entry.timeout
+	}
+	return total
+}
+
+/* Names returns fetcher names in the order they're tried */
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.entries))
+	for i, entry := range c.entries {
+		names[i] = entry.fetcher.Name()
+	}
+	return names
+}
+
+/*
+Fetch returns the page from the first fetcher that succeeds, and that fetcher's name.
+If every fetcher fails, the returned error joins each fetcher's error.
+*/
+func (c *Chain) Fetch(ctx context.Context, targetURL string) ([]byte, string, error) {
+	if len(c.entries) == 0 {
+		return nil, "", ErrNoFetchers
+	}
+	var errs []error
+	for _, entry := range c.entries {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		name := entry.fetcher.Name()
+		entry.counters.attempts.Add(1)
+		start := time.Now()
+
+		fetchCtx, cancel := context.WithTimeout(ctx, entry.timeout)
+		data, err := entry.fetcher.Fetch(fetchCtx, targetURL)
+		timedOut := errors.Is(fetchCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		elapsed := time.Since(start)
+		entry.counters.totalMillis.Add(elapsed.Milliseconds())
+		if err == nil && len(data) == 0 {
+			err = errors.New("empty response")
+		}
+		if err != nil {
+			entry.counters.failures.Add(1)
+			if timedOut {
+				entry.counters.timeouts.Add(1)
+			}
+			log.Warn().Err(err).Str("fetcher", name).Dur("elapsed", elapsed).Msg("web import fetcher failed")
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		entry.counters.successes.Add(1)
+		log.Debug().Str("fetcher", name).Dur("elapsed", elapsed).Msg("web import fetcher succeeded")
+		return data, name, nil
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+/* Metrics returns a snapshot of each fetcher's metrics since startup */
+func (c *Chain) Metrics() map[string]FetcherMetrics {
+	metrics := make(map[string]FetcherMetrics, len(c.entries))
+	for _, entry := range c.entries {
+		metrics[entry.fetcher.Name()] = FetcherMetrics{
+			Attempts:    entry.counters.attempts.Load(),
+			Successes:   entry.counters.successes.Load(),
+			Failures:    entry.counters.failures.Load(),
+			Timeouts:    entry.counters.timeouts.Load(),
+			TotalMillis: entry.counters.totalMillis.Load(),
+		}
+	}
+	return metrics
+}
+
+/*
+ChainFromConfig builds the fetcher chain from web_import_fetchers in config.toml.
+If web_import_fetchers isn't set, it falls back to the older
+use_zyte, use_crawlbase, and try_zyte_before_crawlbase options.
+*/
+func ChainFromConfig(config qzfrAPIConfig.Config) (*Chain, error) {
+	/* no client timeout because each fetcher's timeout is set with context */
+	apiClient := &http.Client{}
+
+	names := config.WebImportFetchers
+	if len(names) == 0 {
+		if config.UseZyte && (config.TryZyteBeforeCrawlbase || !config.UseCrawlbase) {
+			names = append(names, "zyte")
+			if config.UseCrawlbase {
+				names = append(names, "crawlbase")
+			}
+		} else if config.UseCrawlbase {
+			names = append(names, "crawlbase")
+			if config.UseZyte {
+				names = append(names, "zyte")
+			}
+		}
+	}
+
+	chain := NewChain()
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil, fmt.Errorf("web import fetcher %q is listed more than once in web_import_fetchers", name)
+		}
+		seen[name] = true
+
+		var fetcher Fetcher
+		switch name {
+		case "direct":
+			direct, err := NewDirectFetcher(config.WebImportUserAgent, config.WebImportProxyURL)
+			if err != nil {
+				return nil, err
+			}
+			fetcher = direct
+		case "crawlbase":
+			if config.CrawlbaseAPIKey == "" {
+				return nil, errors.New("web import fetcher crawlbase needs crawlbase_api_key")
+			}
+			fetcher = &CrawlbaseFetcher{Client: apiClient, APIKey: config.CrawlbaseAPIKey}
+		case "zyte":
+			if config.ZyteAPIKey == "" {
+				return nil, errors.New("web import fetcher zyte needs zyte_api_key")
+			}
+			fetcher = &ZyteFetcher{Client: apiClient, APIKey: config.ZyteAPIKey}
+		case "file":
+			if config.WebImportFileDir == "" {
+				return nil, errors.New("web import fetcher file needs web_import_file_dir")
+			}
+			fetcher = &FileFetcher{Dir: config.WebImportFileDir}
+		default:
+			return nil, fmt.Errorf("unknown web import fetcher %q in web_import_fetchers, expected direct, crawlbase, zyte, or file", name)
+		}
+
+		timeout := defaultFetcherTimeouts[name]
+		if seconds, ok := config.WebImportFetcherTimeouts[name]; ok {
+			if seconds < 1 {
+				return nil, fmt.Errorf("web_import_fetcher_timeouts.%s must be >= 1 (seconds)", name)
+			}
+			timeout = time.Duration(seconds) * time.Second
+		}
+		chain.Add(fetcher, timeout)
+	}
+	return chain, nil
+}
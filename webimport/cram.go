@@ -0,0 +1,65 @@
+package webimport
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+/*
+Cram parses Cram flashcard pages,
+which list every card server-side in `#flashCardsListingTable`
+*/
+type Cram struct{}
+
+func (Cram) Name() string {
+	return "cram"
+}
+
+func (Cram) Hosts() []string {
+	return []string{"cram.com"}
+}
+
+func (Cram) FetchURL(u *url.URL) (string, error) {
+	if !strings.HasPrefix(u.Path, "/flashcards/") {
+		return "", ErrUnsupportedURL
+	}
+	return u.String(), nil
+}
+
+func (Cram) Parse(r io.Reader) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Title: strings.TrimSpace(doc.Find("h1").First().Text()),
+	}
+	if result.Title == "" {
+		result.Title = metaTitle(doc)
+	}
+	doc.Find("#flashCardsListingTable tr").Each(func(_ int, row *goquery.Selection) {
+		front := row.Find(".front_text").First()
+		back := row.Find(".back_text").First()
+		if front.Length() == 0 && back.Length() == 0 {
+			/* header row */
+			return
+		}
+		result.Terms = append(result.Terms, Term{
+			Term:         selectionText(front),
+			Def:          selectionText(back),
+			TermRichText: formattedOrEmpty(selectionHTML(front)),
+			DefRichText:  formattedOrEmpty(selectionHTML(back)),
+			TermImageURL: firstImageURL(front),
+			DefImageURL:  firstImageURL(back),
+		})
+	})
+	if len(result.Terms) == 0 {
+		return nil, errors.New("no terms in cram page")
+	}
+	return result, nil
+}
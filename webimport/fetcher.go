@@ -0,0 +1,285 @@
+package webimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"quizfreely/api/safedial"
+)
+
+/* pages bigger than this aren't imported (quizlet pages are usually around 1-3 MB) */
+const maxFetchSize = 20 << 20 /* 20 MB */
+
+/* ErrPageTooLarge is returned by fetchers instead of cutting off the page, because importers would return partial term lists */
+var ErrPageTooLarge = errors.New("page too large")
+
+const DefaultUserAgent = "Mozilla/5.0 (compatible; QuizfreelyWebImport/1.0; +https://quizfreely.org)"
+
+/* Fetcher gets the raw page for a URL */
+type Fetcher interface {
+	/* Name is used in config.toml, logs, and metrics, like "direct" */
+	Name() string
+	Fetch(ctx context.Context, targetURL string) ([]byte, error)
+}
+
+type DirectFetcher struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+/*
+NewDirectFetcher makes a fetcher that requests pages itself,
+optionally through an HTTP(S)/SOCKS5 proxy (empty proxyURL means no proxy).
+Pages are fetched from urls users import, so without a proxy, it can't connect to non-public addresses (see safedial),
+and redirects are checked with checkFetchRedirect
+*/
+func NewDirectFetcher(userAgent string, proxyURL string) (*DirectFetcher, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid web import proxy url: %w", err)
+		}
+		/* the proxy (from config.toml) connects to the page itself, so the dialer only connects to the proxy,
+		which can be on a private network */
+		transport.Proxy = http.ProxyURL(parsed)
+	} else {
+		transport.Proxy = nil
+		transport.DialContext = safedial.NewDialer(30 * time.Second).DialContext
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &DirectFetcher{
+		Client: &http.Client{
+			Transport:     transport,
+			CheckRedirect: checkFetchRedirect,
+		},
+		UserAgent: userAgent,
+	}, nil
+}
+
+/*
+checkFetchRedirect re-checks each redirect, because Registry.Lookup only checked the original url.
+Redirects can go to other hosts (google sheets exports redirect to googleusercontent.com),
+but only to https urls without ports or credentials, and not to non-public ip addresses or localhost
+*/
+func checkFetchRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("too many redirects")
+	}
+	u := req.URL
+	if u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return fmt.Errorf("page redirected to unsupported url %s", u.Redacted())
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("page redirected to %s", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !safedial.IsPublicAddr(addr) {
+		return fmt.Errorf("page redirected to non-public address %s", addr)
+	}
+	return nil
+}
+
+func (f *DirectFetcher) Name() string {
+	return "direct"
+}
+
+func (f *DirectFetcher) Fetch(ctx context.Context, targetURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/csv;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("direct fetch got status %d", resp.StatusCode)
+	}
+	return readLimited(resp.Body)
+}
+
+type CrawlbaseFetcher struct {
+	Client *http.Client
+	APIKey string
+}
+
+func (f *CrawlbaseFetcher) Name() string {
+	return "crawlbase"
+}
+
+func (f *CrawlbaseFetcher) Fetch(ctx context.Context, targetURL string) ([]byte, error) {
+	params := url.Values{}
+	params.Add("token", f.APIKey)
+	params.Add("url", targetURL)
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		"https://api.crawlbase.com/?"+params.Encode(),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		/* crawlbase puts the target site's status in original_status,
+		and its own status in pc_status */
+		return nil, fmt.Errorf(
+			"crawlbase got status %d (pc_status %s, original_status %s)",
+			resp.StatusCode,
+			resp.Header.Get("pc_status"),
+			resp.Header.Get("original_status"),
+		)
+	}
+	return readLimited(resp.Body)
+}
+
+type ZyteFetcher struct {
+	Client *http.Client
+	APIKey string
+}
+
+type zyteReqBody struct {
+	URL              string `json:"url"`
+	HTTPResponseBody bool   `json:"httpResponseBody"`
+}
+type zyteRespBody struct {
+	StatusCode       int    `json:"statusCode"`
+	HTTPResponseBody string `json:"httpResponseBody"`
+}
+
+func (f *ZyteFetcher) Name() string {
+	return "zyte"
+}
+
+func (f *ZyteFetcher) Fetch(ctx context.Context, targetURL string) ([]byte, error) {
+	reqBodyJSON, err := json.Marshal(
+		zyteReqBody{
+			URL:              targetURL,
+			HTTPResponseBody: true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		"https://api.zyte.com/v1/extract",
+		bytes.NewBuffer(reqBodyJSON),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(f.APIKey, "")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("zyte got status %d", resp.StatusCode)
+	}
+
+	body, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody zyteRespBody
+	err = json.Unmarshal(body, &respBody)
+	if err != nil {
+		return nil, err
+	}
+	if respBody.StatusCode != 0 && (respBody.StatusCode < 200 || respBody.StatusCode > 299) {
+		return nil, fmt.Errorf("zyte target site got status %d", respBody.StatusCode)
+	}
+
+	return base64.StdEncoding.DecodeString(respBody.HTTPResponseBody)
+}
+
+/*
+FileFetcher reads saved pages from a directory instead of the internet,
+for development and testing without API keys.
+Files are named after the URL's host and path,
+with everything except letters, numbers, dots, and dashes replaced with "_",
+so https://quizlet.com/123/abc/ is read from quizlet.com_123_abc.html
+(or .csv for google sheets exports, or no extension).
+*/
+type FileFetcher struct {
+	Dir string
+}
+
+var fileFetcherNameRegex = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func (f *FileFetcher) Name() string {
+	return "file"
+}
+
+/* FileName returns the file name (without extension) that a URL is read from */
+func (f *FileFetcher) FileName(targetURL string) (string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", err
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	return strings.Trim(fileFetcherNameRegex.ReplaceAllString(host+u.Path, "_"), "_"), nil
+}
+
+func (f *FileFetcher) Fetch(ctx context.Context, targetURL string) ([]byte, error) {
+	name, err := f.FileName(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, ext := range []string{".html", ".csv", ""} {
+		data, err := os.ReadFile(filepath.Join(f.Dir, name+ext))
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no saved page for %s in %s", name, f.Dir)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxFetchSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFetchSize {
+		return nil, ErrPageTooLarge
+	}
+	return data, nil
+}
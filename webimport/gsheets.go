@@ -0,0 +1,113 @@
+package webimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+/*
+GoogleSheets imports public Google Sheets as CSV,
+using the first column as terms and the second column as defs.
+A first row that looks like a header ("term", "definition", etc) is skipped.
+*/
+type GoogleSheets struct{}
+
+func (GoogleSheets) Name() string {
+	return "googlesheets"
+}
+
+func (GoogleSheets) Hosts() []string {
+	return []string{"docs.google.com"}
+}
+
+/*
+FetchURL turns a sheet's URL into its CSV export URL, keeping the selected tab (gid).
+Both normal links (/spreadsheets/d/{id}/edit)
+and "publish to web" links (/spreadsheets/d/e/{id}/pubhtml) work.
+*/
+func (GoogleSheets) FetchURL(u *url.URL) (string, error) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "spreadsheets" || parts[1] != "d" {
+		return "", ErrUnsupportedURL
+	}
+
+	gid := u.Query().Get("gid")
+	if gid == "" {
+		if fragment, err := url.ParseQuery(u.Fragment); err == nil {
+			gid = fragment.Get("gid")
+		}
+	}
+
+	export := url.URL{
+		Scheme: "https",
+		Host:   "docs.google.com",
+	}
+	params := url.Values{}
+	if parts[2] == "e" {
+		if len(parts) < 4 || parts[3] == "" {
+			return "", ErrUnsupportedURL
+		}
+		export.Path = "/spreadsheets/d/e/" + parts[3] + "/pub"
+		params.Set("output", "csv")
+	} else {
+		export.Path = "/spreadsheets/d/" + parts[2] + "/export"
+		params.Set("format", "csv")
+	}
+	if gid != "" {
+		params.Set("gid", gid)
+	}
+	export.RawQuery = params.Encode()
+	return export.String(), nil
+}
+
+var gsheetsHeaderTerms = map[string]bool{
+	"term": true, "terms": true, "word": true, "front": true, "question": true,
+}
+
+func (GoogleSheets) Parse(r io.Reader) (*Result, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("<")) {
+		/* private sheets redirect to a sign in page instead of returning CSV */
+		return nil, errors.New("google sheets returned HTML instead of CSV, sheet is probably not public")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for i, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		term := strings.TrimSpace(record[0])
+		def := ""
+		if len(record) >= 2 {
+			def = strings.TrimSpace(record[1])
+		}
+		if i == 0 && gsheetsHeaderTerms[strings.ToLower(term)] {
+			continue
+		}
+		if term == "" && def == "" {
+			continue
+		}
+		result.Terms = append(result.Terms, Term{
+			Term: term,
+			Def:  def,
+		})
+	}
+	if len(result.Terms) == 0 {
+		return nil, errors.New("no terms in google sheets csv")
+	}
+	return result, nil
+}
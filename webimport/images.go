@@ -0,0 +1,139 @@
+package webimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"quizfreely/api/safedial"
+)
+
+/* images bigger than this aren't downloaded (same as the term image upload limit) */
+const maxImageSize = 10 << 20 /* 10 MB */
+
+var ErrImageTooLarge = errors.New("image too large")
+
+/*
+ImageDownloader downloads images from imported sets.
+Image URLs come from the imported page, so they're untrusted:
+only https is allowed, and connections to private/loopback/link-local addresses are blocked
+(checked after DNS resolution, for every redirect too).
+It doesn't use the fetchers' proxy or scraping APIs, images are usually on public CDNs.
+*/
+type ImageDownloader struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+func NewImageDownloader(userAgent string) *ImageDownloader {
+	dialer := safedial.NewDialer(10 * time.Second)
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		MaxIdleConnsPerHost:   4,
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &ImageDownloader{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "https" {
+					return errors.New("image redirected to non-https url")
+				}
+				return nil
+			},
+		},
+		UserAgent: userAgent,
+	}
+}
+
+/* Download gets an image, returning ErrImageTooLarge if it's bigger than 10 MB */
+func (d *ImageDownloader) Download(ctx context.Context, imageURL string) ([]byte, error) {
+	if !strings.HasPrefix(imageURL, "https://") {
+		return nil, errors.New("image url must be https")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", d.UserAgent)
+	req.Header.Set("Accept", "image/webp,image/png,image/jpeg,image/*;q=0.8")
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("image download got status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	return raw, nil
+}
+
+/*
+ImageURLs returns every distinct image URL in the result,
+so each image is only downloaded once even if it's used on multiple terms
+*/
+func (result *Result) ImageURLs() []string {
+	seen := map[string]bool{}
+	var urls []string
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	for _, term := range result.Terms {
+		add(term.TermImageURL)
+		add(term.DefImageURL)
+		for _, side := range term.ExtraSides {
+			add(side.ImageURL)
+		}
+	}
+	return urls
+}
+
+/*
+SetImageKeys sets image keys (and replaces image URLs) for downloaded images.
+keys maps each original image URL to its object key,
+and baseURL is prepended to object keys for the new image URLs.
+Images that aren't in keys (because they failed to download) keep their original URL.
+*/
+func (result *Result) SetImageKeys(keys map[string]string, baseURL string) {
+	for i := range result.Terms {
+		term := &result.Terms[i]
+		if key, ok := keys[term.TermImageURL]; ok {
+			term.TermImageKey, term.TermImageURL = key, baseURL+key
+		}
+		if key, ok := keys[term.DefImageURL]; ok {
+			term.DefImageKey, term.DefImageURL = key, baseURL+key
+		}
+		for j := range term.ExtraSides {
+			side := &term.ExtraSides[j]
+			if key, ok := keys[side.ImageURL]; ok {
+				side.ImageKey, side.ImageURL = key, baseURL+key
+			}
+		}
+	}
+}
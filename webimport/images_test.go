@@ -0,0 +1,82 @@
+package webimport
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageDownloader(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small.png":
+			w.Write([]byte("not really a png"))
+		case "/huge.png":
+			w.Write(bytes.Repeat([]byte{0}, maxImageSize+1))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	/* the test server is on loopback, so the real dialer blocks it */
+	_, err := NewImageDownloader("").Download(context.Background(), server.URL+"/small.png")
+	require.ErrorContains(t, err, "non-public address")
+
+	_, err = NewImageDownloader("").Download(context.Background(), "http://example.org/a.png")
+	require.Error(t, err)
+
+	/* the test server's client skips the address check, to test everything else */
+	downloader := &ImageDownloader{Client: server.Client(), UserAgent: DefaultUserAgent}
+	raw, err := downloader.Download(context.Background(), server.URL+"/small.png")
+	require.NoError(t, err)
+	require.Equal(t, "not really a png", string(raw))
+
+	_, err = downloader.Download(context.Background(), server.URL+"/huge.png")
+	require.ErrorIs(t, err, ErrImageTooLarge)
+
+	_, err = downloader.Download(context.Background(), server.URL+"/missing.png")
+	require.ErrorContains(t, err, "status 404")
+}
+
+func TestResultImageKeys(t *testing.T) {
+	result := &Result{
+		Terms: []Term{
+			{Term: "a", TermImageURL: "https://example.org/a.png", DefImageURL: "https://example.org/b.png"},
+			{Term: "b", DefImageURL: "https://example.org/a.png", ExtraSides: []Side{{Text: "c", ImageURL: "https://example.org/c.png"}}},
+		},
+	}
+	require.Equal(t, []string{
+		"https://example.org/a.png",
+		"https://example.org/b.png",
+		"https://example.org/c.png",
+	}, result.ImageURLs())
+
+	/* b.png failed to download, so it keeps its original url */
+	result.SetImageKeys(map[string]string{
+		"https://example.org/a.png": "images/aa/aa/a.webp",
+		"https://example.org/c.png": "images/cc/cc/c.webp",
+	}, "https://usercontent.example.org/")
+	require.Equal(t, []Term{
+		{
+			Term:         "a",
+			TermImageURL: "https://usercontent.example.org/images/aa/aa/a.webp",
+			TermImageKey: "images/aa/aa/a.webp",
+			DefImageURL:  "https://example.org/b.png",
+		},
+		{
+			Term:        "b",
+			DefImageURL: "https://usercontent.example.org/images/aa/aa/a.webp",
+			DefImageKey: "images/aa/aa/a.webp",
+			ExtraSides: []Side{{
+				Text:     "c",
+				ImageURL: "https://usercontent.example.org/images/cc/cc/c.webp",
+				ImageKey: "images/cc/cc/c.webp",
+			}},
+		},
+	}, result.Terms)
+}
@@ -0,0 +1,202 @@
+package webimport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var ErrUnsupportedURL = errors.New("unsupported url")
+
+/*
+Term is one imported term.
+Term/Def are plain text, and TermRichText/DefRichText are the same text as a small subset of HTML
+(b, i, u, s, sub, sup, mark, code, br, p, ul, ol, li), only set when the site has formatted text.
+Image URLs are the site's URLs, unless images are downloaded to usercontent storage,
+then they're usercontent URLs and TermImageKey/DefImageKey can be used with createTerms.
+*/
+type Term struct {
+	Term         string `json:"term"`
+	Def          string `json:"def"`
+	TermRichText string `json:"termRichText,omitempty"`
+	DefRichText  string `json:"defRichText,omitempty"`
+	TermImageURL string `json:"termImageUrl,omitempty"`
+	DefImageURL  string `json:"defImageUrl,omitempty"`
+	TermImageKey string `json:"termImageKey,omitempty"`
+	DefImageKey  string `json:"defImageKey,omitempty"`
+	/* sides after the first two (Quizlet lets cards have a third side, like "location") */
+	ExtraSides []Side `json:"extraSides,omitempty"`
+}
+
+type Side struct {
+	Label    string `json:"label,omitempty"`
+	Text     string `json:"text"`
+	RichText string `json:"richText,omitempty"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	ImageKey string `json:"imageKey,omitempty"`
+}
+
+type Result struct {
+	Title string `json:"title"`
+	Terms []Term `json:"terms"`
+}
+
+/*
+Importer parses studysets from a site.
+Importers are chosen by URL host using a Registry.
+*/
+type Importer interface {
+	/* Name is used in logs and errors, like "quizlet" */
+	Name() string
+	/* Hosts returns the hosts this importer handles, without "www." */
+	Hosts() []string
+	/*
+		FetchURL returns the URL that should actually be fetched for a user-inputted URL,
+		or ErrUnsupportedURL if the URL is on the right host but isn't a studyset.
+		Most importers fetch the same URL,
+		but some (like Google Sheets) use a different export URL.
+	*/
+	FetchURL(u *url.URL) (string, error)
+	/* Parse parses the fetched page */
+	Parse(r io.Reader) (*Result, error)
+}
+
+type Registry struct {
+	byHost map[string]Importer
+}
+
+func NewRegistry(importers ...Importer) *Registry {
+	reg := &Registry{byHost: map[string]Importer{}}
+	for _, imp := range importers {
+		reg.Register(imp)
+	}
+	return reg
+}
+
+/* DefaultRegistry returns a registry with every built-in importer */
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		Quizlet{},
+		Knowt{},
+		Cram{},
+		Brainscape{},
+		GoogleSheets{},
+	)
+}
+
+func (reg *Registry) Register(imp Importer) {
+	for _, host := range imp.Hosts() {
+		reg.byHost[strings.ToLower(host)] = imp
+	}
+}
+
+/* Hosts returns every registered host, sorted, for error messages */
+func (reg *Registry) Hosts() []string {
+	hosts := make([]string, 0, len(reg.byHost))
+	for host := range reg.byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+/*
+Lookup parses rawURL and returns the importer for its host
+and the URL to fetch, or ErrUnsupportedURL.
+Only https URLs are allowed.
+*/
+func (reg *Registry) Lookup(rawURL string) (Importer, string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return nil, "", ErrUnsupportedURL
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if u.Port() != "" {
+		return nil, "", ErrUnsupportedURL
+	}
+	imp, ok := reg.byHost[host]
+	if !ok {
+		return nil, "", ErrUnsupportedURL
+	}
+	fetchURL, err := imp.FetchURL(u)
+	if err != nil {
+		return nil, "", err
+	}
+	return imp, fetchURL, nil
+}
+
+/* nextData returns the JSON in Next.js's `<script id="__NEXT_DATA__">` */
+func nextData(doc *goquery.Document) ([]byte, error) {
+	raw := strings.TrimSpace(doc.Find(`script#__NEXT_DATA__`).Text())
+	if raw == "" {
+		return nil, errors.New("no __NEXT_DATA__ in page")
+	}
+	return []byte(raw), nil
+}
+
+/*
+unmarshalMaybeString unmarshals JSON that might be an object,
+or might be an object encoded as a JSON string (which Next.js pages sometimes do)
+*/
+func unmarshalMaybeString(raw json.RawMessage, v any) error {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return errors.New("empty JSON")
+	}
+	if trimmed[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(s), v)
+	}
+	if trimmed[0] == '{' {
+		return json.Unmarshal(raw, v)
+	}
+	return errors.New("unexpected JSON token, expected object or string")
+}
+
+/* metaTitle returns og:title, or <title> if there's no og:title */
+func metaTitle(doc *goquery.Document) string {
+	if title, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+/*
+htmlText converts an HTML fragment (like Knowt's term/def HTML) into plain text,
+keeping line breaks between block elements
+*/
+func htmlText(fragment string) string {
+	if !strings.Contains(fragment, "<") {
+		return strings.TrimSpace(fragment)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return strings.TrimSpace(fragment)
+	}
+	return selectionText(doc.Find("body"))
+}
+
+/* selectionText is like goquery's Text, but keeps line breaks for <br> and block elements */
+func selectionText(sel *goquery.Selection) string {
+	sel = sel.Clone()
+	sel.Find("br").ReplaceWithHtml("\n")
+	sel.Find("p, div, li, tr, h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		s.AppendHtml("\n")
+	})
+	var lines []string
+	for _, line := range strings.Split(sel.Text(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,203 @@
+package webimport
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	reg := DefaultRegistry()
+
+	cases := []struct {
+		url      string
+		importer string
+		fetchURL string
+	}{
+		{"https://quizlet.com/900/cell-organelles-flash-cards/", "quizlet", "https://quizlet.com/900/cell-organelles-flash-cards/"},
+		{"https://www.quizlet.com/900/cell-organelles-flash-cards/", "quizlet", "https://www.quizlet.com/900/cell-organelles-flash-cards/"},
+		{"https://knowt.com/flashcards/abc-123", "knowt", "https://knowt.com/flashcards/abc-123"},
+		{"https://www.cram.com/flashcards/spanish-verbs-123", "cram", "https://www.cram.com/flashcards/spanish-verbs-123"},
+		{"https://www.brainscape.com/flashcards/world-capitals-1/456", "brainscape", "https://www.brainscape.com/flashcards/world-capitals-1/456"},
+		{"https://docs.google.com/spreadsheets/d/SHEET_ID/edit#gid=42", "googlesheets", "https://docs.google.com/spreadsheets/d/SHEET_ID/export?format=csv&gid=42"},
+		{"https://docs.google.com/spreadsheets/d/SHEET_ID/edit?usp=sharing", "googlesheets", "https://docs.google.com/spreadsheets/d/SHEET_ID/export?format=csv"},
+		{"https://docs.google.com/spreadsheets/d/e/PUB_ID/pubhtml?gid=7", "googlesheets", "https://docs.google.com/spreadsheets/d/e/PUB_ID/pub?gid=7&output=csv"},
+	}
+	for _, c := range cases {
+		imp, fetchURL, err := reg.Lookup(c.url)
+		require.NoError(t, err, c.url)
+		require.Equal(t, c.importer, imp.Name(), c.url)
+		require.Equal(t, c.fetchURL, fetchURL, c.url)
+	}
+
+	unsupported := []string{
+		"http://quizlet.com/900/cell-organelles-flash-cards/",
+		"https://quizlet.com",
+		"https://quizlet.com.example.org/900/",
+		"https://user@quizlet.com/900/",
+		"https://quizlet.com:8443/900/",
+		"https://example.org/flashcards/123",
+		"https://docs.google.com/document/d/DOC_ID/edit",
+		"https://knowt.com/note/abc-123",
+		"not a url",
+	}
+	for _, u := range unsupported {
+		_, _, err := reg.Lookup(u)
+		require.True(t, errors.Is(err, ErrUnsupportedURL), u)
+	}
+}
+
+func parseFixture(t *testing.T, imp Importer, name string) *Result {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+	result, err := imp.Parse(f)
+	require.NoError(t, err)
+	return result
+}
+
+func TestQuizletParse(t *testing.T) {
+	result := parseFixture(t, Quizlet{}, "quizlet.html")
+	require.Equal(t, "Cell Organelles", result.Title)
+	require.Equal(t, []Term{
+		{
+			Term:        "Mitochondria",
+			Def:         "Powerhouse of the cell; makes ATP",
+			DefRichText: "<p><b>Powerhouse</b> of the cell; makes ATP</p>",
+			DefImageURL: "https://o.quizlet.com/abc123_m.png",
+		},
+		{
+			Term: "Ribosome",
+			Def:  "Site of protein synthesis",
+			ExtraSides: []Side{
+				{Label: "location", Text: "Cytoplasm and rough ER"},
+			},
+		},
+		{Term: "Nucleus", Def: ""},
+	}, result.Terms)
+}
+
+func TestQuizletParseObjectState(t *testing.T) {
+	/* dehydratedReduxStateKey is usually a JSON string, but can also be an object */
+	page := `<html><body><script id="__NEXT_DATA__" type="application/json">
+	{"props":{"pageProps":{"dehydratedReduxStateKey":{"StudyModesCommon":{"studiableData":{"studiableItems":[
+		{"isDeleted":false,"cardSides":[{"sideId":0,"media":[{"plainText":"a"}]},{"sideId":1,"media":[{"plainText":"b"}]}]}
+	]}}}}}}
+	</script></body></html>`
+	result, err := Quizlet{}.Parse(strings.NewReader(page))
+	require.NoError(t, err)
+	require.Equal(t, []Term{{Term: "a", Def: "b"}}, result.Terms)
+
+	_, err = Quizlet{}.Parse(strings.NewReader(`<html><body>captcha</body></html>`))
+	require.Error(t, err)
+}
+
+func TestKnowtParse(t *testing.T) {
+	result := parseFixture(t, Knowt{}, "knowt.html")
+	require.Equal(t, "Photosynthesis Vocab", result.Title)
+	require.Equal(t, []Term{
+		{
+			Term:        "Photosynthesis",
+			Def:         "Process plants use to turn light into chemical energy\nHappens in chloroplasts",
+			DefRichText: "<p>Process plants use to turn <strong>light</strong> into chemical energy</p><p>Happens in chloroplasts</p>",
+			DefImageURL: "https://knowt-user-attachments.s3.amazonaws.com/leaf.png",
+		},
+		{Term: "Chlorophyll", Def: "Green pigment that absorbs light"},
+		{Term: "Stomata", Def: "Pores for gas exchange\non leaves"},
+	}, result.Terms)
+}
+
+func TestCramParse(t *testing.T) {
+	result := parseFixture(t, Cram{}, "cram.html")
+	require.Equal(t, "Spanish Verbs", result.Title)
+	require.Equal(t, []Term{
+		{Term: "hablar", Def: "to speak"},
+		{Term: "comer", Def: "to eat\n(also: to have lunch)"},
+		{Term: "vivir", Def: "to live", DefImageURL: "https://images.cram.com/images/upload-flashcards/live.jpg"},
+	}, result.Terms)
+}
+
+func TestBrainscapeParse(t *testing.T) {
+	result := parseFixture(t, Brainscape{}, "brainscape.html")
+	require.Equal(t, "World Capitals", result.Title)
+	require.Equal(t, []Term{
+		{Term: "France", Def: "Paris"},
+		{Term: "Japan", Def: "Tokyo\n(since 1869)"},
+		{Term: "Kenya", Def: "Nairobi", TermImageURL: "https://s3.amazonaws.com/brainscape-prod/kenya-map.png"},
+	}, result.Terms)
+}
+
+func TestGoogleSheetsParse(t *testing.T) {
+	result := parseFixture(t, GoogleSheets{}, "gsheets.csv")
+	require.Equal(t, []Term{
+		{Term: "osmosis", Def: "diffusion of water across a membrane"},
+		{Term: "diffusion", Def: "movement from high to low concentration, passive"},
+		{Term: "active transport", Def: "uses ATP"},
+	}, result.Terms)
+
+	_, err := GoogleSheets{}.Parse(strings.NewReader("<!DOCTYPE html><html><body>Sign in</body></html>"))
+	require.Error(t, err)
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := map[string]string{
+		`<p>plain</p>`: `<p>plain</p>`,
+		`<p class="x" onclick="alert(1)"><strong>bold</strong> &amp; <span style="color:red">red</span></p>`: `<p><strong>bold</strong> &amp; red</p>`,
+		`a<script>alert(1)</script><img src="https://example.org/a.png">b`:                                   `ab`,
+		`<ul><li>one<li>two</ul>`: `<ul><li>one</li><li>two</li></ul>`,
+		`1 < 2`:                   `1 &lt; 2`,
+	}
+	for input, expected := range cases {
+		require.Equal(t, expected, sanitizeHTML(input), input)
+	}
+
+	require.Equal(t, "", formattedOrEmpty("<p>just text</p><p>two lines<br>here</p>"))
+	require.Equal(t, "<p><i>x</i></p>", formattedOrEmpty("<p><i>x</i></p>"))
+}
+
+func TestQuizletRichTextHTML(t *testing.T) {
+	richText := `{"type":"doc","content":[
+		{"type":"paragraph","content":[
+			{"type":"text","text":"H"},
+			{"type":"text","marks":[{"type":"sub"}],"text":"2"},
+			{"type":"text","text":"O is "},
+			{"type":"text","marks":[{"type":"b"},{"type":"bgY"}],"text":"water"},
+			{"type":"hardBreak"},
+			{"type":"text","text":"<not a tag>"}
+		]},
+		{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"liquid"}]}]}]}
+	]}`
+	require.Equal(t,
+		"<p>H<sub>2</sub>O is <b><mark>water</mark></b><br>&lt;not a tag&gt;</p><ul><li><p>liquid</p></li></ul>",
+		quizletRichTextHTML(richText),
+	)
+	require.Equal(t, "", quizletRichTextHTML("not json"))
+}
+
+func TestAbsoluteImageURL(t *testing.T) {
+	require.Equal(t, "https://example.org/a.png", absoluteImageURL(" https://example.org/a.png "))
+	require.Equal(t, "https://example.org/a.png", absoluteImageURL("//example.org/a.png"))
+	require.Equal(t, "", absoluteImageURL("http://example.org/a.png"))
+	require.Equal(t, "", absoluteImageURL("/images/a.png"))
+	require.Equal(t, "", absoluteImageURL("data:image/png;base64,AAAA"))
+}
+
+func TestNormalizeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://quizlet.com/900/cell-organelles-flash-cards/":                                 "https://quizlet.com/900/cell-organelles-flash-cards",
+		"https://www.Quizlet.com/900/cell-organelles-flash-cards/?utm_source=x&fbclid=abc":     "https://quizlet.com/900/cell-organelles-flash-cards",
+		"https://quizlet.com/900/cell-organelles-flash-cards#terms":                            "https://quizlet.com/900/cell-organelles-flash-cards",
+		"https://docs.google.com/spreadsheets/d/SHEET_ID/export?gid=42&format=csv":             "https://docs.google.com/spreadsheets/d/SHEET_ID/export?format=csv&gid=42",
+		"https://docs.google.com/spreadsheets/d/SHEET_ID/export?format=csv&gid=42&usp=sharing": "https://docs.google.com/spreadsheets/d/SHEET_ID/export?format=csv&gid=42",
+	}
+	for input, expected := range cases {
+		normalized, err := NormalizeURL(input)
+		require.NoError(t, err)
+		require.Equal(t, expected, normalized, input)
+	}
+}
@@ -0,0 +1,101 @@
+package webimport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+/*
+Knowt parses Knowt flashcard set pages,
+which have terms in `__NEXT_DATA__` under pageProps.flashcardSet,
+with each term/def as an HTML fragment
+*/
+type Knowt struct{}
+
+func (Knowt) Name() string {
+	return "knowt"
+}
+
+func (Knowt) Hosts() []string {
+	return []string{"knowt.com"}
+}
+
+func (Knowt) FetchURL(u *url.URL) (string, error) {
+	if !strings.HasPrefix(u.Path, "/flashcards/") {
+		return "", ErrUnsupportedURL
+	}
+	return u.String(), nil
+}
+
+type knowtFlashcard struct {
+	Term       string  `json:"term"`
+	Definition string  `json:"definition"`
+	Image      *string `json:"image"`
+	DefImage   *string `json:"definitionImage"`
+	Trash      bool    `json:"trash"`
+}
+
+type knowtFlashcardSet struct {
+	Title      string           `json:"title"`
+	Flashcards []knowtFlashcard `json:"flashcards"`
+}
+
+func (Knowt) Parse(r io.Reader) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	rawNextData, err := nextData(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextData struct {
+		Props struct {
+			PageProps struct {
+				FlashcardSet json.RawMessage `json:"flashcardSet"`
+			} `json:"pageProps"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal(rawNextData, &nextData); err != nil {
+		return nil, err
+	}
+	var set knowtFlashcardSet
+	if err := unmarshalMaybeString(nextData.Props.PageProps.FlashcardSet, &set); err != nil {
+		return nil, errors.Join(errors.New("invalid flashcardSet in __NEXT_DATA__"), err)
+	}
+
+	result := &Result{
+		Title: strings.TrimSpace(set.Title),
+	}
+	if result.Title == "" {
+		result.Title = metaTitle(doc)
+	}
+	for _, card := range set.Flashcards {
+		if card.Trash {
+			continue
+		}
+		term := Term{
+			Term:         htmlText(card.Term),
+			Def:          htmlText(card.Definition),
+			TermRichText: formattedOrEmpty(sanitizeHTML(card.Term)),
+			DefRichText:  formattedOrEmpty(sanitizeHTML(card.Definition)),
+		}
+		if card.Image != nil {
+			term.TermImageURL = absoluteImageURL(*card.Image)
+		}
+		if card.DefImage != nil {
+			term.DefImageURL = absoluteImageURL(*card.DefImage)
+		}
+		result.Terms = append(result.Terms, term)
+	}
+	if len(result.Terms) == 0 {
+		return nil, errors.New("no terms in knowt page")
+	}
+	return result, nil
+}
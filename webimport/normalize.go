@@ -0,0 +1,40 @@
+package webimport
+
+import (
+	"net/url"
+	"strings"
+)
+
+/* query params that never change the page, like share/tracking params */
+var ignoredQueryParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+	"ref":    true,
+	"usp":    true,
+}
+
+/*
+NormalizeURL returns a key for caching/deduplicating imports of the same page:
+lowercase host without "www.", no fragment, no trailing slash,
+no tracking params (utm_*, etc), and sorted query params.
+It should be called with the URL from Registry.Lookup (the URL that's actually fetched).
+*/
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for key := range query {
+		if ignoredQueryParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	normalized := url.URL{
+		Scheme:   "https",
+		Host:     strings.TrimPrefix(strings.ToLower(u.Host), "www."),
+		Path:     strings.TrimRight(u.Path, "/"),
+		RawQuery: query.Encode(), /* Encode sorts by key */
+	}
+	return normalized.String(), nil
+}
@@ -0,0 +1,134 @@
+package webimport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+/*
+Quizlet parses Quizlet studyset pages,
+which have terms in `__NEXT_DATA__` under pageProps.dehydratedReduxStateKey
+*/
+type Quizlet struct{}
+
+func (Quizlet) Name() string {
+	return "quizlet"
+}
+
+func (Quizlet) Hosts() []string {
+	return []string{"quizlet.com"}
+}
+
+func (Quizlet) FetchURL(u *url.URL) (string, error) {
+	if u.Path == "" || u.Path == "/" {
+		return "", ErrUnsupportedURL
+	}
+	return u.String(), nil
+}
+
+/* media type 1 is text, type 2 is an image */
+type quizletMedia struct {
+	Type      int     `json:"type"`
+	PlainText string  `json:"plainText"`
+	RichText  *string `json:"richText"`
+	URL       string  `json:"url"`
+}
+
+type quizletCardSide struct {
+	SideID int            `json:"sideId"`
+	Label  string         `json:"label"`
+	Media  []quizletMedia `json:"media"`
+}
+
+type quizletStudiableItem struct {
+	IsDeleted bool              `json:"isDeleted"`
+	CardSides []quizletCardSide `json:"cardSides"`
+}
+
+type quizletReduxState struct {
+	SetPage struct {
+		Set struct {
+			Title string `json:"title"`
+		} `json:"set"`
+	} `json:"setPage"`
+	StudyModesCommon struct {
+		StudiableData struct {
+			StudiableItems []quizletStudiableItem `json:"studiableItems"`
+		} `json:"studiableData"`
+	} `json:"StudyModesCommon"`
+}
+
+func (Quizlet) Parse(r io.Reader) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	rawNextData, err := nextData(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextData struct {
+		Props struct {
+			PageProps struct {
+				DehydratedReduxStateKey json.RawMessage `json:"dehydratedReduxStateKey"`
+			} `json:"pageProps"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal(rawNextData, &nextData); err != nil {
+		return nil, err
+	}
+	var state quizletReduxState
+	if err := unmarshalMaybeString(nextData.Props.PageProps.DehydratedReduxStateKey, &state); err != nil {
+		return nil, errors.Join(errors.New("invalid dehydratedReduxStateKey in __NEXT_DATA__"), err)
+	}
+
+	result := &Result{
+		Title: state.SetPage.Set.Title,
+	}
+	if result.Title == "" {
+		result.Title = metaTitle(doc)
+	}
+	for _, item := range state.StudyModesCommon.StudiableData.StudiableItems {
+		if item.IsDeleted || len(item.CardSides) < 1 {
+			continue
+		}
+		var term Term
+		termSide := quizletSide(item.CardSides[0])
+		term.Term, term.TermRichText, term.TermImageURL = termSide.Text, termSide.RichText, termSide.ImageURL
+		if len(item.CardSides) >= 2 {
+			defSide := quizletSide(item.CardSides[1])
+			term.Def, term.DefRichText, term.DefImageURL = defSide.Text, defSide.RichText, defSide.ImageURL
+		}
+		for _, side := range item.CardSides[min(2, len(item.CardSides)):] {
+			term.ExtraSides = append(term.ExtraSides, quizletSide(side))
+		}
+		result.Terms = append(result.Terms, term)
+	}
+	if len(result.Terms) == 0 {
+		return nil, errors.New("no terms in quizlet page")
+	}
+	return result, nil
+}
+
+func quizletSide(side quizletCardSide) Side {
+	result := Side{
+		Label: side.Label,
+	}
+	for _, media := range side.Media {
+		if result.Text == "" && media.PlainText != "" {
+			result.Text = media.PlainText
+			if media.RichText != nil {
+				result.RichText = formattedOrEmpty(quizletRichTextHTML(*media.RichText))
+			}
+		}
+		if result.ImageURL == "" && media.Type == 2 {
+			result.ImageURL = absoluteImageURL(media.URL)
+		}
+	}
+	return result
+}
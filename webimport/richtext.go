@@ -0,0 +1,179 @@
+package webimport
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+/*
+rich text in import results is a small subset of HTML,
+everything else is unwrapped (tags removed, text kept) or dropped (like scripts)
+*/
+var allowedRichTextTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "s": true,
+	"sub": true, "sup": true, "mark": true, "code": true,
+	"br": true, "p": true, "ul": true, "ol": true, "li": true,
+}
+
+var droppedRichTextTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"img": true, "svg": true, "video": true, "audio": true, "button": true, "input": true,
+}
+
+/* sanitizeHTML returns fragment with only allowedRichTextTags and no attributes */
+func sanitizeHTML(fragment string) string {
+	nodes, err := nethtml.ParseFragment(strings.NewReader(fragment), &nethtml.Node{
+		Type:     nethtml.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+	var sb strings.Builder
+	for _, node := range nodes {
+		writeSanitized(&sb, node)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func writeSanitized(sb *strings.Builder, node *nethtml.Node) {
+	switch node.Type {
+	case nethtml.TextNode:
+		sb.WriteString(html.EscapeString(node.Data))
+		return
+	case nethtml.ElementNode:
+		tag := strings.ToLower(node.Data)
+		if droppedRichTextTags[tag] {
+			return
+		}
+		if allowedRichTextTags[tag] {
+			sb.WriteString("<" + tag + ">")
+			if tag == "br" {
+				return
+			}
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				writeSanitized(sb, child)
+			}
+			sb.WriteString("</" + tag + ">")
+			return
+		}
+	case nethtml.CommentNode, nethtml.DoctypeNode:
+		return
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeSanitized(sb, child)
+	}
+}
+
+/*
+formattedOrEmpty returns richText if it has formatting (anything besides paragraphs & line breaks),
+so plain text isn't duplicated as rich text
+*/
+func formattedOrEmpty(richText string) string {
+	unformatted := strings.NewReplacer("<p>", "", "</p>", "", "<br>", "").Replace(richText)
+	if !strings.Contains(unformatted, "<") {
+		return ""
+	}
+	return richText
+}
+
+/* selectionHTML returns a selection's inner HTML, sanitized */
+func selectionHTML(sel *goquery.Selection) string {
+	inner, err := sel.Html()
+	if err != nil {
+		return ""
+	}
+	return sanitizeHTML(inner)
+}
+
+/* firstImageURL returns the first <img> src in a selection, or "" */
+func firstImageURL(sel *goquery.Selection) string {
+	src, _ := sel.Find("img").First().Attr("src")
+	return absoluteImageURL(src)
+}
+
+/*
+absoluteImageURL keeps https image URLs (and upgrades protocol-relative ones),
+anything else (relative paths, data: URLs, http) is dropped
+*/
+func absoluteImageURL(src string) string {
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(src, "//") {
+		src = "https:" + src
+	}
+	if !strings.HasPrefix(src, "https://") {
+		return ""
+	}
+	return src
+}
+
+/*
+quizletRichTextHTML converts Quizlet's rich text (a ProseMirror JSON document) into HTML.
+Quizlet uses marks like "b", "i", "u", "sup", "sub", and "bgY" (highlight).
+*/
+func quizletRichTextHTML(raw string) string {
+	var doc quizletRichTextNode
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeQuizletRichText(&sb, doc)
+	return strings.TrimSpace(sb.String())
+}
+
+type quizletRichTextNode struct {
+	Type    string                  `json:"type"`
+	Text    string                  `json:"text"`
+	Marks   []struct{ Type string } `json:"marks"`
+	Content []quizletRichTextNode   `json:"content"`
+}
+
+var quizletMarkTags = map[string]string{
+	"b": "b", "bold": "b", "strong": "b",
+	"i": "i", "italic": "i", "em": "i",
+	"u": "u", "underline": "u",
+	"sup": "sup", "sub": "sub",
+	"bgY": "mark", "bgB": "mark", "bgP": "mark",
+}
+
+var quizletNodeTags = map[string]string{
+	"paragraph":   "p",
+	"bulletList":  "ul",
+	"orderedList": "ol",
+	"listItem":    "li",
+}
+
+func writeQuizletRichText(sb *strings.Builder, node quizletRichTextNode) {
+	switch node.Type {
+	case "text":
+		var closing []string
+		for _, mark := range node.Marks {
+			if tag, ok := quizletMarkTags[mark.Type]; ok {
+				sb.WriteString("<" + tag + ">")
+				closing = append([]string{"</" + tag + ">"}, closing...)
+			}
+		}
+		sb.WriteString(html.EscapeString(node.Text))
+		sb.WriteString(strings.Join(closing, ""))
+		return
+	case "hardBreak":
+		sb.WriteString("<br>")
+		return
+	}
+	tag := quizletNodeTags[node.Type]
+	if tag != "" {
+		sb.WriteString("<" + tag + ">")
+	}
+	for _, child := range node.Content {
+		writeQuizletRichText(sb, child)
+	}
+	if tag != "" {
+		sb.WriteString("</" + tag + ">")
+	}
+}